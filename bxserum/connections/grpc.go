@@ -6,33 +6,50 @@ import (
 	"io"
 )
 
+// GRPCStream forwards the responses of stream to responseChan, which is closed once the stream ends or fails
 func GRPCStream[T any](stream grpc.ClientStream, input string, responseChan chan *T) error {
-	response, err := recvGRPC[T](stream, input)
+	generator, err := GRPCStreamGenerator[T](stream, input)
 	if err != nil {
 		return err
 	}
 
-	go func(response *T, stream grpc.ClientStream, input string) {
-		responseChan <- response
-
+	go func() {
 		for {
-			response, err = recvGRPC[T](stream, input)
+			response, err := generator()
 			if err != nil {
+				close(responseChan)
 				return
-			} else {
-				responseChan <- response
 			}
+			responseChan <- response
 		}
-	}(response, stream, input)
+	}()
 
 	return nil
 }
 
+// GRPCStreamGenerator receives the first response of stream, so that subscription errors are returned, and returns a
+// generator of its responses like WSStream. The generator's error wraps io.EOF once the server ends the stream.
+func GRPCStreamGenerator[T any](stream grpc.ClientStream, input string) (func() (*T, error), error) {
+	first, err := recvGRPC[T](stream, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*T, error) {
+		if first != nil {
+			response := first
+			first = nil
+			return response, nil
+		}
+		return recvGRPC[T](stream, input)
+	}, nil
+}
+
 func recvGRPC[T any](stream grpc.ClientStream, input string) (*T, error) {
 	m := new(T)
 	err := stream.RecvMsg(m)
 	if err == io.EOF {
-		return nil, fmt.Errorf("stream for input %s ended successfully", input)
+		return nil, fmt.Errorf("stream for input %s ended successfully: %w", input, err)
 	} else if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,7 @@ package connections
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -19,6 +20,9 @@ type HTTPError struct {
 	Code    int         `json:"code"`
 	Details interface{} `json:"details"`
 	Message string      `json:"message"`
+
+	// StatusCode is the HTTP status of the response the error was read from
+	StatusCode int `json:"-"`
 }
 
 func (h HTTPError) Error() string {
@@ -32,32 +36,47 @@ func HTTPGet[T protoreflect.ProtoMessage](url string, val T) error {
 }
 
 func HTTPGetWithClient[T protoreflect.ProtoMessage](url string, client *http.Client, val T) error {
-	httpResp, err := client.Get(url)
-	if err != nil {
-		return err
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return httpUnmarshalError(httpResp)
-	}
+	return HTTPGetWithContext[T](context.Background(), url, client, val)
+}
 
-	if err := httpUnmarshal[T](httpResp, val); err != nil {
+// HTTPGetWithContext is HTTPGetWithClient bound to ctx, so the request is aborted when ctx is cancelled or expires
+func HTTPGetWithContext[T protoreflect.ProtoMessage](ctx context.Context, url string, client *http.Client, val T) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return httpDo[T](client, req, val)
 }
 
 func HTTPPostWithClient[T protoreflect.ProtoMessage](url string, client *http.Client, body interface{}, val T) error {
+	return HTTPPostWithContext[T](context.Background(), url, client, body, val)
+}
+
+// HTTPPostWithContext is HTTPPostWithClient bound to ctx, so the request is aborted when ctx is cancelled or expires
+func HTTPPostWithContext[T protoreflect.ProtoMessage](ctx context.Context, url string, client *http.Client, body interface{}, val T) error {
 	b, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
-	httpResp, err := client.Post(url, contentType, bytes.NewBuffer(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(b))
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", contentType)
+
+	return httpDo[T](client, req, val)
+}
+
+func httpDo[T protoreflect.ProtoMessage](client *http.Client, req *http.Request, val T) error {
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
 
 	if httpResp.StatusCode != http.StatusOK {
 		return httpUnmarshalError(httpResp)
@@ -80,12 +99,13 @@ func httpUnmarshalError(httpResp *http.Response) error {
 		return err
 	}
 
+	// errors not produced by the API (e.g. from a proxy or load balancer) aren't JSON: keep the body as message
 	var httpError HTTPError
-	err = json.Unmarshal(body, &httpError)
-	if err != nil {
-		return err
+	if err = json.Unmarshal(body, &httpError); err != nil {
+		return HTTPError{Message: string(body), StatusCode: httpResp.StatusCode}
 	}
 
+	httpError.StatusCode = httpResp.StatusCode
 	return httpError
 }
 
@@ -3,6 +3,8 @@ package connections
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/sourcegraph/jsonrpc2"
 )
 
 const (
@@ -79,3 +81,25 @@ func (s *UnsubscribeParams) UnmarshalJSON(b []byte) error {
 	}
 	return nil
 }
+
+// RPCError is an error response to a JSON-RPC request
+type RPCError struct {
+	Code    int64
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// newRPCError takes the message from the error data when provided, since the API reports its errors there
+func newRPCError(rpcErr *jsonrpc2.Error) *RPCError {
+	message := rpcErr.Message
+	if rpcErr.Data != nil {
+		var data string
+		if err := json.Unmarshal(*rpcErr.Data, &data); err == nil {
+			message = data
+		}
+	}
+	return &RPCError{Code: rpcErr.Code, Message: message}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
 	"github.com/bloXroute-Labs/serum-client-go/utils"
 	"github.com/gorilla/websocket"
 	"github.com/sourcegraph/jsonrpc2"
@@ -17,48 +18,127 @@ import (
 const (
 	handshakeTimeout       = 5 * time.Second
 	subscriptionBuffer     = 1000
+	writeBuffer            = 100
 	unsubscribeGracePeriod = 3 * time.Second
 )
 
+// ErrSubscriptionOverflow ends the streams subscribed with WSStreamOpts.DisconnectOnOverflow whose subscriber doesn't
+// read its updates fast enough
+var ErrSubscriptionOverflow = errors.New("subscription buffer overflowed")
+
+// WSOpts configures optional behavior of a WS connection
+type WSOpts struct {
+	// Reconnect enables automatic reconnection and subscription resumption when set
+	Reconnect *ReconnectPolicy
+
+	// OnConnectionEvent is called for every disconnect, reconnect attempt and reconnect result
+	OnConnectionEvent func(ConnectionEvent)
+
+	// OnWriteQueueDepth is called with the number of messages waiting to be written whenever it changes
+	OnWriteQueueDepth func(int)
+
+	// RequestMeta returns the JSON-RPC "meta" field of requests and subscriptions sent with ctx, or nil to leave it out
+	RequestMeta func(ctx context.Context) interface{}
+
+	// Logger receives the connection lifecycle, subscription and dropped message logs, which are discarded when not set
+	Logger logger.Logger
+}
+
 type WS struct {
 	messageM      sync.Mutex
 	subscriptionM sync.RWMutex
+	requestM      sync.Mutex
+	connM         sync.RWMutex
 	requestID     *utils.RequestID
-	conn          *websocket.Conn
+	endpoint      string
+	opts          WSOpts
+	log           logger.Logger
+	conn          *wsConn
 	ctx           context.Context
 	cancel        context.CancelFunc
 	err           error
-	writeCh       chan []byte
 
 	requestMap      map[uint64]requestTracker
-	subscriptionMap map[string]subscriptionEntry
+	subscriptionMap map[string]*subscriptionEntry
+}
+
+// wsConn is a single underlying websocket connection: WS replaces it on every reconnect
+type wsConn struct {
+	conn    *websocket.Conn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	err     error
+	writeCh chan []byte
 }
 
 func NewWS(endpoint string) (*WS, error) {
-	dialer := websocket.Dialer{HandshakeTimeout: handshakeTimeout}
-	conn, _, err := dialer.Dial(endpoint, nil)
+	return NewWSWithOpts(endpoint, WSOpts{})
+}
+
+func NewWSWithOpts(endpoint string, opts WSOpts) (*WS, error) {
+	conn, err := dialWS(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	log := opts.Logger
+	if log == nil {
+		log = logger.Nop{}
+	}
+	log = log.With(logger.Fields{"endpoint": endpoint})
+
 	ctx, cancel := context.WithCancel(context.Background())
 	ws := &WS{
 		requestID:       utils.NewRequestID(),
-		conn:            conn,
+		endpoint:        endpoint,
+		opts:            opts,
+		log:             log,
 		ctx:             ctx,
 		cancel:          cancel,
-		writeCh:         make(chan []byte, 100),
 		requestMap:      make(map[uint64]requestTracker),
-		subscriptionMap: make(map[string]subscriptionEntry),
+		subscriptionMap: make(map[string]*subscriptionEntry),
 	}
-	go ws.readLoop()
-	go ws.writeLoop()
+	ws.start(conn)
+	log.Info("websocket connected", nil)
 	return ws, nil
 }
 
-func (w *WS) readLoop() {
-	defer w.cancel()
+func dialWS(endpoint string) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: handshakeTimeout}
+	conn, _, err := dialer.Dial(endpoint, nil)
+	return conn, err
+}
+
+// start begins processing messages on a freshly dialed connection. Returns false if WS was closed in the meantime.
+func (w *WS) start(conn *websocket.Conn) (*wsConn, bool) {
+	w.connM.Lock()
+	if w.ctx.Err() != nil {
+		w.connM.Unlock()
+		return nil, false
+	}
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	c := &wsConn{
+		conn:    conn,
+		ctx:     ctx,
+		cancel:  cancel,
+		writeCh: make(chan []byte, writeBuffer),
+	}
+	w.conn = c
+	w.connM.Unlock()
+
+	go w.readLoop(c)
+	go w.writeLoop(c)
+	return c, true
+}
+
+func (w *WS) currentConn() *wsConn {
+	w.connM.RLock()
+	defer w.connM.RUnlock()
+	return w.conn
+}
 
+func (w *WS) readLoop(c *wsConn) {
 	for {
 		// all message reading is done on a single goroutine, while message processing is dispatched on independent
 		// goroutines in parallel. in most cases this is fine, but if not message processors can request to hold the lock
@@ -69,9 +149,9 @@ func (w *WS) readLoop() {
 		w.messageM.Lock()
 		w.messageM.Unlock()
 
-		_, msg, err := w.conn.ReadMessage()
+		_, msg, err := c.conn.ReadMessage()
 		if err != nil {
-			_ = w.Close(err)
+			w.connectionLost(c, err)
 			return
 		}
 
@@ -92,27 +172,66 @@ func (w *WS) readLoop() {
 		}
 
 		// no message works: exit loop and cancel connection
-		_ = w.Close(fmt.Errorf("unknown jsonrpc message format: %v", string(msg)))
+		w.fail(fmt.Errorf("unknown jsonrpc message format: %v", string(msg)))
 		return
 	}
 }
 
-func (w *WS) writeLoop() {
+func (w *WS) writeLoop(c *wsConn) {
 	for {
-		m := <-w.writeCh
-		err := w.conn.WriteMessage(websocket.TextMessage, m)
-		if err != nil {
-			_ = w.Close(fmt.Errorf("error sending message: %w", err))
+		select {
+		case m := <-c.writeCh:
+			w.writeQueueChanged(c)
+			err := c.conn.WriteMessage(websocket.TextMessage, m)
+			if err != nil {
+				w.connectionLost(c, fmt.Errorf("error sending message: %w", err))
+				return
+			}
+		case <-c.ctx.Done():
 			return
 		}
 	}
 }
 
+func (w *WS) writeQueueChanged(c *wsConn) {
+	if w.opts.OnWriteQueueDepth != nil {
+		w.opts.OnWriteQueueDepth(len(c.writeCh))
+	}
+}
+
+// connectionLost either closes WS entirely or starts reconnecting, depending on the configured policy
+func (w *WS) connectionLost(c *wsConn, reason error) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	if w.opts.Reconnect == nil {
+		w.fail(reason)
+		return
+	}
+
+	// read and write loops can both fail on the same connection: only handle the first
+	w.connM.Lock()
+	if c.ctx.Err() != nil {
+		w.connM.Unlock()
+		return
+	}
+	c.err = reason
+	c.cancel()
+	w.connM.Unlock()
+
+	_ = c.conn.Close()
+	w.log.Warn("websocket connection lost, reconnecting", logger.Fields{"error": reason})
+	w.emit(ConnectionEvent{Type: Disconnected, Err: reason})
+	go w.reconnect(reason)
+}
+
 func (w *WS) processRPCResponse(response jsonrpc2.Response) {
 	requestID := response.ID.Num
+	w.requestM.Lock()
 	rt, ok := w.requestMap[requestID]
+	w.requestM.Unlock()
 	if !ok {
-		_ = w.Close(fmt.Errorf("unknown request ID: got %v, most recent %v", requestID, w.requestID.Current()))
+		w.fail(fmt.Errorf("unknown request ID: got %v, most recent %v", requestID, w.requestID.Current()))
 		return
 	}
 
@@ -134,23 +253,34 @@ func (w *WS) processSubscriptionUpdate(update jsonrpc2.Request) {
 	var f FeedUpdate
 	err := json.Unmarshal(*update.Params, &f)
 	if err != nil {
-		_ = w.Close(fmt.Errorf("could not deserialize feed update: %w", err))
+		w.fail(fmt.Errorf("could not deserialize feed update: %w", err))
 		return
 	}
 
 	w.subscriptionM.RLock()
-	defer w.subscriptionM.RUnlock()
 	sub, ok := w.subscriptionMap[f.SubscriptionID]
+	active := ok && sub.active
+	w.subscriptionM.RUnlock()
 	if !ok {
-		_ = w.Close(fmt.Errorf("unknown subscription ID: %v", f.SubscriptionID))
+		w.fail(fmt.Errorf("unknown subscription ID: %v", f.SubscriptionID))
 		return
 	}
 	// skip message for inactive subscription: will be closed soon
-	if !sub.active {
+	if !active {
+		w.log.Debug("dropped update for inactive subscription", logger.Fields{"stream": sub.params.StreamName, "subscription": f.SubscriptionID})
 		return
 	}
 
-	sub.ch <- f.Result
+	// the reader never waits for a subscriber, which would hold up the connection's other subscriptions and
+	// reconnects: updates are queued until the subscriber reads them, unless it asked to be disconnected instead
+	if !sub.push(f.Result) {
+		w.subscriptionM.Lock()
+		sub.active = false
+		sub.overflowed = true
+		w.subscriptionM.Unlock()
+		w.log.Warn("disconnected slow subscriber", logger.Fields{"stream": sub.params.StreamName, "subscription": f.SubscriptionID})
+		sub.cancel()
+	}
 }
 
 func (w *WS) Request(ctx context.Context, method string, request proto.Message, response proto.Message) error {
@@ -165,6 +295,9 @@ func (w *WS) Request(ctx context.Context, method string, request proto.Message,
 	}
 	rawParams := json.RawMessage(params)
 	rpcRequest.Params = &rawParams
+	if err = w.setMeta(ctx, &rpcRequest); err != nil {
+		return err
+	}
 
 	rpcResponse, err := w.request(ctx, rpcRequest, false)
 	if err != nil {
@@ -177,56 +310,102 @@ func (w *WS) Request(ctx context.Context, method string, request proto.Message,
 	return nil
 }
 
+func (w *WS) setMeta(ctx context.Context, request *jsonrpc2.Request) error {
+	if w.opts.RequestMeta == nil {
+		return nil
+	}
+	meta := w.opts.RequestMeta(ctx)
+	if meta == nil {
+		return nil
+	}
+	return request.SetMeta(meta)
+}
+
 func (w *WS) request(ctx context.Context, request jsonrpc2.Request, lockRequired bool) (jsonrpc2.Response, error) {
 	b, err := json.Marshal(request)
 	if err != nil {
 		return jsonrpc2.Response{}, err
 	}
 
+	c := w.currentConn()
+	if c.ctx.Err() != nil {
+		return jsonrpc2.Response{}, w.connErr(c)
+	}
+
 	// setup listener for next request ID that matches response
 	responseCh := make(chan responseUpdate)
+	w.requestM.Lock()
 	w.requestMap[request.ID.Num] = requestTracker{
 		ch:           responseCh,
 		lockRequired: lockRequired,
 	}
+	w.requestM.Unlock()
 	defer func() {
+		w.requestM.Lock()
 		delete(w.requestMap, request.ID.Num)
+		w.requestM.Unlock()
 	}()
 
-	w.writeCh <- b
+	select {
+	case c.writeCh <- b:
+		w.writeQueueChanged(c)
+	case <-ctx.Done():
+		return jsonrpc2.Response{}, ctx.Err()
+	case <-c.ctx.Done():
+		return jsonrpc2.Response{}, w.connErr(c)
+	}
 
 	select {
 	case response := <-responseCh:
 		rpcResponse := response.v
 		if rpcResponse.Error != nil {
-			var rpcErr string
-			err = json.Unmarshal(*rpcResponse.Error.Data, &rpcErr)
-			if err != nil {
-				return jsonrpc2.Response{}, err
+			// caller never receives the response, so processing lock must be released here
+			if response.lockHeld {
+				w.messageM.Unlock()
 			}
-			return rpcResponse, errors.New(rpcErr)
+
+			return rpcResponse, newRPCError(rpcResponse.Error)
 		}
 		return response.v, nil
 	case <-ctx.Done():
 		return jsonrpc2.Response{}, ctx.Err()
-	case <-w.ctx.Done():
-		// connection closed
-		return jsonrpc2.Response{}, fmt.Errorf("websocket connection was closed: %w", w.err)
+	case <-c.ctx.Done():
+		return jsonrpc2.Response{}, w.connErr(c)
 	}
 }
 
-func WSStream[T proto.Message](w *WS, ctx context.Context, streamName string, streamParams proto.Message, resultInitFn func() T) (func() (T, error), error) {
-	streamParamsB, err := protojson.Marshal(streamParams)
-	if err != nil {
-		return nil, err
-	}
-	params := SubscribeParams{
-		StreamName: streamName,
-		StreamOpts: streamParamsB,
+// connErr describes why the provided connection is no longer usable
+func (w *WS) connErr(c *wsConn) error {
+	if w.ctx.Err() != nil {
+		return &ConnectionError{Message: "websocket connection was closed", Cause: w.err}
 	}
+
+	w.connM.RLock()
+	defer w.connM.RUnlock()
+	return &ConnectionError{Message: "websocket connection was lost", Cause: c.err}
+}
+
+// ConnectionError is returned by requests and streams that can't complete because the websocket connection was lost
+// or closed
+type ConnectionError struct {
+	Message string
+	Cause   error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Message, e.Cause)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Cause
+}
+
+// subscribe registers the stream with the server and returns the subscription ID. On success the message processing
+// lock is still held, so the caller must register the subscription ID and then release messageM.
+func (w *WS) subscribe(ctx context.Context, params SubscribeParams) (string, error) {
 	paramsB, err := json.Marshal(params)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	rawParams := json.RawMessage(paramsB)
 	rpcRequest := jsonrpc2.Request{
@@ -234,30 +413,96 @@ func WSStream[T proto.Message](w *WS, ctx context.Context, streamName string, st
 		ID:     jsonrpc2.ID{Num: w.requestID.Next()},
 		Params: &rawParams,
 	}
+	if err = w.setMeta(ctx, &rpcRequest); err != nil {
+		return "", err
+	}
 
 	// requires lock held on subscription mutex, otherwise a subscription message could be processed before the map entry is created
 	rpcResponse, err := w.request(ctx, rpcRequest, true)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	defer w.messageM.Unlock()
 
 	var subscriptionID string
 	err = json.Unmarshal(*rpcResponse.Result, &subscriptionID)
+	if err != nil {
+		w.messageM.Unlock()
+		return "", err
+	}
+	return subscriptionID, nil
+}
+
+// unsubscribe cancels the subscription on the server, unless the connection it was created on is already gone
+func (w *WS) unsubscribe(sub *subscriptionEntry) error {
+	w.subscriptionM.RLock()
+	subscriptionID, c := sub.id, sub.conn
+	w.subscriptionM.RUnlock()
+
+	if c != w.currentConn() || c.ctx.Err() != nil {
+		return nil
+	}
+
+	up := UnsubscribeParams{SubscriptionID: subscriptionID}
+	b, _ := json.Marshal(up)
+	rm := json.RawMessage(b)
+
+	unsubscribeMessage := jsonrpc2.Request{
+		ID:     jsonrpc2.ID{Num: w.requestID.Next()},
+		Method: unsubscribeMethod,
+		Params: &rm,
+	}
+
+	_, err := w.request(w.ctx, unsubscribeMessage, false)
+	if err != nil && c.ctx.Err() == nil {
+		return err
+	}
+	w.log.Debug("unsubscribed", logger.Fields{"stream": sub.params.StreamName, "subscription": subscriptionID})
+	return nil
+}
+
+// WSStreamOpts configures optional behavior of a WSStream subscription
+type WSStreamOpts struct {
+	// DisconnectOnOverflow ends the stream with ErrSubscriptionOverflow once its subscriber lets updates pile up, instead
+	// of queueing them until it reads them
+	DisconnectOnOverflow bool
+}
+
+func WSStream[T proto.Message](w *WS, ctx context.Context, streamName string, streamParams proto.Message, resultInitFn func() T) (func() (T, error), error) {
+	return WSStreamWithOpts(w, ctx, streamName, streamParams, WSStreamOpts{}, resultInitFn)
+}
+
+// WSStreamWithOpts is WSStream configured by opts
+func WSStreamWithOpts[T proto.Message](w *WS, ctx context.Context, streamName string, streamParams proto.Message, opts WSStreamOpts, resultInitFn func() T) (func() (T, error), error) {
+	streamParamsB, err := protojson.Marshal(streamParams)
+	if err != nil {
+		return nil, err
+	}
+	params := SubscribeParams{
+		StreamName: streamName,
+		StreamOpts: streamParamsB,
+	}
+
+	subscriptionID, err := w.subscribe(ctx, params)
 	if err != nil {
 		return nil, err
 	}
+	defer w.messageM.Unlock()
 
-	ch := make(chan json.RawMessage, subscriptionBuffer)
 	streamCtx, streamCancel := context.WithCancel(ctx)
 
-	w.subscriptionM.Lock()
-	w.subscriptionMap[subscriptionID] = subscriptionEntry{
-		active: true,
-		ch:     ch,
-		cancel: streamCancel,
+	sub := &subscriptionEntry{
+		id:                   subscriptionID,
+		params:               params,
+		conn:                 w.currentConn(),
+		active:               true,
+		cancel:               streamCancel,
+		ready:                make(chan struct{}, 1),
+		disconnectOnOverflow: opts.DisconnectOnOverflow,
 	}
+	w.subscriptionM.Lock()
+	w.subscriptionMap[subscriptionID] = sub
 	w.subscriptionM.Unlock()
+	w.log.Debug("subscribed", logger.Fields{"stream": streamName, "subscription": subscriptionID})
 
 	// set goroutine to unsubscribe when ctx is canceled
 	go func() {
@@ -265,69 +510,100 @@ func WSStream[T proto.Message](w *WS, ctx context.Context, streamName string, st
 
 		// immediately mark as inactive
 		w.subscriptionM.Lock()
-		w.subscriptionMap[subscriptionID] = subscriptionEntry{active: false}
+		sub.active = false
 		w.subscriptionM.Unlock()
 
-		up := UnsubscribeParams{SubscriptionID: subscriptionID}
-		b, _ := json.Marshal(up)
-		rm := json.RawMessage(b)
-
-		unsubscribeMessage := jsonrpc2.Request{
-			ID:     jsonrpc2.ID{Num: w.requestID.Next()},
-			Method: unsubscribeMethod,
-			Params: &rm,
-		}
-
-		_, err = w.request(w.ctx, unsubscribeMessage, false)
+		err := w.unsubscribe(sub)
 		if err != nil {
-			_ = w.Close(fmt.Errorf("unsubscribe requested rejected: %w", err))
+			w.fail(fmt.Errorf("unsubscribe requested rejected: %w", err))
 		}
 
 		// wait for server to process message before forcing errors from unknown subscription IDs
 		time.Sleep(unsubscribeGracePeriod)
 		w.subscriptionM.Lock()
-		delete(w.subscriptionMap, subscriptionID)
+		delete(w.subscriptionMap, sub.id)
 		w.subscriptionM.Unlock()
 	}()
 
+	// closing the connection also cancels its subscriptions, so it's checked first
+	ended := func() error {
+		if w.ctx.Err() != nil {
+			return &ConnectionError{Message: "connection has been closed", Cause: w.err}
+		}
+		if streamCtx.Err() == nil {
+			return nil
+		}
+		w.subscriptionM.RLock()
+		overflowed := sub.overflowed
+		w.subscriptionM.RUnlock()
+		if overflowed {
+			return ErrSubscriptionOverflow
+		}
+		return errors.New("stream context has been closed")
+	}
+
 	return func() (T, error) {
 		var zero T
-		select {
-		case b := <-ch:
+		for {
+			if err := ended(); err != nil {
+				return zero, err
+			}
+			b, ok := sub.pop()
+			if !ok {
+				select {
+				case <-sub.ready:
+				case <-w.ctx.Done():
+				case <-streamCtx.Done():
+				}
+				continue
+			}
+
 			v := resultInitFn()
 			err := protojson.Unmarshal(b, v)
 			if err != nil {
 				return zero, err
 			}
 			return v, nil
-		case <-w.ctx.Done():
-			return zero, fmt.Errorf("connection has been closed: %w", w.err)
-		case <-streamCtx.Done():
-			return zero, errors.New("stream context has been closed")
 		}
 	}, nil
 }
 
+// Close closes the connection and ends all subscriptions, which fail with reason
 func (w *WS) Close(reason error) error {
+	return w.close(reason, func() {
+		w.log.Info("websocket connection closed", logger.Fields{"reason": reason})
+	})
+}
+
+// fail closes the connection because of an error it can't recover from
+func (w *WS) fail(reason error) {
+	_ = w.close(reason, func() {
+		w.log.Error("closing websocket connection", logger.Fields{"error": reason})
+	})
+}
+
+// close logs and closes the connection, unless it's already closed
+func (w *WS) close(reason error, log func()) error {
 	w.messageM.Lock()
 	defer w.messageM.Unlock()
-	w.subscriptionM.Lock()
-	defer w.subscriptionM.Unlock()
 
 	if w.ctx.Err() != nil {
 		return nil
 	}
 
 	w.err = reason
+	log()
 
-	// cancel main connection ctx
+	// cancel main connection ctx, which ends the subscriptions' generators
 	w.cancel()
 
 	// cancel all subscriptions
+	w.subscriptionM.Lock()
+	defer w.subscriptionM.Unlock()
 	for _, sub := range w.subscriptionMap {
 		sub.close()
 	}
 
 	// close underlying connection
-	return w.conn.Close()
+	return w.currentConn().conn.Close()
 }
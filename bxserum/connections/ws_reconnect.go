@@ -0,0 +1,194 @@
+package connections
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+)
+
+const (
+	defaultReconnectAttempts   = 10
+	defaultReconnectBackoff    = 500 * time.Millisecond
+	defaultReconnectMaxBackoff = 30 * time.Second
+	defaultReconnectMultiplier = 2
+	defaultReconnectJitter     = 0.2
+)
+
+// ReconnectPolicy controls how WS re-dials the endpoint after the connection is lost
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of consecutive failed attempts before WS gives up and closes. Set to 0 to retry forever.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each failed attempt
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction (e.g. 0.2 for +/-20%)
+	Jitter float64
+}
+
+// DefaultReconnectPolicy retries 10 times with exponential backoff from 500ms up to 30s
+func DefaultReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		MaxAttempts:    defaultReconnectAttempts,
+		InitialBackoff: defaultReconnectBackoff,
+		MaxBackoff:     defaultReconnectMaxBackoff,
+		Multiplier:     defaultReconnectMultiplier,
+		Jitter:         defaultReconnectJitter,
+	}
+}
+
+// Backoff returns how long to wait before the given attempt (starting from 1)
+func (p ReconnectPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+type ConnectionEventType int
+
+const (
+	// Disconnected is emitted when the underlying connection fails and reconnection begins
+	Disconnected ConnectionEventType = iota
+	// ReconnectAttemptFailed is emitted for each attempt that could not re-dial or resubscribe
+	ReconnectAttemptFailed
+	// Reconnected is emitted once the connection is re-established and all subscriptions are resumed
+	Reconnected
+	// ReconnectAbandoned is emitted when MaxAttempts is exhausted and the connection is closed for good
+	ReconnectAbandoned
+)
+
+func (t ConnectionEventType) String() string {
+	switch t {
+	case Disconnected:
+		return "disconnected"
+	case ReconnectAttemptFailed:
+		return "reconnect attempt failed"
+	case Reconnected:
+		return "reconnected"
+	case ReconnectAbandoned:
+		return "reconnect abandoned"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectionEvent describes a change in the state of the underlying websocket connection
+type ConnectionEvent struct {
+	Type    ConnectionEventType
+	Time    time.Time
+	Attempt int
+	Err     error
+}
+
+func (w *WS) emit(event ConnectionEvent) {
+	if w.opts.OnConnectionEvent == nil {
+		return
+	}
+	event.Time = time.Now()
+	w.opts.OnConnectionEvent(event)
+}
+
+// reconnect re-dials the endpoint until successful or the policy is exhausted, then resumes all active subscriptions
+func (w *WS) reconnect(reason error) {
+	policy := w.opts.Reconnect
+	lastErr := reason
+
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(policy.Backoff(attempt)):
+		case <-w.ctx.Done():
+			return
+		}
+
+		conn, err := dialWS(w.endpoint)
+		if err != nil {
+			lastErr = err
+			w.log.Warn("websocket reconnect attempt failed", logger.Fields{"attempt": attempt, "error": err})
+			w.emit(ConnectionEvent{Type: ReconnectAttemptFailed, Attempt: attempt, Err: err})
+			continue
+		}
+
+		// Close could have been called while dialing
+		c, ok := w.start(conn)
+		if !ok {
+			_ = conn.Close()
+			return
+		}
+
+		err = w.resubscribe(c)
+		if err != nil {
+			lastErr = err
+			w.log.Warn("websocket reconnect attempt failed", logger.Fields{"attempt": attempt, "error": err})
+			w.emit(ConnectionEvent{Type: ReconnectAttemptFailed, Attempt: attempt, Err: err})
+
+			// mark connection as handled so its own loops do not start a second reconnect
+			w.connM.Lock()
+			c.err = err
+			c.cancel()
+			w.connM.Unlock()
+			_ = conn.Close()
+			continue
+		}
+
+		w.log.Info("websocket reconnected", logger.Fields{"attempt": attempt})
+		w.emit(ConnectionEvent{Type: Reconnected, Attempt: attempt})
+		return
+	}
+
+	err := fmt.Errorf("could not reconnect after %v attempts: %w", policy.MaxAttempts, lastErr)
+	w.emit(ConnectionEvent{Type: ReconnectAbandoned, Attempt: policy.MaxAttempts, Err: err})
+	w.fail(err)
+}
+
+// resubscribe re-issues every active subscription on the new connection and remaps the server's new subscription IDs
+// onto the existing entries, so callers keep receiving updates on their original channels
+func (w *WS) resubscribe(c *wsConn) error {
+	w.subscriptionM.RLock()
+	var subs []*subscriptionEntry
+	for _, sub := range w.subscriptionMap {
+		if sub.active {
+			subs = append(subs, sub)
+		}
+	}
+	w.subscriptionM.RUnlock()
+
+	for _, sub := range subs {
+		subscriptionID, err := w.subscribe(c.ctx, sub.params)
+		if err != nil {
+			return fmt.Errorf("could not resume %v subscription: %w", sub.params.StreamName, err)
+		}
+
+		w.subscriptionM.Lock()
+		delete(w.subscriptionMap, sub.id)
+		sub.id = subscriptionID
+		sub.conn = c
+		w.subscriptionMap[subscriptionID] = sub
+		w.log.Debug("resumed subscription", logger.Fields{"stream": sub.params.StreamName, "subscription": subscriptionID})
+		// subscription was canceled while resuming: its unsubscribe was skipped for the old connection
+		canceled := !sub.active
+		w.subscriptionM.Unlock()
+		w.messageM.Unlock()
+
+		if canceled {
+			if err := w.unsubscribe(sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -4,17 +4,65 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/sourcegraph/jsonrpc2"
+	"sync"
 )
 
 // entry to track an active subscription on connection: channel to send updates on and reference to cancel the subscription
 type subscriptionEntry struct {
+	// server assigned ID, which changes whenever the subscription is resumed on a new connection
+	id string
+	// original subscription request, used to resume the subscription after a reconnect
+	params SubscribeParams
+	// connection the subscription ID is registered on
+	conn   *wsConn
 	active bool
-	ch     chan json.RawMessage
-	cancel context.CancelFunc
+	// overflowed is set when the subscription is ended for not reading its updates fast enough
+	overflowed bool
+	cancel     context.CancelFunc
+
+	// updates waiting to be read, guarded by their own lock so that the reader never waits for the subscriber or holds
+	// the subscription lock while queueing them
+	updatesM sync.Mutex
+	updates  []json.RawMessage
+	// ready is signalled whenever updates are queued
+	ready chan struct{}
+	// disconnectOnOverflow limits the queue to subscriptionBuffer updates
+	disconnectOnOverflow bool
+}
+
+// push queues an update, and returns false if the queue is full
+func (s *subscriptionEntry) push(update json.RawMessage) bool {
+	s.updatesM.Lock()
+	if s.disconnectOnOverflow && len(s.updates) >= subscriptionBuffer {
+		s.updatesM.Unlock()
+		return false
+	}
+	s.updates = append(s.updates, update)
+	s.updatesM.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop dequeues the oldest update
+func (s *subscriptionEntry) pop() (json.RawMessage, bool) {
+	s.updatesM.Lock()
+	defer s.updatesM.Unlock()
+
+	if len(s.updates) == 0 {
+		return nil, false
+	}
+	update := s.updates[0]
+	s.updates[0] = nil
+	s.updates = s.updates[1:]
+	return update, true
 }
 
-func (s subscriptionEntry) close() {
-	close(s.ch)
+func (s *subscriptionEntry) close() {
+	s.active = false
 	s.cancel()
 }
 
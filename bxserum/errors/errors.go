@@ -0,0 +1,201 @@
+// Package errors is the error model shared by the GRPC, HTTP and WS clients. Errors returned by the clients are *Error
+// values classified into one of the sentinel errors below when possible, so they can be handled the same way regardless
+// of transport:
+//
+//	if errors.Is(err, bxerrors.ErrMarketNotFound) { ... }
+//
+//	var apiErr *bxerrors.Error
+//	if errors.As(err, &apiErr) { log.Println(apiErr.Transport, apiErr.Code) }
+//
+// The original error is kept as the cause, so errors.Is and errors.As also match it (e.g. context.DeadlineExceeded).
+package errors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrMarketNotFound    = errors.New("market not found")
+	ErrInvalidAddress    = errors.New("invalid address")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrTransportClosed   = errors.New("transport closed")
+	ErrTimeout           = errors.New("timeout")
+	// ErrAlreadyProcessed is returned for transactions submitted again after they landed
+	ErrAlreadyProcessed = errors.New("transaction already processed")
+)
+
+// Transport is the protocol a client talks to Serum API with
+type Transport string
+
+const (
+	TransportGRPC Transport = "grpc"
+	TransportHTTP Transport = "http"
+	TransportWS   Transport = "ws"
+)
+
+// Error is an error returned by a client
+type Error struct {
+	// Kind is one of the sentinel errors of this package, nil if the error couldn't be classified
+	Kind      error
+	Transport Transport
+	// Code is the code reported by the server: the gRPC status code over GRPC and HTTP, and the JSON-RPC error code
+	// over WS. It's 0 for errors that didn't come from the server.
+	Code int
+	// HTTPStatus is the status of the HTTP response the error was read from
+	HTTPStatus int
+	// Message is the error message reported by the server, or the message of the cause
+	Message string
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches the sentinel error the error was classified into
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// GRPCStatus keeps status.FromError and status.Code working on errors of every transport
+func (e *Error) GRPCStatus() *status.Status {
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(e.Cause, &se) {
+		return se.GRPCStatus()
+	}
+	if e.Transport == TransportHTTP && e.Code != 0 {
+		return status.New(codes.Code(e.Code), e.Message)
+	}
+	return status.New(codes.Unknown, e.Error())
+}
+
+// Wrap maps an error returned over transport into an *Error. Nil, io.EOF (the end of a stream) and errors that are
+// already an *Error are returned as is.
+func Wrap(transport Transport, err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	e = &Error{Transport: transport, Message: err.Error(), Cause: err}
+	var (
+		httpErr *connections.HTTPError
+		rpcErr  *connections.RPCError
+		connErr *connections.ConnectionError
+	)
+	switch st, ok := status.FromError(err); {
+	case ok:
+		e.Code, e.Message = int(st.Code()), st.Message()
+		e.Kind = kindFromCode(st.Code(), st.Message())
+	case asHTTPError(err, &httpErr):
+		e.Code, e.HTTPStatus, e.Message = httpErr.Code, httpErr.StatusCode, httpErr.Message
+		e.Kind = kindFromHTTP(httpErr)
+	case errors.As(err, &rpcErr):
+		e.Code, e.Message = int(rpcErr.Code), rpcErr.Message
+		e.Kind = kindFromMessage(rpcErr.Message)
+	case errors.As(err, &connErr):
+		e.Kind = ErrTransportClosed
+	default:
+		e.Kind = kindFromLocal(err)
+	}
+	return e
+}
+
+// asHTTPError is errors.As for connections.HTTPError, which is returned by value
+func asHTTPError(err error, target **connections.HTTPError) bool {
+	var httpErr connections.HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	*target = &httpErr
+	return true
+}
+
+func kindFromCode(code codes.Code, message string) error {
+	switch code {
+	case codes.ResourceExhausted:
+		return ErrRateLimited
+	case codes.Unavailable:
+		return ErrTransportClosed
+	case codes.DeadlineExceeded:
+		return ErrTimeout
+	case codes.Canceled:
+		// returned for calls on a closed connection, as well as for cancelled contexts
+		if strings.Contains(message, "client connection is closing") {
+			return ErrTransportClosed
+		}
+		return nil
+	case codes.NotFound:
+		if strings.Contains(strings.ToLower(message), "market") {
+			return ErrMarketNotFound
+		}
+	}
+	return kindFromMessage(message)
+}
+
+func kindFromHTTP(httpErr *connections.HTTPError) error {
+	switch httpErr.StatusCode {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrTransportClosed
+	case http.StatusGatewayTimeout:
+		return ErrTimeout
+	}
+	if httpErr.Code != 0 {
+		return kindFromCode(codes.Code(httpErr.Code), httpErr.Message)
+	}
+	return kindFromMessage(httpErr.Message)
+}
+
+// kindFromMessage classifies errors that don't come with a meaningful code by their message
+func kindFromMessage(message string) error {
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "market") && strings.Contains(message, "not found"):
+		return ErrMarketNotFound
+	case strings.Contains(message, "insufficient"):
+		return ErrInsufficientFunds
+	case strings.Contains(message, "base58") || strings.Contains(message, "public key") || strings.Contains(message, "invalid address"):
+		return ErrInvalidAddress
+	case strings.Contains(message, "rate limit") || strings.Contains(message, "too many requests"):
+		return ErrRateLimited
+	case strings.Contains(message, "already been processed") || strings.Contains(message, "already processed"):
+		return ErrAlreadyProcessed
+	}
+	return nil
+}
+
+// kindFromLocal classifies errors raised by the client itself rather than the server
+func kindFromLocal(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	case errors.Is(err, net.ErrClosed), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrTransportClosed
+	}
+	return nil
+}
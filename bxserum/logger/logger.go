@@ -0,0 +1,39 @@
+// Package logger defines the structured logger used by the connections and provider packages. Set RPCOpts.Logger (or
+// connections.WSOpts.Logger) to an implementation, such as the adapters in bxserum/logger/logrus and
+// bxserum/logger/slog, to receive the logs.
+package logger
+
+// Fields are the structured context of a log entry, e.g. the stream or subscription it's about
+type Fields map[string]interface{}
+
+// Logger receives the logs of the clients. Levels are used as follows:
+//
+//   - Debug: subscriptions starting and ending, dropped stream updates and canceled requests
+//   - Info: connections being established, re-established or closed on request, and requests rejected by the API
+//   - Warn: connections being lost, failed reconnection attempts, and requests failing with transport errors
+//   - Error: connections closed for good because of an error
+//
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	Debug(msg string, fields Fields)
+	Info(msg string, fields Fields)
+	Warn(msg string, fields Fields)
+	Error(msg string, fields Fields)
+	// With returns a Logger adding fields to every entry
+	With(fields Fields) Logger
+}
+
+// Nop discards all logs
+type Nop struct{}
+
+func (Nop) Debug(string, Fields) {}
+
+func (Nop) Info(string, Fields) {}
+
+func (Nop) Warn(string, Fields) {}
+
+func (Nop) Error(string, Fields) {}
+
+func (n Nop) With(Fields) Logger {
+	return n
+}
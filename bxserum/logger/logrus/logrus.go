@@ -0,0 +1,39 @@
+// Package logrus writes the logs of the clients to a logrus logger
+package logrus
+
+import (
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger implements logger.Logger with a logrus.FieldLogger
+type Logger struct {
+	entry logrus.FieldLogger
+}
+
+var _ logger.Logger = Logger{}
+
+// New writes logs to l, e.g. logrus.StandardLogger() or an entry with fields of its own
+func New(l logrus.FieldLogger) Logger {
+	return Logger{entry: l}
+}
+
+func (l Logger) Debug(msg string, fields logger.Fields) {
+	l.entry.WithFields(logrus.Fields(fields)).Debug(msg)
+}
+
+func (l Logger) Info(msg string, fields logger.Fields) {
+	l.entry.WithFields(logrus.Fields(fields)).Info(msg)
+}
+
+func (l Logger) Warn(msg string, fields logger.Fields) {
+	l.entry.WithFields(logrus.Fields(fields)).Warn(msg)
+}
+
+func (l Logger) Error(msg string, fields logger.Fields) {
+	l.entry.WithFields(logrus.Fields(fields)).Error(msg)
+}
+
+func (l Logger) With(fields logger.Fields) logger.Logger {
+	return Logger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
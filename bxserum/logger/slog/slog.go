@@ -0,0 +1,67 @@
+//go:build go1.21
+
+// Package slog writes the logs of the clients to a log/slog logger. The package requires Go 1.21 or later, which
+// introduced log/slog, while the rest of the module builds with Go 1.18.
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+)
+
+// Logger implements logger.Logger with a *slog.Logger
+type Logger struct {
+	l *slog.Logger
+}
+
+var _ logger.Logger = Logger{}
+
+// New writes logs to l, or to slog.Default() if nil
+func New(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return Logger{l: l}
+}
+
+func (l Logger) Debug(msg string, fields logger.Fields) {
+	l.log(slog.LevelDebug, msg, fields)
+}
+
+func (l Logger) Info(msg string, fields logger.Fields) {
+	l.log(slog.LevelInfo, msg, fields)
+}
+
+func (l Logger) Warn(msg string, fields logger.Fields) {
+	l.log(slog.LevelWarn, msg, fields)
+}
+
+func (l Logger) Error(msg string, fields logger.Fields) {
+	l.log(slog.LevelError, msg, fields)
+}
+
+func (l Logger) With(fields logger.Fields) logger.Logger {
+	return Logger{l: l.l.With(attrs(fields)...)}
+}
+
+func (l Logger) log(level slog.Level, msg string, fields logger.Fields) {
+	l.l.Log(context.Background(), level, msg, attrs(fields)...)
+}
+
+// attrs sorts fields by key, so entries are written the same way every time
+func attrs(fields logger.Fields) []any {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]any, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, slog.Any(key, fields[key]))
+	}
+	return result
+}
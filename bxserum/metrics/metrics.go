@@ -0,0 +1,64 @@
+// Package metrics defines the instrumentation interface of the provider clients. Set RPCOpts.Metrics to an
+// implementation, such as the Prometheus adapter in bxserum/metrics/prometheus, to record it.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics receives the measurements of provider clients. Transport is one of the bxerrors.Transport values, and
+// implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called after every request (every attempt, when retried) with its duration and error, nil on
+	// success
+	ObserveRequest(transport bxerrors.Transport, method string, duration time.Duration, err error)
+	// AddSubscriptions changes the number of active subscriptions to a stream: +1 when it starts and -1 when it ends
+	AddSubscriptions(transport bxerrors.Transport, stream string, delta int)
+	// ObserveStreamMessage is called for every message received from a stream
+	ObserveStreamMessage(transport bxerrors.Transport, stream string)
+	// SetWSWriteQueueDepth reports the number of messages waiting to be written to the websocket connection
+	SetWSWriteQueueDepth(depth int)
+}
+
+// Nop discards all measurements
+type Nop struct{}
+
+func (Nop) ObserveRequest(bxerrors.Transport, string, time.Duration, error) {}
+
+func (Nop) AddSubscriptions(bxerrors.Transport, string, int) {}
+
+func (Nop) ObserveStreamMessage(bxerrors.Transport, string) {}
+
+func (Nop) SetWSWriteQueueDepth(int) {}
+
+// ErrorType labels err by its bxserum/errors kind: "market_not_found", "invalid_address", "insufficient_funds",
+// "rate_limited", "transport_closed" or "timeout". Other errors are "canceled" if their context was, and "other"
+// otherwise. Nil errors have no type.
+func ErrorType(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, bxerrors.ErrMarketNotFound):
+		return "market_not_found"
+	case errors.Is(err, bxerrors.ErrInvalidAddress):
+		return "invalid_address"
+	case errors.Is(err, bxerrors.ErrInsufficientFunds):
+		return "insufficient_funds"
+	case errors.Is(err, bxerrors.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, bxerrors.ErrTransportClosed):
+		return "transport_closed"
+	case errors.Is(err, bxerrors.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, context.Canceled), status.Code(err) == codes.Canceled:
+		return "canceled"
+	default:
+		return "other"
+	}
+}
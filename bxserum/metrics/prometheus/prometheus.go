@@ -0,0 +1,107 @@
+// Package prometheus records the metrics of provider clients with Prometheus collectors
+package prometheus
+
+import (
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultNamespace = "serum_client"
+
+// Opts configures the collectors
+type Opts struct {
+	// Namespace prefixes the metric names, defaults to serum_client
+	Namespace string
+	// ConstLabels are added to every metric, e.g. to tell clients apart
+	ConstLabels prometheus.Labels
+	// Buckets of the request latency histogram in seconds, defaults to prometheus.DefBuckets
+	Buckets []float64
+}
+
+// Metrics implements metrics.Metrics with Prometheus collectors:
+//
+//   - requests_total{transport, method}
+//   - request_duration_seconds{transport, method}
+//   - request_errors_total{transport, method, type}, with the types of metrics.ErrorType
+//   - active_subscriptions{transport, stream}
+//   - stream_messages_total{transport, stream}
+//   - ws_write_queue_depth
+type Metrics struct {
+	requests       *prometheus.CounterVec
+	latency        *prometheus.HistogramVec
+	errors         *prometheus.CounterVec
+	subscriptions  *prometheus.GaugeVec
+	streamMessages *prometheus.CounterVec
+	writeQueue     prometheus.Gauge
+}
+
+var _ metrics.Metrics = (*Metrics)(nil)
+
+// New creates the collectors and registers them with registerer (e.g. prometheus.DefaultRegisterer)
+func New(registerer prometheus.Registerer, opts Opts) (*Metrics, error) {
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	buckets := opts.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "requests_total", ConstLabels: opts.ConstLabels,
+			Help: "Requests sent to Serum API, by transport and method",
+		}, []string{"transport", "method"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "request_duration_seconds", ConstLabels: opts.ConstLabels, Buckets: buckets,
+			Help: "Latency of requests sent to Serum API, by transport and method",
+		}, []string{"transport", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "request_errors_total", ConstLabels: opts.ConstLabels,
+			Help: "Failed requests to Serum API, by transport, method and error type",
+		}, []string{"transport", "method", "type"}),
+		subscriptions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "active_subscriptions", ConstLabels: opts.ConstLabels,
+			Help: "Active stream subscriptions, by transport and stream",
+		}, []string{"transport", "stream"}),
+		streamMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "stream_messages_total", ConstLabels: opts.ConstLabels,
+			Help: "Messages received from streams, by transport and stream",
+		}, []string{"transport", "stream"}),
+		writeQueue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "ws_write_queue_depth", ConstLabels: opts.ConstLabels,
+			Help: "Messages waiting to be written to the websocket connection",
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.requests, m.latency, m.errors, m.subscriptions, m.streamMessages, m.writeQueue} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Metrics) ObserveRequest(transport bxerrors.Transport, method string, duration time.Duration, err error) {
+	m.requests.WithLabelValues(string(transport), method).Inc()
+	m.latency.WithLabelValues(string(transport), method).Observe(duration.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(string(transport), method, metrics.ErrorType(err)).Inc()
+	}
+}
+
+func (m *Metrics) AddSubscriptions(transport bxerrors.Transport, stream string, delta int) {
+	m.subscriptions.WithLabelValues(string(transport), stream).Add(float64(delta))
+}
+
+func (m *Metrics) ObserveStreamMessage(transport bxerrors.Transport, stream string) {
+	m.streamMessages.WithLabelValues(string(transport), stream).Inc()
+}
+
+func (m *Metrics) SetWSWriteQueueDepth(depth int) {
+	m.writeQueue.Set(float64(depth))
+}
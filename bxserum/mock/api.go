@@ -0,0 +1,529 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	ErrMarketNotFound = status.Error(codes.NotFound, "provided market name/address was not found")
+	ErrOrderNotFound  = status.Error(codes.NotFound, "order was not found")
+
+	errInvalidPublicKey = status.Error(codes.InvalidArgument, "invalid len base58 public key string")
+)
+
+type market struct {
+	info         *pb.Market
+	minOrderSize float64
+	// bids are sorted by descending price, asks by ascending price
+	bids   []*pb.OrderbookItem
+	asks   []*pb.OrderbookItem
+	trades []trade
+}
+
+type trade struct {
+	*pb.Trade
+	time time.Time
+}
+
+type order struct {
+	*pb.Order
+	owner  string
+	status pb.OrderStatus
+}
+
+type account struct {
+	balances []*pb.TokenBalance
+	// unsettled and openOrders are keyed by market name
+	unsettled  map[string][]*pb.UnsettledAccount
+	openOrders map[string]string
+}
+
+// API is an in-memory implementation of the Serum API. Markets, orderbooks, trades and accounts are scripted through
+// its exported methods, and transactions built by its Post* methods change that state once they are submitted.
+//
+// Stream subscribers are only updated when a block is published with AdvanceBlock or ProduceBlocks: every block carries
+// the current orderbooks, along with the trades and order status changes since the previous block.
+type API struct {
+	pb.UnimplementedApiServer
+
+	m           sync.RWMutex
+	blockHeight int64
+	markets     map[string]*market
+	depthMarket string
+	orders      []*order
+	accounts    map[string]*account
+	orderID     uint64
+	txID        uint64
+	pending     map[string]pendingTx
+	submissions []string
+
+	// changes since the last published block
+	blockTrades   map[string][]*pb.Trade
+	blockStatuses []orderStatus
+
+	publishM    sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// NewAPI returns an API without any markets
+func NewAPI() *API {
+	return &API{
+		// heights start at 1 as on chain, since a prevBlockHeight of 0 marks market depth snapshots
+		blockHeight: 1,
+		markets:     make(map[string]*market),
+		accounts:    make(map[string]*account),
+		pending:     make(map[string]pendingTx),
+		blockTrades: make(map[string][]*pb.Trade),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// AddMarket lists a market (e.g. SOL/USDC) at the given address. Orders smaller than minOrderSize fail simulation on submission.
+func (a *API) AddMarket(name, address string, minOrderSize float64) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	a.markets[name] = &market{
+		info:         &pb.Market{Market: name, Status: pb.MarketStatus_MS_ONLINE, Address: address},
+		minOrderSize: minOrderSize,
+	}
+	if a.depthMarket == "" {
+		a.depthMarket = name
+	}
+}
+
+// SetDepthMarket selects the market followed by GetMarketDepthStream, which defaults to the first market added
+func (a *API) SetDepthMarket(marketName string) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(marketName)
+	if err != nil {
+		return err
+	}
+	a.depthMarket = m.info.Market
+	return nil
+}
+
+// SetOrderbook replaces the market's orderbook. Levels are sorted, so they can be provided in any order.
+func (a *API) SetOrderbook(marketName string, bids, asks []*pb.OrderbookItem) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(marketName)
+	if err != nil {
+		return err
+	}
+
+	m.bids = cloneLevels(bids)
+	sort.Slice(m.bids, func(i, j int) bool { return m.bids[i].Price > m.bids[j].Price })
+	m.asks = cloneLevels(asks)
+	sort.Slice(m.asks, func(i, j int) bool { return m.asks[i].Price < m.asks[j].Price })
+	return nil
+}
+
+// AddTrades records trades executed on the market at the current time
+func (a *API) AddTrades(marketName string, trades ...*pb.Trade) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(marketName)
+	if err != nil {
+		return err
+	}
+	for _, t := range trades {
+		a.addTrade(m, proto.Clone(t).(*pb.Trade))
+	}
+	return nil
+}
+
+// SetBalances replaces the owner's token balances
+func (a *API) SetBalances(owner string, tokens ...*pb.TokenBalance) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	a.account(owner).balances = tokens
+}
+
+// SetUnsettled replaces the owner's unsettled funds on the market
+func (a *API) SetUnsettled(marketName, owner string, accounts ...*pb.UnsettledAccount) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(marketName)
+	if err != nil {
+		return err
+	}
+	a.account(owner).unsettled[m.info.Market] = accounts
+	return nil
+}
+
+// AddOrder opens an order for owner and returns its ID. The order ID, creation time and open orders account are filled in when missing.
+func (a *API) AddOrder(owner string, o *pb.Order) (string, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(o.Market)
+	if err != nil {
+		return "", err
+	}
+
+	o = proto.Clone(o).(*pb.Order)
+	o.Market = m.info.Market
+	if o.OrderID == "" {
+		a.orderID++
+		o.OrderID = fmt.Sprint(a.orderID)
+	}
+	if o.CreatedAt == nil {
+		o.CreatedAt = timestamppb.Now()
+	}
+	if o.OpenOrderAccount == "" {
+		o.OpenOrderAccount = a.openOrdersAddress(owner, m.info.Market)
+	}
+
+	a.orders = append(a.orders, &order{Order: o, owner: owner, status: pb.OrderStatus_OS_OPEN})
+	a.blockStatuses = append(a.blockStatuses, newOrderStatus(owner, o, pb.OrderStatus_OS_OPEN, 0))
+	return o.OrderID, nil
+}
+
+// FillOrder executes size of an open order, recording the trade on its market
+func (a *API) FillOrder(orderID string, size float64) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	o, err := a.openOrder(orderID)
+	if err != nil {
+		return err
+	}
+	if size > o.RemainingSize {
+		size = o.RemainingSize
+	}
+
+	o.RemainingSize -= size
+	o.status = pb.OrderStatus_OS_PARTIAL_FILL
+	if o.RemainingSize <= 0 {
+		o.status = pb.OrderStatus_OS_FILLED
+	}
+	a.addTrade(a.markets[o.Market], &pb.Trade{Side: o.Side, Size: size, Price: o.Price, OrderID: o.OrderID, IsMaker: true})
+	a.blockStatuses = append(a.blockStatuses, newOrderStatus(o.owner, o.Order, o.status, size))
+	return nil
+}
+
+// CancelOrder removes an open order from the book
+func (a *API) CancelOrder(orderID string) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	o, err := a.openOrder(orderID)
+	if err != nil {
+		return err
+	}
+	a.cancel(o)
+	return nil
+}
+
+// Submissions returns every transaction submitted with PostSubmit, in order
+func (a *API) Submissions() []string {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	return append([]string(nil), a.submissions...)
+}
+
+func (a *API) GetMarkets(context.Context, *pb.GetMarketsRequest) (*pb.GetMarketsResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	markets := make(map[string]*pb.Market)
+	for name, m := range a.markets {
+		markets[name] = proto.Clone(m.info).(*pb.Market)
+	}
+	return &pb.GetMarketsResponse{Markets: markets}, nil
+}
+
+func (a *API) GetOrderbook(_ context.Context, request *pb.GetOrderbookRequest) (*pb.GetOrderbookResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+	return m.orderbook(request.Limit), nil
+}
+
+func (a *API) GetTickers(_ context.Context, request *pb.GetTickersRequest) (*pb.GetTickersResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	return a.tickers(request.Market)
+}
+
+func (a *API) GetTrades(_ context.Context, request *pb.GetTradesRequest) (*pb.GetTradesResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []*pb.Trade
+	for _, t := range m.trades {
+		trades = append(trades, proto.Clone(t.Trade).(*pb.Trade))
+	}
+	return &pb.GetTradesResponse{Trades: lastN(trades, request.Limit)}, nil
+}
+
+// GetKline aggregates the market's recorded trades into candles
+func (a *API) GetKline(_ context.Context, request *pb.GetKlineRequest) (*pb.GetKlineResponse, error) {
+	resolution, err := parseResolution(request.Resolution)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := request.From.AsTime(), request.To.AsTime()
+	if request.To == nil {
+		to = time.Now()
+	}
+
+	var candles []*pb.Candle
+	var candle *pb.Candle
+	var candleEnd time.Time
+	for _, t := range m.trades {
+		if t.time.Before(from) || !t.time.Before(to) {
+			continue
+		}
+		if candle == nil || !t.time.Before(candleEnd) {
+			if request.Limit != 0 && len(candles) == int(request.Limit) {
+				break
+			}
+			start := from.Add(t.time.Sub(from).Truncate(resolution))
+			candleEnd = start.Add(resolution)
+			candle = &pb.Candle{StartTime: timestamppb.New(start), Open: t.Price, Low: t.Price, High: t.Price}
+			candles = append(candles, candle)
+		}
+
+		candle.UpdateTime = timestamppb.New(t.time)
+		candle.Close = t.Price
+		if t.Price < candle.Low {
+			candle.Low = t.Price
+		}
+		if t.Price > candle.High {
+			candle.High = t.Price
+		}
+		candle.Amount += t.Size * t.Price
+		candle.Volume += t.Size
+		candle.Count++
+	}
+	return &pb.GetKlineResponse{Market: m.info.Market, Timestamp: timestamppb.Now(), Candles: candles}, nil
+}
+
+func (a *API) GetServerTime(context.Context, *pb.GetServerTimeRequest) (*pb.GetServerTimeResponse, error) {
+	return &pb.GetServerTimeResponse{Timestamp: time.Now().UTC().Format(time.RFC3339Nano)}, nil
+}
+
+func (a *API) GetAccountBalance(_ context.Context, request *pb.GetAccountBalanceRequest) (*pb.GetAccountBalanceResponse, error) {
+	if err := validatePublicKey(request.OwnerAddress); err != nil {
+		return nil, err
+	}
+
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	var tokens []*pb.TokenBalance
+	if acc, ok := a.accounts[request.OwnerAddress]; ok {
+		for _, token := range acc.balances {
+			tokens = append(tokens, proto.Clone(token).(*pb.TokenBalance))
+		}
+	}
+	return &pb.GetAccountBalanceResponse{Tokens: tokens}, nil
+}
+
+func (a *API) GetUnsettled(_ context.Context, request *pb.GetUnsettledRequest) (*pb.GetUnsettledResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePublicKey(request.Owner); err != nil {
+		return nil, err
+	}
+
+	var unsettled []*pb.UnsettledAccount
+	if acc, ok := a.accounts[request.Owner]; ok {
+		for _, u := range acc.unsettled[m.info.Market] {
+			unsettled = append(unsettled, proto.Clone(u).(*pb.UnsettledAccount))
+		}
+	}
+	return &pb.GetUnsettledResponse{Market: m.info.Address, Unsettled: unsettled}, nil
+}
+
+// market finds a market by name (e.g. SOL/USDC, SOLUSDC or SOL-USDC) or address. Requires a.m to be held.
+func (a *API) market(name string) (*market, error) {
+	if m, ok := a.markets[name]; ok {
+		return m, nil
+	}
+	for _, m := range a.markets {
+		if name == m.info.Address || name == strings.ReplaceAll(m.info.Market, "/", "") || name == strings.ReplaceAll(m.info.Market, "/", "-") {
+			return m, nil
+		}
+	}
+	return nil, ErrMarketNotFound
+}
+
+// account returns the owner's account, creating it if needed. Requires a.m to be held for writing.
+func (a *API) account(owner string) *account {
+	acc, ok := a.accounts[owner]
+	if !ok {
+		acc = &account{unsettled: make(map[string][]*pb.UnsettledAccount), openOrders: make(map[string]string)}
+		a.accounts[owner] = acc
+	}
+	return acc
+}
+
+// openOrdersAddress returns the owner's open orders account on the market, generating one on first use
+func (a *API) openOrdersAddress(owner, marketName string) string {
+	acc := a.account(owner)
+	address, ok := acc.openOrders[marketName]
+	if !ok {
+		address = solana.NewWallet().PublicKey().String()
+		acc.openOrders[marketName] = address
+	}
+	return address
+}
+
+func (a *API) openOrder(orderID string) (*order, error) {
+	for _, o := range a.orders {
+		if o.OrderID == orderID && isOpen(o.status) {
+			return o, nil
+		}
+	}
+	return nil, ErrOrderNotFound
+}
+
+func (a *API) cancel(o *order) {
+	o.status = pb.OrderStatus_OS_CANCELLED
+	a.blockStatuses = append(a.blockStatuses, newOrderStatus(o.owner, o.Order, o.status, o.RemainingSize))
+}
+
+func (a *API) addTrade(m *market, t *pb.Trade) {
+	m.trades = append(m.trades, trade{Trade: t, time: time.Now()})
+	a.blockTrades[m.info.Market] = append(a.blockTrades[m.info.Market], t)
+}
+
+func (a *API) tickers(marketName string) (*pb.GetTickersResponse, error) {
+	var markets []*market
+	if marketName == "" {
+		for _, m := range a.markets {
+			markets = append(markets, m)
+		}
+		sort.Slice(markets, func(i, j int) bool { return markets[i].info.Market < markets[j].info.Market })
+	} else {
+		m, err := a.market(marketName)
+		if err != nil {
+			return nil, err
+		}
+		markets = append(markets, m)
+	}
+
+	tickers := make([]*pb.Ticker, 0, len(markets))
+	for _, m := range markets {
+		tickers = append(tickers, m.ticker())
+	}
+	return &pb.GetTickersResponse{Tickers: tickers}, nil
+}
+
+func (m *market) orderbook(limit uint32) *pb.GetOrderbookResponse {
+	return &pb.GetOrderbookResponse{
+		Market:        m.info.Market,
+		MarketAddress: m.info.Address,
+		Bids:          cloneLevels(firstN(m.bids, limit)),
+		Asks:          cloneLevels(firstN(m.asks, limit)),
+	}
+}
+
+func (m *market) ticker() *pb.Ticker {
+	ticker := &pb.Ticker{Market: m.info.Market, MarketAddress: m.info.Address}
+	if len(m.bids) > 0 {
+		ticker.Bid, ticker.BidSize = m.bids[0].Price, m.bids[0].Size
+	}
+	if len(m.asks) > 0 {
+		ticker.Ask, ticker.AskSize = m.asks[0].Price, m.asks[0].Size
+	}
+	return ticker
+}
+
+func isOpen(s pb.OrderStatus) bool {
+	return s == pb.OrderStatus_OS_OPEN || s == pb.OrderStatus_OS_PARTIAL_FILL
+}
+
+func validatePublicKey(address string) error {
+	if _, err := solana.PublicKeyFromBase58(address); err != nil {
+		return errInvalidPublicKey
+	}
+	return nil
+}
+
+// parseResolution accepts the kline resolutions supported by the Serum API (e.g. 1d, 4h, 30m), defaulting to 1m
+func parseResolution(resolution string) (time.Duration, error) {
+	if resolution == "" {
+		return time.Minute, nil
+	}
+	if strings.HasSuffix(resolution, "d") {
+		var days int
+		if _, err := fmt.Sscanf(resolution, "%dd", &days); err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid resolution %v", resolution)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(resolution)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid resolution %v", resolution)
+	}
+	return d, nil
+}
+
+func cloneLevels(levels []*pb.OrderbookItem) []*pb.OrderbookItem {
+	cloned := make([]*pb.OrderbookItem, 0, len(levels))
+	for _, level := range levels {
+		cloned = append(cloned, &pb.OrderbookItem{Price: level.Price, Size: level.Size})
+	}
+	return cloned
+}
+
+func firstN[T any](s []T, limit uint32) []T {
+	if limit != 0 && int(limit) < len(s) {
+		return s[:limit]
+	}
+	return s
+}
+
+func lastN[T any](s []T, limit uint32) []T {
+	if limit != 0 && int(limit) < len(s) {
+		return s[len(s)-int(limit):]
+	}
+	return s
+}
@@ -0,0 +1,300 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const subscriberBuffer = 100
+
+// block is the state published to stream subscribers at a block height
+type block struct {
+	height     int64
+	orderbooks map[string]*pb.GetOrderbookResponse
+	tickers    *pb.GetTickersResponse
+	trades     map[string][]*pb.Trade
+	statuses   []orderStatus
+}
+
+type subscriber struct {
+	ch   chan *block
+	done <-chan struct{}
+}
+
+// AdvanceBlock publishes a new block to every stream subscriber and returns its height
+func (a *API) AdvanceBlock() int64 {
+	a.publishM.Lock()
+	defer a.publishM.Unlock()
+
+	a.m.Lock()
+	a.blockHeight++
+	b := a.snapshot()
+	b.trades = a.blockTrades
+	b.statuses = a.blockStatuses
+	a.blockTrades = make(map[string][]*pb.Trade)
+	a.blockStatuses = nil
+
+	subscribers := make([]*subscriber, 0, len(a.subscribers))
+	for s := range a.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	a.m.Unlock()
+
+	for _, s := range subscribers {
+		select {
+		case s.ch <- b:
+		case <-s.done:
+		}
+	}
+	return b.height
+}
+
+// ProduceBlocks calls AdvanceBlock every interval until ctx is done
+func (a *API) ProduceBlocks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.AdvanceBlock()
+		}
+	}
+}
+
+// Subscribers returns the number of open streams, so scripts can wait for clients to subscribe before publishing blocks
+func (a *API) Subscribers() int {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	return len(a.subscribers)
+}
+
+// GetOrderbooksStream sends the current orderbooks of the requested markets, then their orderbooks at every block
+func (a *API) GetOrderbooksStream(request *pb.GetOrderbooksRequest, stream pb.Api_GetOrderbooksStreamServer) error {
+	a.m.RLock()
+	var markets []string
+	for _, name := range request.Markets {
+		m, err := a.market(name)
+		if err != nil {
+			a.m.RUnlock()
+			return err
+		}
+		markets = append(markets, m.info.Market)
+	}
+	a.m.RUnlock()
+
+	return a.stream(stream.Context(), stream, func(b *block, _ bool) error {
+		for _, name := range markets {
+			orderbook := b.orderbooks[name]
+			orderbook.Bids = firstN(orderbook.Bids, request.Limit)
+			orderbook.Asks = firstN(orderbook.Asks, request.Limit)
+			if err := stream.Send(&pb.GetOrderbooksStreamResponse{BlockHeight: b.height, Orderbook: orderbook}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetTickersStream sends the current tickers, then the tickers at every block
+func (a *API) GetTickersStream(request *pb.GetTickersRequest, stream pb.Api_GetTickersStreamServer) error {
+	marketName := ""
+	if request.Market != "" {
+		a.m.RLock()
+		m, err := a.market(request.Market)
+		a.m.RUnlock()
+		if err != nil {
+			return err
+		}
+		marketName = m.info.Market
+	}
+
+	return a.stream(stream.Context(), stream, func(b *block, _ bool) error {
+		tickers := b.tickers
+		if marketName != "" {
+			tickers = &pb.GetTickersResponse{}
+			for _, ticker := range b.tickers.Tickers {
+				if ticker.Market == marketName {
+					tickers.Tickers = append(tickers.Tickers, ticker)
+				}
+			}
+		}
+		return stream.Send(&pb.GetTickersStreamResponse{BlockHeight: b.height, Ticker: tickers})
+	})
+}
+
+// GetMarketDepthStream follows the depth market (see SetDepthMarket). The first tick is a full snapshot with
+// prevBlockHeight 0, and every following tick carries the levels that changed since the previous tick, with a size of
+// 0 for levels that were removed.
+func (a *API) GetMarketDepthStream(_ *pb.GetMarketsRequest, stream pb.Api_GetMarketDepthStreamServer) error {
+	a.m.RLock()
+	marketName := a.depthMarket
+	a.m.RUnlock()
+
+	var prev *pb.GetOrderbookResponse
+	var prevHeight int64
+	return a.stream(stream.Context(), stream, func(b *block, initial bool) error {
+		orderbook, ok := b.orderbooks[marketName]
+		if !ok {
+			return nil
+		}
+
+		tick := &pb.MarketDepthTick{PrevBlockHeight: prevHeight}
+		if initial || prev == nil {
+			tick.PrevBlockHeight = 0
+			tick.Bids, tick.Asks = orderbook.Bids, orderbook.Asks
+		} else {
+			tick.Bids = levelChanges(prev.Bids, orderbook.Bids)
+			tick.Asks = levelChanges(prev.Asks, orderbook.Asks)
+			if len(tick.Bids) == 0 && len(tick.Asks) == 0 {
+				return nil
+			}
+		}
+
+		prev, prevHeight = orderbook, b.height
+		return stream.Send(&pb.GetMarketDepthStreamResponse{BlockHeight: b.height, Tick: tick})
+	})
+}
+
+// GetTradesStream sends the trades executed on the market in every block that has any
+func (a *API) GetTradesStream(request *pb.GetTradesRequest, stream pb.Api_GetTradesStreamServer) error {
+	a.m.RLock()
+	m, err := a.market(request.Market)
+	a.m.RUnlock()
+	if err != nil {
+		return err
+	}
+	marketName := m.info.Market
+
+	return a.stream(stream.Context(), stream, func(b *block, _ bool) error {
+		trades := lastN(b.trades[marketName], request.Limit)
+		if len(trades) == 0 {
+			return nil
+		}
+		return stream.Send(&pb.GetTradesStreamResponse{BlockHeight: b.height, Trades: &pb.GetTradesResponse{Trades: trades}})
+	})
+}
+
+// GetOrderStatusStream sends the status changes of the owner's orders on the market in every block
+func (a *API) GetOrderStatusStream(request *pb.GetOrderStatusStreamRequest, stream pb.Api_GetOrderStatusStreamServer) error {
+	a.m.RLock()
+	m, err := a.market(request.Market)
+	a.m.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := validatePublicKey(request.OwnerAddress); err != nil {
+		return err
+	}
+	marketName := m.info.Market
+
+	return a.stream(stream.Context(), stream, func(b *block, _ bool) error {
+		for _, s := range b.statuses {
+			if s.owner != request.OwnerAddress || s.info.Market != marketName {
+				continue
+			}
+			if err := stream.Send(&pb.GetOrderStatusStreamResponse{BlockHeight: b.height, OrderInfo: s.info}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stream subscribes to blocks, calls send with a snapshot of the current state and then with every published block
+// until ctx is done
+func (a *API) stream(ctx context.Context, ss interface{ SendHeader(metadata.MD) error }, send func(b *block, initial bool) error) error {
+	s := &subscriber{ch: make(chan *block, subscriberBuffer), done: ctx.Done()}
+
+	a.m.Lock()
+	a.subscribers[s] = struct{}{}
+	current := a.snapshot()
+	a.m.Unlock()
+
+	defer func() {
+		a.m.Lock()
+		delete(a.subscribers, s)
+		a.m.Unlock()
+	}()
+
+	if err := ss.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	if err := send(current, true); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case b := <-s.ch:
+			if err := send(b.clone(), false); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// snapshot captures the current orderbooks and tickers at the current block height. Requires a.m to be held.
+func (a *API) snapshot() *block {
+	b := &block{
+		height:     a.blockHeight,
+		orderbooks: make(map[string]*pb.GetOrderbookResponse),
+		trades:     make(map[string][]*pb.Trade),
+	}
+	for name, m := range a.markets {
+		b.orderbooks[name] = m.orderbook(0)
+	}
+	b.tickers, _ = a.tickers("")
+	return b
+}
+
+// clone copies the block, so that each subscriber can modify its own copy
+func (b *block) clone() *block {
+	cloned := &block{
+		height:     b.height,
+		orderbooks: make(map[string]*pb.GetOrderbookResponse),
+		tickers:    proto.Clone(b.tickers).(*pb.GetTickersResponse),
+		trades:     make(map[string][]*pb.Trade),
+		statuses:   b.statuses,
+	}
+	for name, orderbook := range b.orderbooks {
+		cloned.orderbooks[name] = proto.Clone(orderbook).(*pb.GetOrderbookResponse)
+	}
+	for name, trades := range b.trades {
+		for _, t := range trades {
+			cloned.trades[name] = append(cloned.trades[name], proto.Clone(t).(*pb.Trade))
+		}
+	}
+	return cloned
+}
+
+// levelChanges returns the levels of next whose size differs from prev, and levels of prev missing from next with a size of 0
+func levelChanges(prev, next []*pb.OrderbookItem) []*pb.OrderbookItem {
+	prevSizes := make(map[float64]float64)
+	for _, level := range prev {
+		prevSizes[level.Price] = level.Size
+	}
+
+	var changes []*pb.OrderbookItem
+	for _, level := range next {
+		if size, ok := prevSizes[level.Price]; !ok || size != level.Size {
+			changes = append(changes, &pb.OrderbookItem{Price: level.Price, Size: level.Size})
+		}
+		delete(prevSizes, level.Price)
+	}
+	for price := range prevSizes {
+		changes = append(changes, &pb.OrderbookItem{Price: price, Size: 0})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Price < changes[j].Price })
+	return changes
+}
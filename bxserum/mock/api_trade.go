@@ -0,0 +1,390 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	errInvalidPayer  = status.Error(codes.InvalidArgument, "invalid payer specified: owner cannot match payer unless selling SOL")
+	errOrderTooSmall = simulationError("Error processing Instruction 2: invalid program argument")
+)
+
+// pendingTx is a transaction built by the API that has not been submitted yet. simulate decides whether it would
+// succeed on chain, and apply changes the API state accordingly.
+type pendingTx struct {
+	simulate func() error
+	apply    func()
+}
+
+func simulationError(reason string) error {
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("Transaction simulation failed: %v", reason))
+}
+
+func (a *API) PostOrder(_ context.Context, request *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
+	if err := validatePublicKey(request.OwnerAddress); err != nil {
+		return nil, err
+	}
+	if err := validatePublicKey(request.PayerAddress); err != nil {
+		return nil, err
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+	if request.OwnerAddress == request.PayerAddress && !(request.Side == pb.Side_S_ASK && m.baseToken() == "SOL") {
+		return nil, errInvalidPayer
+	}
+
+	openOrdersAddress := request.OpenOrdersAddress
+	if openOrdersAddress == "" {
+		openOrdersAddress = a.openOrdersAddress(request.OwnerAddress, m.info.Market)
+	}
+	o := &pb.Order{
+		Market:           m.info.Market,
+		Side:             request.Side,
+		Types:            request.Type,
+		Price:            request.Price,
+		RemainingSize:    request.Amount,
+		ClientOrderID:    fmt.Sprint(request.ClientOrderID),
+		OpenOrderAccount: openOrdersAddress,
+	}
+
+	tx, err := a.transaction(request.OwnerAddress, pendingTx{
+		simulate: func() error {
+			if request.Amount < m.minOrderSize {
+				return errOrderTooSmall
+			}
+			return nil
+		},
+		apply: func() {
+			a.orderID++
+			o.OrderID = fmt.Sprint(a.orderID)
+			o.CreatedAt = timestamppb.Now()
+			a.orders = append(a.orders, &order{Order: o, owner: request.OwnerAddress, status: pb.OrderStatus_OS_OPEN})
+			a.blockStatuses = append(a.blockStatuses, newOrderStatus(request.OwnerAddress, o, pb.OrderStatus_OS_OPEN, 0))
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostOrderResponse{Transaction: tx, OpenOrdersAddress: openOrdersAddress}, nil
+}
+
+func (a *API) PostCancelOrder(_ context.Context, request *pb.PostCancelOrderRequest) (*pb.PostCancelOrderResponse, error) {
+	tx, err := a.cancelTransaction(request.OwnerAddress, request.MarketAddress, func(o *order) bool {
+		return o.OrderID == request.OrderID
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostCancelOrderResponse{Transaction: tx}, nil
+}
+
+func (a *API) PostCancelByClientOrderID(_ context.Context, request *pb.PostCancelByClientOrderIDRequest) (*pb.PostCancelOrderResponse, error) {
+	clientOrderID := fmt.Sprint(request.ClientOrderID)
+	tx, err := a.cancelTransaction(request.OwnerAddress, request.MarketAddress, func(o *order) bool {
+		return o.ClientOrderID == clientOrderID
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostCancelOrderResponse{Transaction: tx}, nil
+}
+
+// PostCancelAll builds one transaction per open orders account, or a single transaction for all of them if none are given
+func (a *API) PostCancelAll(_ context.Context, request *pb.PostCancelAllRequest) (*pb.PostCancelAllResponse, error) {
+	openOrdersAddresses := request.OpenOrdersAddresses
+	if len(openOrdersAddresses) == 0 {
+		openOrdersAddresses = []string{""}
+	}
+
+	var txs []string
+	for _, openOrdersAddress := range openOrdersAddresses {
+		openOrdersAddress := openOrdersAddress
+		tx, err := a.cancelTransaction(request.OwnerAddress, request.Market, func(o *order) bool {
+			return openOrdersAddress == "" || o.OpenOrderAccount == openOrdersAddress
+		})
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return &pb.PostCancelAllResponse{Transactions: txs}, nil
+}
+
+func (a *API) PostSettle(_ context.Context, request *pb.PostSettleRequest) (*pb.PostSettleResponse, error) {
+	if err := validatePublicKey(request.OwnerAddress); err != nil {
+		return nil, err
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := a.transaction(request.OwnerAddress, pendingTx{
+		simulate: func() error { return nil },
+		apply: func() {
+			delete(a.account(request.OwnerAddress).unsettled, m.info.Market)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostSettleResponse{Transaction: tx}, nil
+}
+
+// PostSubmit verifies the transaction signatures, simulates it unless preflight is skipped, and applies it if it was
+// built by the API. Transactions that fail simulation with skipPreFlight set are accepted but have no effect.
+func (a *API) PostSubmit(_ context.Context, request *pb.PostSubmitRequest) (*pb.PostSubmitResponse, error) {
+	txBytes, err := solanarpc.DataBytesOrJSONFromBase64(request.Transaction)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	tx, err := (&solanarpc.TransactionWithMeta{Transaction: txBytes}).GetTransaction()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(tx.Signatures) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "transaction is not signed")
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	key := memo(tx)
+	if p, ok := a.pending[key]; ok {
+		err := p.simulate()
+		if err != nil && !request.SkipPreFlight {
+			return nil, err
+		}
+		if err == nil {
+			p.apply()
+		}
+		delete(a.pending, key)
+	}
+
+	a.submissions = append(a.submissions, request.Transaction)
+	return &pb.PostSubmitResponse{Signature: tx.Signatures[0].String()}, nil
+}
+
+func (a *API) GetOrders(_ context.Context, request *pb.GetOrdersRequest) (*pb.GetOrdersResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []*pb.Order
+	for _, o := range a.orders {
+		if o.Market != m.info.Market || o.owner != request.Address {
+			continue
+		}
+		if request.Status != pb.OrderStatus_OS_UNKNOWN && request.Status != o.status {
+			continue
+		}
+		if request.Side != pb.Side_S_UNKNOWN && request.Side != o.Side {
+			continue
+		}
+		if !hasTypes(o.Types, request.Types) {
+			continue
+		}
+		if request.From != nil && o.CreatedAt.AsTime().Before(request.From.AsTime()) {
+			continue
+		}
+		orders = append(orders, proto.Clone(o.Order).(*pb.Order))
+	}
+
+	if request.Direction == pb.Direction_D_DESCENDING {
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+	return &pb.GetOrdersResponse{Orders: firstN(orders, request.Limit)}, nil
+}
+
+func (a *API) GetOpenOrders(_ context.Context, request *pb.GetOpenOrdersRequest) (*pb.GetOpenOrdersResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	m, err := a.market(request.Market)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePublicKey(request.Address); err != nil {
+		return nil, err
+	}
+
+	var orders []*pb.Order
+	for _, o := range a.orders {
+		if o.Market == m.info.Market && o.owner == request.Address && isOpen(o.status) {
+			orders = append(orders, proto.Clone(o.Order).(*pb.Order))
+		}
+	}
+	return &pb.GetOpenOrdersResponse{Orders: firstN(orders, request.Limit)}, nil
+}
+
+func (a *API) GetOrderByID(_ context.Context, request *pb.GetOrderByIDRequest) (*pb.GetOrderByIDResponse, error) {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	marketName := ""
+	if request.Market != "" {
+		m, err := a.market(request.Market)
+		if err != nil {
+			return nil, err
+		}
+		marketName = m.info.Market
+	}
+
+	for _, o := range a.orders {
+		if o.OrderID == request.OrderID && (marketName == "" || o.Market == marketName) {
+			return &pb.GetOrderByIDResponse{Order: proto.Clone(o.Order).(*pb.Order)}, nil
+		}
+	}
+	return nil, ErrOrderNotFound
+}
+
+// cancelTransaction builds a transaction that cancels the owner's open orders on the market that match. Simulation
+// fails if no order matches anymore.
+func (a *API) cancelTransaction(owner, marketName string, match func(o *order) bool) (string, error) {
+	if err := validatePublicKey(owner); err != nil {
+		return "", err
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	m, err := a.market(marketName)
+	if err != nil {
+		return "", err
+	}
+
+	matching := func() []*order {
+		var orders []*order
+		for _, o := range a.orders {
+			if o.owner == owner && o.Market == m.info.Market && isOpen(o.status) && match(o) {
+				orders = append(orders, o)
+			}
+		}
+		return orders
+	}
+
+	return a.transaction(owner, pendingTx{
+		simulate: func() error {
+			if len(matching()) == 0 {
+				return simulationError("no matching open orders")
+			}
+			return nil
+		},
+		apply: func() {
+			for _, o := range matching() {
+				a.cancel(o)
+			}
+		},
+	})
+}
+
+// transaction builds an unsigned transaction that requires the owner's signature and tracks it until it's submitted.
+// Requires a.m to be held for writing.
+func (a *API) transaction(owner string, p pendingTx) (string, error) {
+	ownerKey, err := solana.PublicKeyFromBase58(owner)
+	if err != nil {
+		return "", errInvalidPublicKey
+	}
+
+	a.txID++
+	key := fmt.Sprintf("mock-%v", a.txID)
+	tx, err := solana.NewTransaction([]solana.Instruction{
+		solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{solana.Meta(ownerKey).SIGNER()}, []byte(key)),
+	}, solana.Hash{}, solana.TransactionPayer(ownerKey))
+	if err != nil {
+		return "", err
+	}
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+
+	txBase64, err := tx.ToBase64()
+	if err != nil {
+		return "", err
+	}
+	a.pending[key] = p
+	return txBase64, nil
+}
+
+// memo returns the data of the transaction's memo instruction, which identifies transactions built by the API
+func memo(tx *solana.Transaction) string {
+	for _, instruction := range tx.Message.Instructions {
+		programID, err := tx.Message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		if err == nil && programID.Equals(solana.MemoProgramID) {
+			return string(instruction.Data)
+		}
+	}
+	return ""
+}
+
+func (m *market) baseToken() string {
+	return strings.SplitN(m.info.Market, "/", 2)[0]
+}
+
+// hasTypes reports whether the order has every one of the requested types
+func hasTypes(orderTypes, requested []pb.OrderType) bool {
+	for _, r := range requested {
+		found := false
+		for _, t := range orderTypes {
+			if t == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// orderStatus is an order status change, published to the owner's order status streams with the next block
+type orderStatus struct {
+	owner string
+	info  *pb.GetOrderStatusResponse
+}
+
+func newOrderStatus(owner string, o *pb.Order, s pb.OrderStatus, quantityReleased float64) orderStatus {
+	clientOrderID, _ := strconv.ParseUint(o.ClientOrderID, 10, 64)
+	return orderStatus{
+		owner: owner,
+		info: &pb.GetOrderStatusResponse{
+			Market:           o.Market,
+			OpenOrderAddress: o.OpenOrderAccount,
+			OrderID:          o.OrderID,
+			ClientOrderID:    clientOrderID,
+			QuantityReleased: float32(quantityReleased),
+			Price:            float32(o.Price),
+			Side:             o.Side,
+			OrderStatus:      s,
+		},
+	}
+}
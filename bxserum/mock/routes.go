@@ -0,0 +1,173 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// route maps a unary RPC to its grpc-gateway HTTP binding and websocket method name
+type route struct {
+	name       string
+	httpMethod string
+	// path is matched exactly, or as a prefix followed by the value of pathParam when set
+	path      string
+	pathParam string
+	call      func(ctx context.Context, decode func(proto.Message) error) (proto.Message, error)
+}
+
+func newRoute[Req any, PReq interface {
+	*Req
+	proto.Message
+}, Resp proto.Message](name, httpMethod, path, pathParam string, fn func(context.Context, PReq) (Resp, error)) route {
+	return route{
+		name:       name,
+		httpMethod: httpMethod,
+		path:       path,
+		pathParam:  pathParam,
+		call: func(ctx context.Context, decode func(proto.Message) error) (proto.Message, error) {
+			request := PReq(new(Req))
+			if err := decode(request); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			return fn(ctx, request)
+		},
+	}
+}
+
+// routes mirrors the google.api.http options declared in api.proto
+func routes(api pb.ApiServer) []route {
+	return []route{
+		newRoute("GetMarkets", http.MethodGet, "/api/v1/market/markets", "", api.GetMarkets),
+		newRoute("GetTickers", http.MethodGet, "/api/v1/market/tickers/", "market", api.GetTickers),
+		newRoute("GetKline", http.MethodGet, "/api/v1/market/kline/", "market", api.GetKline),
+		newRoute("GetOrderbook", http.MethodGet, "/api/v1/market/orderbooks/", "market", api.GetOrderbook),
+		newRoute("GetTrades", http.MethodGet, "/api/v1/market/trades/", "market", api.GetTrades),
+		newRoute("GetServerTime", http.MethodGet, "/api/v1/system/time", "", api.GetServerTime),
+		newRoute("GetAccountBalance", http.MethodGet, "/api/v1/account/balance", "", api.GetAccountBalance),
+		newRoute("PostOrder", http.MethodPost, "/api/v1/trade/place", "", api.PostOrder),
+		newRoute("PostSubmit", http.MethodPost, "/api/v1/trade/submit", "", api.PostSubmit),
+		newRoute("PostCancelOrder", http.MethodPost, "/api/v1/trade/cancel", "", api.PostCancelOrder),
+		newRoute("PostCancelByClientOrderID", http.MethodPost, "/api/v1/trade/cancelbyid", "", api.PostCancelByClientOrderID),
+		newRoute("PostCancelAll", http.MethodPost, "/api/v1/trade/cancelall", "", api.PostCancelAll),
+		newRoute("PostSettle", http.MethodPost, "/api/v1/trade/settle", "", api.PostSettle),
+		newRoute("GetOrders", http.MethodGet, "/api/v1/trade/orders/", "market", api.GetOrders),
+		newRoute("GetOpenOrders", http.MethodGet, "/api/v1/trade/openorders/", "market", api.GetOpenOrders),
+		newRoute("GetOrderByID", http.MethodGet, "/api/v1/trade/orderbyid/", "orderID", api.GetOrderByID),
+		newRoute("GetUnsettled", http.MethodGet, "/api/v1/trade/unsettled/", "market", api.GetUnsettled),
+	}
+}
+
+// stream starts a streaming RPC handler on any grpc.ServerStream, so websocket subscriptions reuse the GRPC handlers
+type stream func(decode func(proto.Message) error, ss grpc.ServerStream) error
+
+func newStream[Resp proto.Message, Req any, PReq interface {
+	*Req
+	proto.Message
+}, S grpc.ServerStream](fn func(PReq, S) error) stream {
+	if _, ok := interface{}(&wsServerStream[Resp]{}).(S); !ok {
+		panic(fmt.Sprintf("stream server %T does not send %T", *new(S), *new(Resp)))
+	}
+
+	return func(decode func(proto.Message) error, ss grpc.ServerStream) error {
+		request := PReq(new(Req))
+		if err := decode(request); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return fn(request, interface{}(&wsServerStream[Resp]{ss}).(S))
+	}
+}
+
+func streams(api pb.ApiServer) map[string]stream {
+	return map[string]stream{
+		"GetOrderbooksStream":  newStream[*pb.GetOrderbooksStreamResponse](api.GetOrderbooksStream),
+		"GetTickersStream":     newStream[*pb.GetTickersStreamResponse](api.GetTickersStream),
+		"GetMarketDepthStream": newStream[*pb.GetMarketDepthStreamResponse](api.GetMarketDepthStream),
+		"GetTradesStream":      newStream[*pb.GetTradesStreamResponse](api.GetTradesStream),
+		"GetOrderStatusStream": newStream[*pb.GetOrderStatusStreamResponse](api.GetOrderStatusStream),
+	}
+}
+
+// wsServerStream adds the typed Send method that the generated pb.Api_*Server interfaces expect
+type wsServerStream[Resp proto.Message] struct {
+	grpc.ServerStream
+}
+
+func (s *wsServerStream[Resp]) Send(m Resp) error {
+	return s.SendMsg(m)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, route := range s.routes {
+		if route.httpMethod != r.Method {
+			continue
+		}
+
+		var decode func(proto.Message) error
+		switch {
+		case route.httpMethod == http.MethodPost && r.URL.Path == route.path:
+			decode = func(m proto.Message) error {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					return err
+				}
+				return protojson.Unmarshal(body, m)
+			}
+		case route.pathParam == "" && r.URL.Path == route.path:
+			decode = queryDecoder(r, "", "")
+		case route.pathParam != "" && strings.HasPrefix(r.URL.Path, route.path):
+			decode = queryDecoder(r, route.pathParam, strings.TrimPrefix(r.URL.Path, route.path))
+		default:
+			continue
+		}
+
+		response, err := route.call(propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header)), decode)
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		b, err := protojson.Marshal(response)
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+		return
+	}
+	writeHTTPError(w, status.Errorf(codes.NotFound, "no route for %v %v", r.Method, r.URL.Path))
+}
+
+// queryDecoder populates a request from query and path parameters the same way grpc-gateway does
+func queryDecoder(r *http.Request, pathParam, pathValue string) func(proto.Message) error {
+	return func(m proto.Message) error {
+		if pathParam != "" && pathValue != "" {
+			if err := runtime.PopulateFieldFromPath(m, pathParam, pathValue); err != nil {
+				return err
+			}
+		}
+		return runtime.PopulateQueryParameters(m, r.URL.Query(), utilities.NewDoubleArray(nil))
+	}
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	b, _ := json.Marshal(connections.HTTPError{Code: int(st.Code()), Message: st.Message()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	_, _ = w.Write(b)
+}
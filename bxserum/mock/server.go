@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+)
+
+const wsPath = "/ws"
+
+// Server serves a pb.ApiServer on local ports over GRPC, the grpc-gateway HTTP routes and the JSON-RPC websocket
+// protocol, so every provider can be pointed at the same in-process implementation
+type Server struct {
+	GRPCEndpoint string
+	HTTPEndpoint string
+	WSEndpoint   string
+
+	api        pb.ApiServer
+	routes     []route
+	streams    map[string]stream
+	grpcServer *grpc.Server
+	httpServer *http.Server
+
+	wsM     sync.Mutex
+	wsConns map[*websocket.Conn]struct{}
+}
+
+// NewServer starts serving api on random local ports. Use the endpoint fields to configure the providers.
+func NewServer(api pb.ApiServer) (*Server, error) {
+	s := &Server{
+		api:     api,
+		routes:  routes(api),
+		streams: streams(api),
+		wsConns: make(map[*websocket.Conn]struct{}),
+	}
+
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = grpcListener.Close()
+		return nil, err
+	}
+
+	s.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(grpcTraceInterceptor), grpc.StreamInterceptor(grpcTraceStreamInterceptor))
+	pb.RegisterApiServer(s.grpcServer, api)
+	go func() {
+		_ = s.grpcServer.Serve(grpcListener)
+	}()
+	s.GRPCEndpoint = grpcListener.Addr().String()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(wsPath, s.serveWS)
+	mux.HandleFunc("/", s.serveHTTP)
+	s.httpServer = &http.Server{Handler: mux}
+	go func() {
+		_ = s.httpServer.Serve(httpListener)
+	}()
+	s.HTTPEndpoint = fmt.Sprintf("http://%v", httpListener.Addr())
+	s.WSEndpoint = fmt.Sprintf("ws://%v%v", httpListener.Addr(), wsPath)
+
+	return s, nil
+}
+
+// DropWSConnections abruptly closes every open websocket connection, e.g. to exercise client reconnection
+func (s *Server) DropWSConnections() {
+	s.wsM.Lock()
+	defer s.wsM.Unlock()
+	for conn := range s.wsConns {
+		_ = conn.Close()
+	}
+}
+
+// Close stops all listeners and drops any open connections
+func (s *Server) Close() error {
+	s.grpcServer.Stop()
+	s.DropWSConnections()
+
+	err := s.httpServer.Shutdown(context.Background())
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
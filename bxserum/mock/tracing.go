@@ -0,0 +1,54 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// propagator extracts the W3C trace context sent by clients with RPCOpts.Tracing, so API implementations can find the
+// client's span with trace.SpanContextFromContext
+var propagator = propagation.TraceContext{}
+
+func grpcTraceInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(grpcIncomingTrace(ctx), req)
+}
+
+func grpcTraceStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, tracedServerStream{ServerStream: ss, ctx: grpcIncomingTrace(ss.Context())})
+}
+
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func grpcIncomingTrace(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	carrier := propagation.MapCarrier{}
+	for key, values := range md {
+		if len(values) > 0 {
+			carrier[key] = values[0]
+		}
+	}
+	return propagator.Extract(ctx, carrier)
+}
+
+// wsIncomingTrace extracts the trace context from the meta field of a JSON-RPC request
+func wsIncomingTrace(ctx context.Context, meta *json.RawMessage) context.Context {
+	if meta == nil {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{}
+	if err := json.Unmarshal(*meta, &carrier); err != nil {
+		return ctx
+	}
+	return propagator.Extract(ctx, carrier)
+}
@@ -0,0 +1,275 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	subscribeMethod   = "subscribe"
+	unsubscribeMethod = "unsubscribe"
+)
+
+// wsSession is a single websocket client connection
+type wsSession struct {
+	server *Server
+	conn   *websocket.Conn
+	ctx    context.Context
+
+	writeM sync.Mutex
+
+	subscriptionM  sync.Mutex
+	subscriptionID int
+	subscriptions  map[string]context.CancelFunc
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	s.wsM.Lock()
+	s.wsConns[conn] = struct{}{}
+	s.wsM.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		s.wsM.Lock()
+		delete(s.wsConns, conn)
+		s.wsM.Unlock()
+		_ = conn.Close()
+	}()
+
+	session := &wsSession{
+		server:        s,
+		conn:          conn,
+		ctx:           ctx,
+		subscriptions: make(map[string]context.CancelFunc),
+	}
+	session.readLoop()
+}
+
+func (ws *wsSession) readLoop() {
+	routes := make(map[string]route)
+	for _, route := range ws.server.routes {
+		routes[route.name] = route
+	}
+
+	for {
+		_, msg, err := ws.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request jsonrpc2.Request
+		if err := json.Unmarshal(msg, &request); err != nil {
+			return
+		}
+		var params []byte
+		if request.Params != nil {
+			params = *request.Params
+		}
+
+		ctx := wsIncomingTrace(ws.ctx, request.Meta)
+		switch request.Method {
+		case subscribeMethod:
+			// streams may take a while to validate their request: don't hold up other requests in the meantime
+			go ws.subscribe(ctx, request.ID, params)
+		case unsubscribeMethod:
+			ws.respond(request.ID, true, ws.unsubscribe(params))
+		default:
+			route, ok := routes[request.Method]
+			if !ok {
+				ws.respond(request.ID, nil, status.Errorf(codes.Unimplemented, "unknown method %v", request.Method))
+				continue
+			}
+			response, err := route.call(ctx, func(m proto.Message) error {
+				return protojson.Unmarshal(params, m)
+			})
+			if err != nil {
+				ws.respond(request.ID, nil, err)
+				continue
+			}
+			b, err := protojson.Marshal(response)
+			ws.respond(request.ID, json.RawMessage(b), err)
+		}
+	}
+}
+
+func (ws *wsSession) subscribe(ctx context.Context, requestID jsonrpc2.ID, params []byte) {
+	var sp connections.SubscribeParams
+	if err := json.Unmarshal(params, &sp); err != nil {
+		ws.respond(requestID, nil, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	stream, ok := ws.server.streams[sp.StreamName]
+	if !ok {
+		ws.respond(requestID, nil, status.Errorf(codes.Unimplemented, "unknown stream %v", sp.StreamName))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	ws.subscriptionM.Lock()
+	ws.subscriptionID++
+	subscriptionID := fmt.Sprintf("mock-%v", ws.subscriptionID)
+	ws.subscriptions[subscriptionID] = cancel
+	ws.subscriptionM.Unlock()
+	defer ws.cancelSubscription(subscriptionID)
+
+	ss := &wsSubscription{
+		session:        ws,
+		ctx:            ctx,
+		subscriptionID: subscriptionID,
+		accepted:       make(chan struct{}),
+		ready:          make(chan struct{}),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- stream(func(m proto.Message) error {
+			return protojson.Unmarshal(sp.StreamOpts, m)
+		}, ss)
+	}()
+
+	// the subscription ID must be sent before any updates, but errors found while validating the request should be
+	// returned instead: the stream is accepted as soon as it sends headers or its first message
+	select {
+	case <-ss.accepted:
+		ws.respond(requestID, subscriptionID, nil)
+		close(ss.ready)
+		<-errCh
+	case err := <-errCh:
+		if err == nil {
+			err = status.Error(codes.Unavailable, "stream ended before sending any updates")
+		}
+		ws.respond(requestID, nil, err)
+	}
+}
+
+func (ws *wsSession) unsubscribe(params []byte) error {
+	var up connections.UnsubscribeParams
+	if err := json.Unmarshal(params, &up); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	ws.cancelSubscription(up.SubscriptionID)
+	return nil
+}
+
+func (ws *wsSession) cancelSubscription(subscriptionID string) {
+	ws.subscriptionM.Lock()
+	defer ws.subscriptionM.Unlock()
+	if cancel, ok := ws.subscriptions[subscriptionID]; ok {
+		cancel()
+		delete(ws.subscriptions, subscriptionID)
+	}
+}
+
+// respond writes a JSON-RPC response. Errors carry the status message as their data, like the Serum API does.
+func (ws *wsSession) respond(requestID jsonrpc2.ID, result interface{}, err error) {
+	response := jsonrpc2.Response{ID: requestID}
+	if err != nil {
+		st := status.Convert(err)
+		rpcErr := &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: st.Message()}
+		rpcErr.SetError(st.Message())
+		response.Error = rpcErr
+	} else {
+		b, err := json.Marshal(result)
+		if err != nil {
+			ws.respond(requestID, nil, err)
+			return
+		}
+		raw := json.RawMessage(b)
+		response.Result = &raw
+	}
+	ws.write(response)
+}
+
+func (ws *wsSession) write(v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	ws.writeM.Lock()
+	defer ws.writeM.Unlock()
+	_ = ws.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+// wsSubscription implements grpc.ServerStream on top of a websocket subscription
+type wsSubscription struct {
+	session        *wsSession
+	ctx            context.Context
+	subscriptionID string
+
+	acceptOnce sync.Once
+	// accepted is closed when the stream sends headers or its first message
+	accepted chan struct{}
+	// ready is closed once the subscription ID was sent to the client
+	ready chan struct{}
+}
+
+func (s *wsSubscription) accept() {
+	s.acceptOnce.Do(func() {
+		close(s.accepted)
+	})
+}
+
+func (s *wsSubscription) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *wsSubscription) SendHeader(metadata.MD) error {
+	s.accept()
+	return nil
+}
+
+func (s *wsSubscription) SetTrailer(metadata.MD) {}
+
+func (s *wsSubscription) Context() context.Context {
+	return s.ctx
+}
+
+func (s *wsSubscription) SendMsg(m interface{}) error {
+	s.accept()
+	select {
+	case <-s.ready:
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+
+	message, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cannot send %T: not a proto message", m)
+	}
+	b, err := protojson.Marshal(message)
+	if err != nil {
+		return err
+	}
+	params, err := json.Marshal(connections.FeedUpdate{SubscriptionID: s.subscriptionID, Result: b})
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(params)
+	s.session.write(jsonrpc2.Request{Method: subscribeMethod, Params: &raw, Notif: true})
+	return s.ctx.Err()
+}
+
+func (s *wsSubscription) RecvMsg(interface{}) error {
+	return io.EOF
+}
@@ -0,0 +1,292 @@
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+var (
+	ErrInsufficientLiquidity = errors.New("not enough liquidity in the book to fill the requested size")
+	ErrInvalidSize           = errors.New("size must be positive")
+)
+
+// Level is the total size resting at a price
+type Level struct {
+	Price float64
+	Size  float64
+}
+
+// LevelChange describes a level that an update added, resized or removed. Size is 0 for removed levels and PrevSize is
+// 0 for added ones.
+type LevelChange struct {
+	Market      string
+	BlockHeight int64
+	Side        pb.Side
+	Price       float64
+	Size        float64
+	PrevSize    float64
+}
+
+// Book is the local copy of a market's orderbook. It is safe to query while a Manager applies updates to it.
+type Book struct {
+	m             sync.RWMutex
+	market        string
+	marketAddress string
+	blockHeight   int64
+	bids          side
+	asks          side
+
+	// outOfSync is set when a market depth tick doesn't follow the previous one, until the book is resynced
+	outOfSync bool
+	// latestTick is the height of the newest market depth tick received, including the ones rejected as gaps
+	latestTick int64
+	// resynced is set when the book was replaced by a snapshot that doesn't follow the last tick, so the next newer
+	// tick is accepted without checking its continuity
+	resynced bool
+}
+
+func newBook(market string) *Book {
+	return &Book{market: market, bids: side{descending: true}}
+}
+
+// Market returns the market name the book is tracked under
+func (b *Book) Market() string {
+	return b.market
+}
+
+// BlockHeight returns the block height of the last update applied to the book
+func (b *Book) BlockHeight() int64 {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return b.blockHeight
+}
+
+// BestBid returns the highest bid, false if there are no bids
+func (b *Book) BestBid() (Level, bool) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return b.bids.best()
+}
+
+// BestAsk returns the lowest ask, false if there are no asks
+func (b *Book) BestAsk() (Level, bool) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return b.asks.best()
+}
+
+// Bids returns up to limit bids, best first. Use 0 for all levels.
+func (b *Book) Bids(limit int) []Level {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return b.bids.top(limit)
+}
+
+// Asks returns up to limit asks, best first. Use 0 for all levels.
+func (b *Book) Asks(limit int) []Level {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return b.asks.top(limit)
+}
+
+// Depth returns the total size resting on the given side of the book at price or better
+func (b *Book) Depth(s pb.Side, price float64) float64 {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	levels := b.side(s)
+	total := 0.0
+	for _, level := range levels.levels {
+		if levels.better(price, level.Price) {
+			break
+		}
+		total += level.Size
+	}
+	return total
+}
+
+// VWAP returns the volume weighted average price an order of size on the given side would fill at, walking the
+// opposite side of the book. It returns ErrInsufficientLiquidity if the book can't fill the whole size, and
+// ErrInvalidSize if size isn't positive.
+func (b *Book) VWAP(s pb.Side, size float64) (float64, error) {
+	if !(size > 0) {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidSize, size)
+	}
+
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	levels := b.asks
+	if s == pb.Side_S_ASK {
+		levels = b.bids
+	}
+
+	remaining, notional := size, 0.0
+	for _, level := range levels.levels {
+		fill := level.Size
+		if fill > remaining {
+			fill = remaining
+		}
+		notional += fill * level.Price
+		remaining -= fill
+		if remaining <= 0 {
+			return notional / size, nil
+		}
+	}
+	return 0, ErrInsufficientLiquidity
+}
+
+// Snapshot returns the book in the format of GetOrderbook
+func (b *Book) Snapshot() *pb.GetOrderbookResponse {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	return &pb.GetOrderbookResponse{
+		Market:        b.market,
+		MarketAddress: b.marketAddress,
+		Bids:          b.bids.items(),
+		Asks:          b.asks.items(),
+	}
+}
+
+func (b *Book) side(s pb.Side) *side {
+	if s == pb.Side_S_ASK {
+		return &b.asks
+	}
+	return &b.bids
+}
+
+// replace swaps the levels of the book for the given ones and returns the differences. The lock must be held.
+func (b *Book) replace(blockHeight int64, bids, asks []*pb.OrderbookItem) []LevelChange {
+	newBids, newAsks := side{descending: true}, side{}
+	for _, item := range bids {
+		newBids.set(item.Price, item.Size)
+	}
+	for _, item := range asks {
+		newAsks.set(item.Price, item.Size)
+	}
+
+	changes := b.diff(blockHeight, pb.Side_S_BID, b.bids, newBids)
+	changes = append(changes, b.diff(blockHeight, pb.Side_S_ASK, b.asks, newAsks)...)
+	b.bids, b.asks = newBids, newAsks
+	return changes
+}
+
+// update sets the sizes of the given levels, removing levels with a size of 0. The lock must be held.
+func (b *Book) update(blockHeight int64, s pb.Side, items []*pb.OrderbookItem) []LevelChange {
+	var changes []LevelChange
+	levels := b.side(s)
+	for _, item := range items {
+		prevSize := levels.set(item.Price, item.Size)
+		if prevSize != item.Size {
+			changes = append(changes, b.change(blockHeight, s, item.Price, item.Size, prevSize))
+		}
+	}
+	return changes
+}
+
+func (b *Book) diff(blockHeight int64, s pb.Side, old, new side) []LevelChange {
+	var changes []LevelChange
+	for _, level := range new.levels {
+		prevSize := old.size(level.Price)
+		if prevSize != level.Size {
+			changes = append(changes, b.change(blockHeight, s, level.Price, level.Size, prevSize))
+		}
+	}
+	for _, level := range old.levels {
+		if new.size(level.Price) == 0 {
+			changes = append(changes, b.change(blockHeight, s, level.Price, 0, level.Size))
+		}
+	}
+	return changes
+}
+
+func (b *Book) change(blockHeight int64, s pb.Side, price, size, prevSize float64) LevelChange {
+	return LevelChange{Market: b.market, BlockHeight: blockHeight, Side: s, Price: price, Size: size, PrevSize: prevSize}
+}
+
+// side holds the levels of one side of the book, best first
+type side struct {
+	levels []Level
+	// descending orders the levels from the highest price, as bids are
+	descending bool
+}
+
+// better reports whether price a is better than price b on this side
+func (s *side) better(a, b float64) bool {
+	if s.descending {
+		return a > b
+	}
+	return a < b
+}
+
+func (s *side) index(price float64) (int, bool) {
+	i := sort.Search(len(s.levels), func(i int) bool {
+		return !s.better(s.levels[i].Price, price)
+	})
+	return i, i < len(s.levels) && s.levels[i].Price == price
+}
+
+func (s *side) size(price float64) float64 {
+	i, ok := s.index(price)
+	if !ok {
+		return 0
+	}
+	return s.levels[i].Size
+}
+
+// set updates the size at price, removing the level if size is 0, and returns the previous size
+func (s *side) set(price, size float64) float64 {
+	i, ok := s.index(price)
+	switch {
+	case ok && size == 0:
+		prevSize := s.levels[i].Size
+		s.levels = append(s.levels[:i], s.levels[i+1:]...)
+		return prevSize
+	case ok:
+		prevSize := s.levels[i].Size
+		s.levels[i].Size = size
+		return prevSize
+	case size == 0:
+		return 0
+	default:
+		s.levels = append(s.levels, Level{})
+		copy(s.levels[i+1:], s.levels[i:])
+		s.levels[i] = Level{Price: price, Size: size}
+		return 0
+	}
+}
+
+func (s *side) best() (Level, bool) {
+	if len(s.levels) == 0 {
+		return Level{}, false
+	}
+	return s.levels[0], true
+}
+
+func (s *side) top(limit int) []Level {
+	n := len(s.levels)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	levels := make([]Level, n)
+	copy(levels, s.levels)
+	return levels
+}
+
+func (s *side) items() []*pb.OrderbookItem {
+	items := make([]*pb.OrderbookItem, 0, len(s.levels))
+	for _, level := range s.levels {
+		items = append(items, &pb.OrderbookItem{Price: level.Price, Size: level.Size})
+	}
+	return items
+}
@@ -0,0 +1,335 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+const streamBuffer = 100
+
+var (
+	ErrStaleUpdate = errors.New("update is not newer than the book")
+	// ErrStreamEnded is returned when a stream followed with a client that doesn't tell why its streams end is closed
+	ErrStreamEnded = errors.New("stream ended")
+)
+
+// GapError is returned when a market depth tick doesn't follow the last tick applied to the book, meaning updates were
+// missed. The book keeps rejecting ticks until it is resynced.
+type GapError struct {
+	Market string
+	// BlockHeight is the height of the last update applied to the book
+	BlockHeight int64
+	// PrevBlockHeight is the height the rejected tick follows
+	PrevBlockHeight int64
+}
+
+func (e *GapError) Error() string {
+	return fmt.Sprintf("market depth of %v skipped updates: book is at block %v but tick follows block %v", e.Market, e.BlockHeight, e.PrevBlockHeight)
+}
+
+// Opts configures a Manager
+type Opts struct {
+	// OnLevelChange is called with every level an update changes, after the update is applied
+	OnLevelChange func(LevelChange)
+	// OnGap is called when a market depth tick doesn't follow the last tick applied to the book
+	OnGap func(*GapError)
+}
+
+// orderbooksSubscriber and marketDepthSubscriber are implemented by the provider clients, whose subscriptions tell why
+// their streams ended
+type orderbooksSubscriber interface {
+	SubscribeOrderbooks(ctx context.Context, markets []string, limit uint32) (*provider.Subscription[*pb.GetOrderbooksStreamResponse], error)
+}
+
+type marketDepthSubscriber interface {
+	SubscribeMarketDepth(ctx context.Context) (*provider.Subscription[*pb.GetMarketDepthStreamResponse], error)
+}
+
+// Manager maintains local books from GetOrderbooksStream and GetMarketDepthStream updates
+type Manager struct {
+	opts Opts
+
+	m     sync.RWMutex
+	books map[string]*Book
+}
+
+func NewManager(opts Opts) *Manager {
+	return &Manager{opts: opts, books: make(map[string]*Book)}
+}
+
+// Book returns the book of the market, looked up by the name it is tracked under or by its address
+func (m *Manager) Book(market string) (*Book, bool) {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	if b, ok := m.books[market]; ok {
+		return b, true
+	}
+	for _, b := range m.books {
+		b.m.RLock()
+		address := b.marketAddress
+		b.m.RUnlock()
+		if address == market {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (m *Manager) book(market string) *Book {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	b, ok := m.books[market]
+	if !ok {
+		b = newBook(market)
+		m.books[market] = b
+	}
+	return b
+}
+
+// ApplyOrderbook replaces the book of the update's market and returns the levels that changed. As snapshots replace the
+// whole book, they may skip blocks, and only those that aren't newer than the book are rejected with ErrStaleUpdate.
+// Updates with a block height of 0, such as those of HTTP streams, have an unknown height: they always replace the book,
+// which stays at its block height.
+func (m *Manager) ApplyOrderbook(update *pb.GetOrderbooksStreamResponse) ([]LevelChange, error) {
+	orderbook := update.GetOrderbook()
+	if orderbook == nil {
+		return nil, errors.New("orderbook update has no orderbook")
+	}
+	b := m.book(orderbook.Market)
+
+	b.m.Lock()
+	height := update.BlockHeight
+	if height == 0 {
+		height = b.blockHeight
+	} else if height <= b.blockHeight {
+		b.m.Unlock()
+		return nil, fmt.Errorf("%w: %v is at block %v, update is for block %v", ErrStaleUpdate, orderbook.Market, b.blockHeight, update.BlockHeight)
+	}
+	b.marketAddress = orderbook.MarketAddress
+	changes := b.replace(height, orderbook.Bids, orderbook.Asks)
+	b.blockHeight = height
+	b.outOfSync, b.resynced = false, false
+	b.m.Unlock()
+
+	m.notify(changes)
+	return changes, nil
+}
+
+// ApplyMarketDepth applies a market depth tick to the book of market, which must be the market followed by the stream,
+// and returns the levels that changed. Ticks with a prevBlockHeight of 0 replace the book, and other ticks must follow
+// the last applied tick or a *GapError is returned; use Resync to recover. Ticks that aren't newer than the book are
+// rejected with ErrStaleUpdate.
+func (m *Manager) ApplyMarketDepth(market string, update *pb.GetMarketDepthStreamResponse) ([]LevelChange, error) {
+	tick := update.GetTick()
+	if tick == nil {
+		return nil, errors.New("market depth update has no tick")
+	}
+	b := m.book(market)
+
+	b.m.Lock()
+	if update.BlockHeight <= b.blockHeight {
+		b.m.Unlock()
+		return nil, fmt.Errorf("%w: %v is at block %v, tick is for block %v", ErrStaleUpdate, market, b.blockHeight, update.BlockHeight)
+	}
+
+	var changes []LevelChange
+	switch {
+	case tick.PrevBlockHeight == 0:
+		changes = b.replace(update.BlockHeight, tick.Bids, tick.Asks)
+	case b.resynced || (!b.outOfSync && b.blockHeight != 0 && tick.PrevBlockHeight == b.blockHeight):
+		// ticks carry the new size of every level they change, so they can be applied over a resynced book even if
+		// it already includes them
+		changes = b.update(update.BlockHeight, pb.Side_S_BID, tick.Bids)
+		changes = append(changes, b.update(update.BlockHeight, pb.Side_S_ASK, tick.Asks)...)
+	default:
+		gap := &GapError{Market: market, BlockHeight: b.blockHeight, PrevBlockHeight: tick.PrevBlockHeight}
+		reported := b.outOfSync
+		b.outOfSync = true
+		if update.BlockHeight > b.latestTick {
+			b.latestTick = update.BlockHeight
+		}
+		b.m.Unlock()
+
+		if !reported && m.opts.OnGap != nil {
+			m.opts.OnGap(gap)
+		}
+		return nil, gap
+	}
+	b.blockHeight = update.BlockHeight
+	b.outOfSync, b.resynced = false, false
+	b.m.Unlock()
+
+	m.notify(changes)
+	return changes, nil
+}
+
+// Resync replaces the book of market with its current orderbook, and returns the levels that changed. Clients that
+// stream orderbooks provide the snapshot through GetOrderbooksStream, and the book moves to its block height so that
+// ticks at or below it are rejected as stale. Snapshots without a block height, from GetOrderbook or from HTTP streams,
+// move the book to the newest tick received instead: the ticks that were missed are dropped, but a tick received after
+// the resync that is older than the snapshot is still applied over it, leaving the levels it changes stale until the
+// next ticks that change them.
+func (m *Manager) Resync(ctx context.Context, client provider.Client, market string) ([]LevelChange, error) {
+	b := m.book(market)
+
+	b.m.RLock()
+	request := market
+	if b.marketAddress != "" {
+		request = b.marketAddress
+	}
+	b.m.RUnlock()
+
+	orderbook, height, err := snapshot(ctx, client, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resync orderbook of %v: %w", market, err)
+	}
+
+	b.m.Lock()
+	b.marketAddress = orderbook.MarketAddress
+	if height == 0 {
+		// the snapshot was taken after the newest tick was received
+		height = b.blockHeight
+		if b.latestTick > height {
+			height = b.latestTick
+		}
+	}
+	changes := b.replace(height, orderbook.Bids, orderbook.Asks)
+	b.blockHeight = height
+	b.outOfSync, b.resynced = false, true
+	b.m.Unlock()
+
+	m.notify(changes)
+	return changes, nil
+}
+
+// snapshot returns the current orderbook of market, and its block height if it is known. Streaming clients send it as
+// the first update of GetOrderbooksStream, which has a block height except over HTTP; GetOrderbook responses don't.
+func snapshot(ctx context.Context, client provider.Client, market string) (*pb.GetOrderbookResponse, int64, error) {
+	streamer, ok := client.(provider.StreamingClient)
+	if !ok {
+		orderbook, err := client.GetOrderbook(ctx, market, 0)
+		return orderbook, 0, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates := make(chan *pb.GetOrderbooksStreamResponse, 1)
+	if err := streamer.GetOrderbooksStream(ctx, []string{market}, 0, updates); err != nil {
+		return nil, 0, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	case update, ok := <-updates:
+		if !ok || update.GetOrderbook() == nil {
+			return nil, 0, fmt.Errorf("%w: orderbooks of %v", ErrStreamEnded, market)
+		}
+		return update.Orderbook, update.BlockHeight, nil
+	}
+}
+
+// FollowOrderbooks subscribes to the orderbooks of markets and applies every update until ctx is done or the stream
+// ends, with the error it ended with or ErrStreamEnded. Stale updates are skipped.
+func (m *Manager) FollowOrderbooks(ctx context.Context, client provider.StreamingClient, markets []string, limit uint32) error {
+	var updates <-chan *pb.GetOrderbooksStreamResponse
+	streamErr := func() error { return nil }
+	if subscriber, ok := client.(orderbooksSubscriber); ok {
+		sub, err := subscriber.SubscribeOrderbooks(ctx, markets, limit)
+		if err != nil {
+			return err
+		}
+		updates, streamErr = sub.Updates(), sub.Err
+	} else {
+		ch := make(chan *pb.GetOrderbooksStreamResponse, streamBuffer)
+		if err := client.GetOrderbooksStream(ctx, markets, limit, ch); err != nil {
+			return err
+		}
+		updates = ch
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return streamEnded(ctx, "orderbooks", streamErr())
+			}
+			if _, err := m.ApplyOrderbook(update); err != nil && !errors.Is(err, ErrStaleUpdate) {
+				return err
+			}
+		}
+	}
+}
+
+// FollowMarketDepth subscribes to the market depth stream, which follows market, and applies every tick until ctx is
+// done or the stream ends, with the error it ended with or ErrStreamEnded. The book is resynced whenever ticks are
+// missed, and stale ticks are skipped.
+func (m *Manager) FollowMarketDepth(ctx context.Context, client provider.StreamingClient, market string) error {
+	var updates <-chan *pb.GetMarketDepthStreamResponse
+	streamErr := func() error { return nil }
+	if subscriber, ok := client.(marketDepthSubscriber); ok {
+		sub, err := subscriber.SubscribeMarketDepth(ctx)
+		if err != nil {
+			return err
+		}
+		updates, streamErr = sub.Updates(), sub.Err
+	} else {
+		ch := make(chan *pb.GetMarketDepthStreamResponse, streamBuffer)
+		if err := client.GetMarketDepthStream(ctx, ch); err != nil {
+			return err
+		}
+		updates = ch
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return streamEnded(ctx, "market depth", streamErr())
+			}
+			_, err := m.ApplyMarketDepth(market, update)
+			var gapErr *GapError
+			switch {
+			case err == nil, errors.Is(err, ErrStaleUpdate):
+			case errors.As(err, &gapErr):
+				if _, err := m.Resync(ctx, client, market); err != nil {
+					return err
+				}
+			default:
+				return err
+			}
+		}
+	}
+}
+
+// streamEnded returns why a followed stream ended: the error of ctx once it's done, the error the stream ended with if
+// the client tells it, ErrStreamEnded otherwise
+func streamEnded(ctx context.Context, stream string, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		return fmt.Errorf("%v stream ended: %w", stream, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrStreamEnded, stream)
+	}
+}
+
+func (m *Manager) notify(changes []LevelChange) {
+	if m.opts.OnLevelChange == nil {
+		return
+	}
+	for _, change := range changes {
+		m.opts.OnLevelChange(change)
+	}
+}
@@ -0,0 +1,488 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+const (
+	statusBuffer               = 100
+	defaultResubscribeInterval = time.Second
+	defaultPendingTimeout      = time.Minute
+)
+
+var (
+	ErrOrderNotFound       = errors.New("order is not tracked")
+	ErrOrderNotActive      = errors.New("order is not active")
+	ErrOpenOrdersUnknown   = errors.New("open orders address of the order is not known yet")
+	ErrMissingOwnerAddress = errors.New("owner address must be provided")
+	ErrOrderNotLanded      = errors.New("order was not seen on the market")
+)
+
+// Opts configures a Manager
+type Opts struct {
+	// Market the orders are placed on
+	Market string
+	// Owner of the orders, who must be able to sign for the client
+	Owner string
+	// Payer of the orders, defaults to Owner
+	Payer string
+	// OpenOrdersAddress of the owner on the market, found from order updates when not provided
+	OpenOrdersAddress string
+	// FirstClientOrderID is the first client order ID allocated, defaults to the current time in nanoseconds so that
+	// restarted managers don't reuse IDs
+	FirstClientOrderID uint64
+	SkipPreFlight      bool
+	// ResubscribeInterval is how long Run waits before subscribing again to an order status stream that ended,
+	// defaults to 1s
+	ResubscribeInterval time.Duration
+	// PendingTimeout is how long after its submission Reconcile waits for a pending order to be seen on the market,
+	// before failing it with ErrOrderNotLanded, defaults to 1m
+	PendingTimeout time.Duration
+	// OnEvent is called after every change to a tracked order
+	OnEvent func(Event)
+}
+
+// OrderRequest describes an order to place
+type OrderRequest struct {
+	Side   pb.Side
+	Types  []pb.OrderType
+	Amount float64
+	Price  float64
+}
+
+// Manager places orders of an owner on a market and tracks them through their lifecycle, by correlating order status
+// updates and open orders by client order ID and order ID
+type Manager struct {
+	client provider.Client
+	opts   Opts
+
+	m                 sync.RWMutex
+	nextClientOrderID uint64
+	openOrdersAddress string
+	orders            map[uint64]*Order
+	byOrderID         map[string]*Order
+}
+
+func NewManager(client provider.Client, opts Opts) (*Manager, error) {
+	if opts.Owner == "" {
+		return nil, ErrMissingOwnerAddress
+	}
+	if opts.Payer == "" {
+		opts.Payer = opts.Owner
+	}
+	if opts.ResubscribeInterval == 0 {
+		opts.ResubscribeInterval = defaultResubscribeInterval
+	}
+	if opts.PendingTimeout == 0 {
+		opts.PendingTimeout = defaultPendingTimeout
+	}
+	nextClientOrderID := opts.FirstClientOrderID
+	if nextClientOrderID == 0 {
+		nextClientOrderID = uint64(time.Now().UnixNano())
+	}
+
+	return &Manager{
+		client:            client,
+		opts:              opts,
+		nextClientOrderID: nextClientOrderID,
+		openOrdersAddress: opts.OpenOrdersAddress,
+		orders:            make(map[uint64]*Order),
+		byOrderID:         make(map[string]*Order),
+	}, nil
+}
+
+// Submit allocates a client order ID for the order, then signs and submits it. The returned order is pending until it
+// is seen on the market, or failed if the submission was rejected.
+func (m *Manager) Submit(ctx context.Context, request OrderRequest) (Order, error) {
+	m.m.Lock()
+	o := &Order{
+		ClientOrderID:     m.nextClientOrderID,
+		Side:              request.Side,
+		Types:             request.Types,
+		Price:             request.Price,
+		Size:              request.Amount,
+		RemainingSize:     request.Amount,
+		OpenOrdersAddress: m.openOrdersAddress,
+		State:             StatePending,
+		UpdatedAt:         time.Now(),
+	}
+	m.nextClientOrderID++
+	m.orders[o.ClientOrderID] = o
+	m.m.Unlock()
+
+	signature, err := m.client.SubmitOrder(ctx, m.opts.Owner, m.opts.Payer, m.opts.Market, request.Side, request.Types, request.Amount, request.Price, provider.PostOrderOpts{
+		OpenOrdersAddress: o.OpenOrdersAddress,
+		ClientOrderID:     o.ClientOrderID,
+		SkipPreFlight:     m.opts.SkipPreFlight,
+	})
+
+	var timeoutErr *provider.ConfirmationTimeoutError
+	var event *Event
+	m.m.Lock()
+	o.Signature = signature
+	// orders whose confirmation timed out may still land, so they stay pending
+	if err != nil && !errors.As(err, &timeoutErr) && o.State == StatePending {
+		o.Err = err
+		event = m.transition(o, StateFailed)
+	}
+	snapshot := *o
+	m.m.Unlock()
+
+	m.emit(event)
+	return snapshot, err
+}
+
+// Cancel submits a cancel transaction for the order and returns its signature. The order is cancelled once the market
+// reports it.
+func (m *Manager) Cancel(ctx context.Context, clientOrderID uint64) (string, error) {
+	m.m.RLock()
+	o, ok := m.orders[clientOrderID]
+	var openOrdersAddress string
+	var state State
+	if ok {
+		openOrdersAddress, state = o.OpenOrdersAddress, o.State
+	}
+	m.m.RUnlock()
+
+	switch {
+	case !ok:
+		return "", ErrOrderNotFound
+	case !state.Active():
+		return "", fmt.Errorf("%w: order %v is %v", ErrOrderNotActive, clientOrderID, state)
+	case openOrdersAddress == "":
+		return "", ErrOpenOrdersUnknown
+	}
+
+	signature, err := m.client.SubmitCancelByClientOrderID(ctx, clientOrderID, m.opts.Owner, m.opts.Market, openOrdersAddress, m.opts.SkipPreFlight)
+	if err != nil {
+		return signature, err
+	}
+
+	m.m.Lock()
+	o.CancelRequested = true
+	m.m.Unlock()
+	return signature, nil
+}
+
+// Order returns the tracked order with the client order ID
+func (m *Manager) Order(clientOrderID uint64) (Order, bool) {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	o, ok := m.orders[clientOrderID]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// Orders returns the tracked orders, filtered to the given states if any are provided
+func (m *Manager) Orders(states ...State) []Order {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	orders := make([]Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		if len(states) == 0 || containsState(states, o.State) {
+			orders = append(orders, *o)
+		}
+	}
+	return orders
+}
+
+// HandleStatus applies an order status update. Updates for orders that aren't tracked are ignored.
+func (m *Manager) HandleStatus(update *pb.GetOrderStatusStreamResponse) {
+	info := update.GetOrderInfo()
+	if info == nil {
+		return
+	}
+	state, ok := stateFromStatus(info.OrderStatus)
+	if !ok {
+		return
+	}
+
+	m.m.Lock()
+	o := m.lookup(info.ClientOrderID, info.OrderID)
+	if o == nil || o.State.Final() {
+		m.m.Unlock()
+		return
+	}
+	m.observe(o, info.OrderID, info.OpenOrderAddress)
+	prev := *o
+	switch state {
+	case StatePartiallyFilled, StateFilled:
+		o.FilledSize += float64(info.QuantityReleased)
+		o.RemainingSize = o.Size - o.FilledSize
+		if state == StateFilled || o.RemainingSize < 0 {
+			o.RemainingSize = 0
+		}
+	}
+	// orders don't go back to open once they are partially filled
+	if state == StateOpen && o.State == StatePartiallyFilled {
+		state = StatePartiallyFilled
+	}
+	event := m.update(o, prev, state)
+	m.m.Unlock()
+
+	m.emit(event)
+}
+
+// Reconcile corrects the tracked orders from GetOpenOrders, to be used on startup or when status updates may have been
+// missed. Open orders placed with a client order ID that aren't tracked yet are adopted, and tracked orders that are no
+// longer open are looked up with GetOrderByID to tell fills from cancellations. Pending orders that aren't open, such as
+// immediate-or-cancel orders that never rested on the book, are looked up by client order ID with GetOrders, and fail
+// with ErrOrderNotLanded once they aren't found after Opts.PendingTimeout.
+func (m *Manager) Reconcile(ctx context.Context) error {
+	// pending orders are looked up before the open orders, so that those that were placed and aren't open anymore can be
+	// told from those placed in between
+	placed, err := m.placedOrders(ctx)
+	if err != nil {
+		return err
+	}
+	openOrders, err := m.client.GetOpenOrders(ctx, m.opts.Market, m.opts.Owner)
+	if err != nil {
+		return err
+	}
+
+	var events []*Event
+	seen := make(map[*Order]bool)
+	m.m.Lock()
+	for _, openOrder := range openOrders.Orders {
+		clientOrderID, _ := strconv.ParseUint(openOrder.ClientOrderID, 10, 64)
+		o := m.lookup(clientOrderID, openOrder.OrderID)
+		if o == nil && clientOrderID == 0 {
+			continue
+		}
+		if o == nil {
+			o = &Order{
+				ClientOrderID: clientOrderID,
+				Side:          openOrder.Side,
+				Types:         openOrder.Types,
+				Price:         openOrder.Price,
+				Size:          openOrder.RemainingSize,
+				State:         StatePending,
+			}
+			m.orders[clientOrderID] = o
+		}
+		seen[o] = true
+
+		m.observe(o, openOrder.OrderID, openOrder.OpenOrderAccount)
+		prev := *o
+		o.RemainingSize = openOrder.RemainingSize
+		o.FilledSize = o.Size - o.RemainingSize
+		state := StateOpen
+		if o.FilledSize > 0 {
+			state = StatePartiallyFilled
+		}
+		events = append(events, m.update(o, prev, state))
+	}
+
+	var gone []*Order
+	for _, o := range m.orders {
+		switch {
+		case seen[o]:
+		case (o.State == StateOpen || o.State == StatePartiallyFilled) && o.OrderID != "":
+			gone = append(gone, o)
+		case o.State == StatePending && placed != nil:
+			events = append(events, m.resolvePending(o, placed))
+		}
+	}
+	m.m.Unlock()
+
+	var lookupErr error
+	for _, o := range gone {
+		m.m.RLock()
+		orderID := o.OrderID
+		m.m.RUnlock()
+
+		response, err := m.client.GetOrderByID(ctx, m.opts.Market, orderID)
+		if err != nil {
+			if lookupErr == nil {
+				lookupErr = fmt.Errorf("failed to look up order %v: %w", orderID, err)
+			}
+			continue
+		}
+
+		m.m.Lock()
+		if !o.State.Final() {
+			prev := *o
+			state := StateCancelled
+			if response.GetOrder().GetRemainingSize() <= 0 {
+				state = StateFilled
+				o.FilledSize, o.RemainingSize = o.Size, 0
+			}
+			events = append(events, m.update(o, prev, state))
+		}
+		m.m.Unlock()
+	}
+
+	for _, event := range events {
+		m.emit(event)
+	}
+	return lookupErr
+}
+
+// placedOrders returns the owner's orders on the market by client order ID, if there are pending orders to look up
+func (m *Manager) placedOrders(ctx context.Context) (map[uint64]*pb.Order, error) {
+	m.m.RLock()
+	lookup := false
+	for _, o := range m.orders {
+		lookup = lookup || o.State == StatePending
+	}
+	m.m.RUnlock()
+	if !lookup {
+		return nil, nil
+	}
+
+	response, err := m.client.GetOrders(ctx, m.opts.Market, m.opts.Owner, provider.GetOrdersOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pending orders: %w", err)
+	}
+	placed := make(map[uint64]*pb.Order, len(response.Orders))
+	for _, order := range response.Orders {
+		if clientOrderID, _ := strconv.ParseUint(order.ClientOrderID, 10, 64); clientOrderID != 0 {
+			placed[clientOrderID] = order
+		}
+	}
+	return placed, nil
+}
+
+// resolvePending moves a pending order that isn't open to its final state if it was placed, or fails it once the
+// pending timeout has passed. The lock must be held.
+func (m *Manager) resolvePending(o *Order, placed map[uint64]*pb.Order) *Event {
+	prev := *o
+	order, ok := placed[o.ClientOrderID]
+	switch {
+	case ok:
+		m.observe(o, order.OrderID, order.OpenOrderAccount)
+		state := StateCancelled
+		o.RemainingSize = order.RemainingSize
+		if o.RemainingSize <= 0 {
+			state, o.RemainingSize = StateFilled, 0
+		}
+		o.FilledSize = o.Size - o.RemainingSize
+		return m.update(o, prev, state)
+	case time.Since(o.UpdatedAt) >= m.opts.PendingTimeout:
+		o.Err = ErrOrderNotLanded
+		return m.update(o, prev, StateFailed)
+	default:
+		return nil
+	}
+}
+
+// Run subscribes to the order status stream, reconciles the tracked orders and applies the updates from the stream
+// until ctx is done. Streams that end are subscribed to again after Opts.ResubscribeInterval, and the orders are
+// reconciled before the updates of the new stream are applied, since updates may have been missed in between. Run
+// returns when subscribing or reconciling fails.
+func (m *Manager) Run(ctx context.Context, client provider.StreamingClient) error {
+	for {
+		if err := m.follow(ctx, client); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(m.opts.ResubscribeInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// follow subscribes to the order status stream, reconciles the tracked orders and applies the updates from the stream
+// until it ends, which returns nil
+func (m *Manager) follow(ctx context.Context, client provider.StreamingClient) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates := make(chan *pb.GetOrderStatusStreamResponse, statusBuffer)
+	errCh := make(chan error, 1)
+	// the GRPC client only returns once the first update arrives, so the subscription can't block reconciliation
+	go func() {
+		errCh <- client.GetOrderStatusStream(ctx, m.opts.Market, m.opts.Owner, updates)
+	}()
+
+	if err := m.Reconcile(ctx); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			if err != nil {
+				return err
+			}
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			m.HandleStatus(update)
+		}
+	}
+}
+
+// lookup finds a tracked order by client order ID, falling back to its order ID. The lock must be held.
+func (m *Manager) lookup(clientOrderID uint64, orderID string) *Order {
+	if o, ok := m.orders[clientOrderID]; ok && clientOrderID != 0 {
+		return o
+	}
+	if orderID == "" {
+		return nil
+	}
+	return m.byOrderID[orderID]
+}
+
+// observe records the market assigned fields of an order. The lock must be held.
+func (m *Manager) observe(o *Order, orderID, openOrdersAddress string) {
+	if orderID != "" && o.OrderID == "" {
+		o.OrderID = orderID
+		m.byOrderID[orderID] = o
+	}
+	if openOrdersAddress != "" {
+		o.OpenOrdersAddress = openOrdersAddress
+		if m.openOrdersAddress == "" {
+			m.openOrdersAddress = openOrdersAddress
+		}
+	}
+}
+
+// update moves the order to state and returns the event to emit, if the order changed. The lock must be held.
+func (m *Manager) update(o *Order, prev Order, state State) *Event {
+	o.State = state
+	if o.State == prev.State && o.FilledSize == prev.FilledSize {
+		return nil
+	}
+	o.UpdatedAt = time.Now()
+	return &Event{Order: *o, PrevState: prev.State}
+}
+
+// transition moves the order to state and returns the event to emit. The lock must be held.
+func (m *Manager) transition(o *Order, state State) *Event {
+	return m.update(o, *o, state)
+}
+
+func (m *Manager) emit(event *Event) {
+	if event != nil && m.opts.OnEvent != nil {
+		m.opts.OnEvent(*event)
+	}
+}
+
+func containsState(states []State, state State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
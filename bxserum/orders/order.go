@@ -0,0 +1,93 @@
+package orders
+
+import (
+	"time"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+// State is the lifecycle state of an order tracked by a Manager
+type State int
+
+const (
+	// StatePending orders were submitted but haven't been seen on the market yet
+	StatePending State = iota
+	StateOpen
+	StatePartiallyFilled
+	StateFilled
+	StateCancelled
+	// StateFailed orders were rejected on submission or failed on chain
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateOpen:
+		return "open"
+	case StatePartiallyFilled:
+		return "partially filled"
+	case StateFilled:
+		return "filled"
+	case StateCancelled:
+		return "cancelled"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Final reports whether orders in this state can't change anymore
+func (s State) Final() bool {
+	return s == StateFilled || s == StateCancelled || s == StateFailed
+}
+
+// Active reports whether orders in this state may be resting on the market
+func (s State) Active() bool {
+	return s == StatePending || s == StateOpen || s == StatePartiallyFilled
+}
+
+func stateFromStatus(status pb.OrderStatus) (State, bool) {
+	switch status {
+	case pb.OrderStatus_OS_OPEN:
+		return StateOpen, true
+	case pb.OrderStatus_OS_PARTIAL_FILL:
+		return StatePartiallyFilled, true
+	case pb.OrderStatus_OS_FILLED:
+		return StateFilled, true
+	case pb.OrderStatus_OS_CANCELLED:
+		return StateCancelled, true
+	default:
+		return 0, false
+	}
+}
+
+// Order is a snapshot of an order tracked by a Manager
+type Order struct {
+	ClientOrderID uint64
+	// OrderID is assigned by the market, and empty until the order is seen on it
+	OrderID           string
+	Side              pb.Side
+	Types             []pb.OrderType
+	Price             float64
+	Size              float64
+	FilledSize        float64
+	RemainingSize     float64
+	OpenOrdersAddress string
+	State             State
+	// Signature is the signature of the transaction that placed the order
+	Signature string
+	// CancelRequested is set once a cancel transaction for the order was submitted
+	CancelRequested bool
+	// Err is the reason the order failed
+	Err       error
+	UpdatedAt time.Time
+}
+
+// Event is emitted whenever a tracked order changes state or gets filled
+type Event struct {
+	Order     Order
+	PrevState State
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+// Client is the transport-agnostic set of request/response methods supported by every Serum API provider. Services
+// written against Client can switch between HTTP, websockets and GRPC without code changes.
+type Client interface {
+	GetOrderbook(ctx context.Context, market string, limit uint32) (*pb.GetOrderbookResponse, error)
+	GetTrades(ctx context.Context, market string, limit uint32) (*pb.GetTradesResponse, error)
+	GetTickers(ctx context.Context, market string) (*pb.GetTickersResponse, error)
+	GetOpenOrders(ctx context.Context, market string, owner string) (*pb.GetOpenOrdersResponse, error)
+	GetUnsettled(ctx context.Context, market string, owner string) (*pb.GetUnsettledResponse, error)
+	GetMarkets(ctx context.Context) (*pb.GetMarketsResponse, error)
+	GetAccountBalance(ctx context.Context, owner string) (*pb.GetAccountBalanceResponse, error)
+	GetKline(ctx context.Context, market string, from, to time.Time, resolution string, limit uint32) (*pb.GetKlineResponse, error)
+	GetOrders(ctx context.Context, market, owner string, opts GetOrdersOpts) (*pb.GetOrdersResponse, error)
+	GetOrderByID(ctx context.Context, market, orderID string) (*pb.GetOrderByIDResponse, error)
+	GetServerTime(ctx context.Context) (*pb.GetServerTimeResponse, error)
+
+	PostOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (*pb.PostOrderResponse, error)
+	PostSubmit(ctx context.Context, txBase64 string, skipPreFlight bool) (*pb.PostSubmitResponse, error)
+	SubmitOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error)
+	PostCancelOrder(ctx context.Context, orderID string, side pb.Side, owner, market, openOrders string) (*pb.PostCancelOrderResponse, error)
+	SubmitCancelOrder(ctx context.Context, orderID string, side pb.Side, owner, market, openOrders string, skipPreFlight bool) (string, error)
+	PostCancelByClientOrderID(ctx context.Context, clientOrderID uint64, owner, market, openOrders string) (*pb.PostCancelOrderResponse, error)
+	SubmitCancelByClientOrderID(ctx context.Context, clientOrderID uint64, owner, market, openOrders string, skipPreFlight bool) (string, error)
+	PostCancelAll(ctx context.Context, market, owner string, openOrders []string) (*pb.PostCancelAllResponse, error)
+	SubmitCancelAll(ctx context.Context, market, owner string, openOrders []string, skipPreFlight bool) ([]string, error)
+	PostSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string) (*pb.PostSettleResponse, error)
+	SubmitSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error)
+	ConfirmTransaction(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error)
+
+	Close() error
+}
+
+// StreamingClient extends Client with the streaming methods, which HTTPContextClient emulates by polling
+type StreamingClient interface {
+	Client
+
+	GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error
+	GetTradesStream(ctx context.Context, market string, limit uint32, tradesChan chan *pb.GetTradesStreamResponse) error
+	GetOrderStatusStream(ctx context.Context, market, ownerAddress string, statusUpdateChan chan *pb.GetOrderStatusStreamResponse) error
+	GetTickersStream(ctx context.Context, market string, tickersChan chan *pb.GetTickersStreamResponse) error
+	GetMarketDepthStream(ctx context.Context, depthChan chan *pb.GetMarketDepthStreamResponse) error
+}
+
+var (
+	_ StreamingClient = (*HTTPContextClient)(nil)
+	_ StreamingClient = (*GRPCClient)(nil)
+	_ StreamingClient = (*WSClient)(nil)
+)
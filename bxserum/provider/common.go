@@ -4,8 +4,13 @@ import (
 	"errors"
 	"time"
 
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/metrics"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
 	"github.com/gagliardetto/solana-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -26,10 +31,73 @@ type PostOrderOpts struct {
 	SkipPreFlight     bool
 }
 
+// GetOrdersOpts filters the orders returned by GetOrders. Zero values are not applied as filters.
+type GetOrdersOpts struct {
+	Status    pb.OrderStatus
+	Side      pb.Side
+	Types     []pb.OrderType
+	From      time.Time
+	Limit     uint32
+	Direction pb.Direction
+}
+
 type RPCOpts struct {
-	Endpoint   string
-	Timeout    time.Duration
+	Endpoint string
+	Timeout  time.Duration
+	// Signer signs the transactions sent by the Submit* methods, and takes precedence over PrivateKey
+	Signer transaction.Signer
+	// Deprecated: PrivateKey keeps the key in process memory, use Signer instead
 	PrivateKey *solana.PrivateKey
+
+	// WSReconnect enables automatic reconnection and subscription resumption (websockets only)
+	WSReconnect *connections.ReconnectPolicy
+	// OnWSConnectionEvent is called whenever the websocket connection is lost or re-established (websockets only)
+	OnWSConnectionEvent func(connections.ConnectionEvent)
+
+	// HTTPPollInterval is how often HTTP streams poll while updates keep arriving (HTTP only, defaults to 1s)
+	HTTPPollInterval time.Duration
+	// HTTPMaxPollInterval caps the poll interval HTTP streams back off to while nothing changes (HTTP only, defaults to 10s)
+	HTTPMaxPollInterval time.Duration
+	// HTTPMaxPollFailures is the number of consecutive failed polls that end an HTTP stream with the last error. Errors
+	// that polling again can't fix, such as an unknown market, end the stream right away (HTTP only, defaults to 5).
+	HTTPMaxPollFailures int
+	// HTTPMarketDepthMarket is the market whose depth HTTP market depth streams poll, as the streaming transports follow
+	// the market chosen by the API (HTTP only)
+	HTTPMarketDepthMarket string
+
+	// ShareStreams makes identical stream subscriptions (same stream and parameters) share a single subscription to the
+	// API, which ends when the last subscriber's context is done. Subscribers joining a shared stream receive the updates
+	// sent from then on, and updates queue up for a subscriber that doesn't read its channel without holding up the others
+	// (websockets and GRPC only).
+	ShareStreams bool
+	// StreamOverflow is the overflow policy of stream subscriptions, for when subscribers don't keep up with their
+	// channel. Defaults to OverflowBlock, and can be set per subscription with WithStreamOverflow.
+	StreamOverflow *StreamOverflow
+
+	// SolanaRPCEndpoint is a Solana RPC node used to track transaction confirmations
+	SolanaRPCEndpoint string
+	// WaitForConfirmation makes the Submit* methods wait until transactions are confirmed on SolanaRPCEndpoint
+	WaitForConfirmation *ConfirmationOpts
+
+	// OrderValidation checks orders before they are built, and can round their prices and amounts to the market tick and
+	// lot sizes. Orders aren't checked when not set.
+	OrderValidation *OrderValidation
+
+	// Retry retries read-only requests, and optionally PostSubmit, that fail with transient errors. Requests are
+	// attempted once when not set.
+	Retry *RetryPolicy
+	// RateLimiter limits the rate of requests per method class, and can be shared between clients
+	RateLimiter *RateLimiter
+
+	// Metrics records request latencies and errors, stream subscriptions and messages, and the websocket write queue
+	// depth (see bxserum/metrics/prometheus)
+	Metrics metrics.Metrics
+	// Tracing creates OpenTelemetry spans for requests and the steps of the Submit* methods, and propagates the trace
+	// context to the API. Nothing is traced when not set.
+	Tracing *TracingOpts
+	// Logger receives the connection lifecycle, subscription and request error logs (see bxserum/logger/logrus and
+	// bxserum/logger/slog). Nothing is logged when not set.
+	Logger logger.Logger
 }
 
 func DefaultRPCOpts(endpoint string) RPCOpts {
@@ -44,3 +112,45 @@ func DefaultRPCOpts(endpoint string) RPCOpts {
 		PrivateKey: spk,
 	}
 }
+
+// signer returns the configured Signer, falling back to a signer for PrivateKey
+func (o RPCOpts) signer() transaction.Signer {
+	if o.Signer != nil {
+		return o.Signer
+	}
+	if o.PrivateKey != nil {
+		return transaction.NewPrivateKeySigner(*o.PrivateKey)
+	}
+	return nil
+}
+
+// timestampOrNil converts t to a protobuf timestamp, leaving zero times unset
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func getKlineRequest(market string, from, to time.Time, resolution string, limit uint32) *pb.GetKlineRequest {
+	return &pb.GetKlineRequest{
+		Market:     market,
+		From:       timestampOrNil(from),
+		To:         timestampOrNil(to),
+		Resolution: resolution,
+		Limit:      limit,
+	}
+}
+
+func getOrdersRequest(market, owner string, opts GetOrdersOpts) *pb.GetOrdersRequest {
+	return &pb.GetOrdersRequest{
+		Market:    market,
+		Status:    opts.Status,
+		Side:      opts.Side,
+		Types:     opts.Types,
+		From:      timestampOrNil(opts.From),
+		Limit:     opts.Limit,
+		Direction: opts.Direction,
+		Address:   owner,
+	}
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	defaultConfirmationTimeout      = 60 * time.Second
+	defaultConfirmationPollInterval = 500 * time.Millisecond
+)
+
+var ErrSolanaRPCNotConfigured = errors.New("solana RPC endpoint not provided for confirming transactions")
+
+// ConfirmationOpts makes the Submit* methods wait until submitted transactions reach Commitment on the Solana RPC
+// endpoint configured in RPCOpts. Zero values use the defaults: confirmed commitment, a 60s timeout and polling every 500ms.
+type ConfirmationOpts struct {
+	Commitment   solanarpc.CommitmentType
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+// Confirmation is the status of a transaction on the Solana network
+type Confirmation struct {
+	Signature string
+	Slot      uint64
+	Status    solanarpc.ConfirmationStatusType
+	// Err is the transaction error reported by the network, nil if the transaction succeeded
+	Err interface{}
+}
+
+// ConfirmationTimeoutError is returned when a transaction doesn't reach the requested commitment in time. Last is the
+// most recent status seen, nil if the transaction was never found.
+type ConfirmationTimeoutError struct {
+	Signature  string
+	Commitment solanarpc.CommitmentType
+	Timeout    time.Duration
+	Last       *Confirmation
+}
+
+func (e *ConfirmationTimeoutError) Error() string {
+	if e.Last == nil {
+		return fmt.Sprintf("transaction %v was not found within %v", e.Signature, e.Timeout)
+	}
+	return fmt.Sprintf("transaction %v was not %v within %v (last status %v in slot %v)", e.Signature, e.Commitment, e.Timeout, e.Last.Status, e.Last.Slot)
+}
+
+// TransactionFailedError is returned when a transaction landed but failed on chain
+type TransactionFailedError struct {
+	Confirmation
+}
+
+func (e *TransactionFailedError) Error() string {
+	return fmt.Sprintf("transaction %v failed in slot %v: %v", e.Signature, e.Slot, e.Err)
+}
+
+// confirmer polls a Solana RPC endpoint for transaction statuses
+type confirmer struct {
+	rpcClient *solanarpc.Client
+	// wait is nil unless Submit* methods should wait for confirmations
+	wait   *ConfirmationOpts
+	tracer *tracer
+}
+
+func newConfirmer(opts RPCOpts) *confirmer {
+	c := &confirmer{tracer: newTracer(opts)}
+	if opts.SolanaRPCEndpoint != "" {
+		c.rpcClient = solanarpc.New(opts.SolanaRPCEndpoint)
+	}
+	if opts.WaitForConfirmation != nil {
+		wait := *opts.WaitForConfirmation
+		c.wait = &wait
+	}
+	return c
+}
+
+// afterSubmit waits for the submitted transaction if configured to. The signature is always returned, so that callers
+// can keep tracking transactions that timed out.
+func (c *confirmer) afterSubmit(ctx context.Context, signature string) (string, error) {
+	if c.wait == nil {
+		return signature, nil
+	}
+	_, err := c.confirm(ctx, signature, *c.wait)
+	return signature, err
+}
+
+func (c *confirmer) confirm(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error) {
+	return traced(ctx, c.tracer, "ConfirmTransaction", func(ctx context.Context) (*Confirmation, error) {
+		return c.poll(ctx, signature, opts)
+	})
+}
+
+func (c *confirmer) poll(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error) {
+	if c.rpcClient == nil {
+		return nil, ErrSolanaRPCNotConfigured
+	}
+	sig, err := solana.SignatureFromBase58(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	commitment := opts.Commitment
+	if commitment == "" {
+		commitment = solanarpc.CommitmentConfirmed
+	}
+	if commitmentLevel(solanarpc.ConfirmationStatusType(commitment)) == 0 {
+		return nil, fmt.Errorf("unsupported commitment %v: use processed, confirmed or finalized", commitment)
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultConfirmationTimeout
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultConfirmationPollInterval
+	}
+
+	// the polls are bounded by the deadline too, so that a hanging RPC call can't hold the timeout up
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var last *Confirmation
+	timedOut := func() (*Confirmation, error) {
+		return last, &ConfirmationTimeoutError{Signature: signature, Commitment: commitment, Timeout: timeout, Last: last}
+	}
+	for {
+		statuses, err := c.rpcClient.GetSignatureStatuses(deadlineCtx, true, sig)
+		if err != nil && ctx.Err() != nil {
+			return last, ctx.Err()
+		}
+		// transient RPC errors are retried until the deadline
+		if err == nil && len(statuses.Value) > 0 && statuses.Value[0] != nil {
+			status := statuses.Value[0]
+			last = &Confirmation{Signature: signature, Slot: status.Slot, Status: status.ConfirmationStatus, Err: status.Err}
+			if status.Err != nil {
+				return last, &TransactionFailedError{Confirmation: *last}
+			}
+			if commitmentLevel(last.Status) >= commitmentLevel(solanarpc.ConfirmationStatusType(commitment)) {
+				return last, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-deadlineCtx.Done():
+			return timedOut()
+		case <-ticker.C:
+		}
+	}
+}
+
+func commitmentLevel(status solanarpc.ConfirmationStatusType) int {
+	switch status {
+	case solanarpc.ConfirmationStatusProcessed:
+		return 1
+	case solanarpc.ConfirmationStatusConfirmed:
+		return 2
+	case solanarpc.ConfirmationStatusFinalized:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// grpcErrorInterceptor maps the errors of every GRPC call into the bxserum/errors model
+func grpcErrorInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return bxerrors.Wrap(bxerrors.TransportGRPC, invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// grpcErrorStreamInterceptor maps the errors of every GRPC stream message into the bxserum/errors model
+func grpcErrorStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, bxerrors.Wrap(bxerrors.TransportGRPC, err)
+	}
+	return errorMappingStream{stream}, nil
+}
+
+type errorMappingStream struct {
+	grpc.ClientStream
+}
+
+func (s errorMappingStream) SendMsg(m interface{}) error {
+	return bxerrors.Wrap(bxerrors.TransportGRPC, s.ClientStream.SendMsg(m))
+}
+
+func (s errorMappingStream) RecvMsg(m interface{}) error {
+	return bxerrors.Wrap(bxerrors.TransportGRPC, s.ClientStream.RecvMsg(m))
+}
+
+func httpGet[T protoreflect.ProtoMessage](ctx context.Context, h *HTTPClient, method, url string, val T) error {
+	return h.tracer.request(ctx, bxerrors.TransportHTTP, method, func(ctx context.Context) error {
+		return h.retrier.readOnly(ctx, method, func(ctx context.Context) error {
+			if err := h.rateLimiter.Wait(ctx, method); err != nil {
+				return err
+			}
+			return logRequest(h.log, method, observe(h.metrics, bxerrors.TransportHTTP, method, func() error {
+				return bxerrors.Wrap(bxerrors.TransportHTTP, connections.HTTPGetWithContext[T](ctx, url, h.httpClient, val))
+			}))
+		})
+	})
+}
+
+func httpPost[T protoreflect.ProtoMessage](ctx context.Context, h *HTTPClient, method, url string, body interface{}, val T) error {
+	return h.tracer.request(ctx, bxerrors.TransportHTTP, method, func(ctx context.Context) error {
+		if err := h.rateLimiter.Wait(ctx, method); err != nil {
+			return err
+		}
+		return logRequest(h.log, method, observe(h.metrics, bxerrors.TransportHTTP, method, func() error {
+			return bxerrors.Wrap(bxerrors.TransportHTTP, connections.HTTPPostWithContext[T](ctx, url, h.httpClient, body, val))
+		}))
+	})
+}
+
+func (w *WSClient) request(ctx context.Context, method string, request proto.Message, response proto.Message) error {
+	return w.tracer.request(ctx, bxerrors.TransportWS, method, func(ctx context.Context) error {
+		return w.retrier.readOnly(ctx, method, func(ctx context.Context) error {
+			if err := w.rateLimiter.Wait(ctx, method); err != nil {
+				return err
+			}
+			return logRequest(w.log, method, observe(w.metrics, bxerrors.TransportWS, method, func() error {
+				return bxerrors.Wrap(bxerrors.TransportWS, w.conn.Request(ctx, method, request, response))
+			}))
+		})
+	})
+}
+
+func wsStream[T proto.Message](w *WSClient, ctx context.Context, streamName string, streamParams proto.Message, resultInitFn func() T) (func() (T, error), error) {
+	if err := w.rateLimiter.Wait(ctx, streamName); err != nil {
+		return nil, err
+	}
+
+	// the span covers the subscription request, which carries the trace context to the server. Its context is only
+	// derived from ctx with values, so the stream still ends with ctx.
+	// only subscribers that chose OverflowDisconnect are disconnected by the connection, the others' updates are queued
+	// on it until they are read
+	overflow := streamOverflow(ctx, w.streams.overflow)
+	opts := connections.WSStreamOpts{DisconnectOnOverflow: overflow.Policy == OverflowDisconnect}
+	var generator func() (T, error)
+	err := w.tracer.request(ctx, bxerrors.TransportWS, streamName, func(ctx context.Context) error {
+		var err error
+		generator, err = connections.WSStreamWithOpts(w.conn, ctx, streamName, streamParams, opts, resultInitFn)
+		return err
+	})
+	if err != nil {
+		return nil, logRequest(w.log, streamName, bxerrors.Wrap(bxerrors.TransportWS, err))
+	}
+
+	ended := trackSubscription(ctx, w.metrics, bxerrors.TransportWS, streamName)
+	return func() (T, error) {
+		result, err := generator()
+		if errors.Is(err, connections.ErrSubscriptionOverflow) {
+			ended()
+			overflow.Stats.drop()
+			overflow.Stats.disconnect()
+			return result, ErrSlowSubscriber
+		}
+		if err != nil {
+			ended()
+			return result, bxerrors.Wrap(bxerrors.TransportWS, err)
+		}
+		w.metrics.ObserveStreamMessage(bxerrors.TransportWS, streamName)
+		return result, nil
+	}, nil
+}
+
+// wsSubscribe sends the updates of the stream to feed
+func wsSubscribe[T proto.Message](w *WSClient, ctx context.Context, streamName string, streamParams proto.Message, feed *Subscription[T], resultInitFn func() T) error {
+	generator, err := wsStream(w, ctx, streamName, streamParams, resultInitFn)
+	if err != nil {
+		return err
+	}
+
+	go runStream(generator, feed)
+	return nil
+}
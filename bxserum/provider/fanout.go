@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	"google.golang.org/protobuf/proto"
+)
+
+// streamManager delivers the updates of stream subscriptions to their consumers, following their overflow policy. With
+// RPCOpts.ShareStreams, it shares a single API subscription between all the consumers of identical streams.
+type streamManager struct {
+	share    bool
+	overflow *StreamOverflow
+	log      logger.Logger
+
+	m       sync.Mutex
+	streams map[string]interface{}
+}
+
+func newStreamManager(opts RPCOpts, log logger.Logger) *streamManager {
+	return &streamManager{share: opts.ShareStreams, overflow: opts.StreamOverflow, log: log, streams: make(map[string]interface{})}
+}
+
+// sharedStream is a subscription to the API and the consumers its updates are fanned out to
+type sharedStream[T any] struct {
+	manager *streamManager
+	key     string
+	cancel  context.CancelFunc
+
+	// ready is closed once the subscription is made, err is set if it failed
+	ready chan struct{}
+	err   error
+
+	// consumers is guarded by manager.m
+	consumers map[*streamConsumer[T]]struct{}
+}
+
+// streamConsumer is the subscription of one of the callers sharing a stream, ended once its context is done, the stream
+// ends or its overflow policy disconnects it. Its updates are queued and delivered by its own goroutine, so that a
+// consumer blocking on its channel doesn't hold up the others.
+type streamConsumer[T any] struct {
+	ctx    context.Context
+	sub    *Subscription[T]
+	sender streamSender[T]
+
+	m      sync.Mutex
+	queue  []T
+	ready  chan struct{}
+	ended  bool
+	err    error
+	closed bool
+}
+
+// shareStream subscribes sub to the stream with subscribe. When sharing streams, sub is added as a consumer of the
+// stream with the same name and params instead, and only subscribes if there is none yet. Consumers only receive the
+// updates sent after they join, and the subscription lasts until the last consumer's context is done. market keys the
+// updates for OverflowConflate.
+func shareStream[T any](m *streamManager, ctx context.Context, streamName string, params proto.Message, sub *Subscription[T], market func(T) string, subscribe func(ctx context.Context, feed *Subscription[T]) error) error {
+	sender := newStreamSender(ctx, m.overflow, sub.updates, market)
+	if !m.share {
+		ctx, cancel := context.WithCancel(ctx)
+		feed := newSubscription(ctx, make(chan T))
+		if err := subscribe(ctx, feed); err != nil {
+			cancel()
+			return err
+		}
+		go forwardStream(feed, sub, sender, cancel, m.log, streamName)
+		return nil
+	}
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(params)
+	if err != nil {
+		return err
+	}
+	key := streamName + "/" + string(b)
+	consumer := &streamConsumer[T]{ctx: ctx, sub: sub, sender: sender, ready: make(chan struct{}, 1)}
+
+	// the subscription ends with the last consumer rather than the first, but keeps the first one's values (e.g. its
+	// trace)
+	var streamCtx context.Context
+	m.m.Lock()
+	stream, joined := m.streams[key].(*sharedStream[T])
+	if !joined {
+		stream = &sharedStream[T]{manager: m, key: key, ready: make(chan struct{}), consumers: make(map[*streamConsumer[T]]struct{})}
+		streamCtx, stream.cancel = context.WithCancel(detachedContext{parent: ctx})
+		// consumers apply their own overflow policy, the subscription waits for them
+		streamCtx = WithStreamOverflow(streamCtx, StreamOverflow{Policy: OverflowBlock})
+		m.streams[key] = stream
+	}
+	stream.consumers[consumer] = struct{}{}
+	m.m.Unlock()
+
+	if !joined {
+		go stream.subscribe(streamCtx, streamName, subscribe)
+	}
+	return stream.join(streamName, consumer)
+}
+
+// subscribe makes the API subscription, then fans out its updates until it ends
+func (s *sharedStream[T]) subscribe(ctx context.Context, streamName string, subscribe func(ctx context.Context, feed *Subscription[T]) error) {
+	feed := newSubscription(ctx, make(chan T))
+	if err := subscribe(ctx, feed); err != nil {
+		s.err = err
+		s.end()
+		close(s.ready)
+		return
+	}
+	close(s.ready)
+
+	for update := range feed.updates {
+		s.manager.m.Lock()
+		consumers := make([]*streamConsumer[T], 0, len(s.consumers))
+		for consumer := range s.consumers {
+			consumers = append(consumers, consumer)
+		}
+		s.manager.m.Unlock()
+
+		for _, consumer := range consumers {
+			consumer.push(update)
+		}
+	}
+
+	for _, consumer := range s.end() {
+		consumer.finish(feed.err)
+	}
+}
+
+// end removes the stream from its manager, so that the next consumer subscribes again, and returns its consumers
+func (s *sharedStream[T]) end() []*streamConsumer[T] {
+	s.manager.m.Lock()
+	defer s.manager.m.Unlock()
+
+	if s.manager.streams[s.key] == s {
+		delete(s.manager.streams, s.key)
+	}
+	consumers := make([]*streamConsumer[T], 0, len(s.consumers))
+	for consumer := range s.consumers {
+		consumers = append(consumers, consumer)
+	}
+	s.consumers = make(map[*streamConsumer[T]]struct{})
+	s.cancel()
+	return consumers
+}
+
+// join waits for the subscription, then delivers the updates of consumer until its context is done
+func (s *sharedStream[T]) join(streamName string, consumer *streamConsumer[T]) error {
+	select {
+	case <-s.ready:
+	case <-consumer.ctx.Done():
+		s.leave(consumer)
+		return consumer.ctx.Err()
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	go consumer.deliver(s, streamName)
+	return nil
+}
+
+// leave removes the consumer, and unsubscribes if it was the last one
+func (s *sharedStream[T]) leave(consumer *streamConsumer[T]) {
+	s.manager.m.Lock()
+	_, ok := s.consumers[consumer]
+	delete(s.consumers, consumer)
+	if ok && len(s.consumers) == 0 {
+		if s.manager.streams[s.key] == s {
+			delete(s.manager.streams, s.key)
+		}
+		s.cancel()
+	}
+	s.manager.m.Unlock()
+}
+
+// push queues update for delivery, unless the consumer is gone
+func (c *streamConsumer[T]) push(update T) {
+	c.m.Lock()
+	if c.closed || c.ended {
+		c.m.Unlock()
+		return
+	}
+	c.queue = append(c.queue, update)
+	c.m.Unlock()
+	c.signal()
+}
+
+// finish ends the consumer with err once its queued updates are delivered
+func (c *streamConsumer[T]) finish(err error) {
+	c.m.Lock()
+	if !c.ended {
+		c.ended = true
+		c.err = err
+	}
+	c.m.Unlock()
+	c.signal()
+}
+
+func (c *streamConsumer[T]) signal() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the queued updates following the consumer's overflow policy, until its context is done, the stream
+// ends or the policy disconnects it
+func (c *streamConsumer[T]) deliver(s *sharedStream[T], streamName string) {
+	for {
+		if err := c.ctx.Err(); err != nil {
+			s.leave(c)
+			c.close(err)
+			return
+		}
+
+		c.m.Lock()
+		if c.closed {
+			c.m.Unlock()
+			return
+		}
+		if len(c.queue) > 0 {
+			update := c.queue[0]
+			var zero T
+			c.queue[0] = zero
+			c.queue = c.queue[1:]
+			c.m.Unlock()
+
+			if !c.sender.send(update) {
+				s.manager.log.Warn("disconnected slow stream subscriber", logger.Fields{"stream": streamName})
+				s.leave(c)
+				c.close(ErrSlowSubscriber)
+				return
+			}
+			continue
+		}
+		if c.ended {
+			err := c.err
+			c.m.Unlock()
+			c.close(err)
+			return
+		}
+		c.m.Unlock()
+
+		select {
+		case <-c.ready:
+		case <-c.ctx.Done():
+		}
+	}
+}
+
+func (c *streamConsumer[T]) close(err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.queue = nil
+	c.sub.end(err)
+}
+
+// detachedContext keeps the values of its parent, but not its deadline or cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
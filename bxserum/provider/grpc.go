@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
 	pb "github.com/bloXroute-Labs/serum-client-go/proto"
-	"github.com/gagliardetto/solana-go"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -15,8 +17,14 @@ import (
 type GRPCClient struct {
 	pb.UnimplementedApiServer
 
-	apiClient  pb.ApiClient
-	privateKey *solana.PrivateKey
+	conn      *grpc.ClientConn
+	apiClient pb.ApiClient
+	signer    transaction.Signer
+	confirmer *confirmer
+	validator *orderValidator
+	retrier   *retrier
+	tracer    *tracer
+	streams   *streamManager
 }
 
 // NewGRPCClient connects to Mainnet Serum API
@@ -33,14 +41,28 @@ func NewGRPCTestnet() (*GRPCClient, error) {
 
 // NewGRPCClientWithOpts connects to custom Serum API
 func NewGRPCClientWithOpts(opts RPCOpts) (*GRPCClient, error) {
-	conn, err := grpc.Dial(opts.Endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	retrier := newRetrier(opts)
+	tracer := newTracer(opts)
+	log := opts.logger(bxerrors.TransportGRPC).With(logger.Fields{"endpoint": opts.Endpoint})
+	conn, err := grpc.Dial(opts.Endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(tracer.grpcInterceptor, retrier.grpcInterceptor, opts.RateLimiter.grpcInterceptor, grpcMetricsInterceptor(opts.metrics()), grpcLoggingInterceptor(log), grpcErrorInterceptor),
+		grpc.WithChainStreamInterceptor(tracer.grpcStreamInterceptor, opts.RateLimiter.grpcStreamInterceptor, grpcMetricsStreamInterceptor(opts.metrics()), grpcLoggingStreamInterceptor(log), grpcErrorStreamInterceptor),
+	)
 	if err != nil {
 		return nil, err
 	}
-	return &GRPCClient{
-		apiClient:  pb.NewApiClient(conn),
-		privateKey: opts.PrivateKey,
-	}, nil
+	g := &GRPCClient{
+		conn:      conn,
+		apiClient: pb.NewApiClient(conn),
+		signer:    opts.signer(),
+		confirmer: newConfirmer(opts),
+		retrier:   retrier,
+		tracer:    tracer,
+		streams:   newStreamManager(opts, log),
+	}
+	g.validator = newOrderValidator(opts, g.GetMarkets)
+	return g, nil
 }
 
 // GetOrderbook returns the requested market's orderbook (e.g. asks and bids). Set limit to 0 for all bids / asks.
@@ -48,14 +70,34 @@ func (g *GRPCClient) GetOrderbook(ctx context.Context, market string, limit uint
 	return g.apiClient.GetOrderbook(ctx, &pb.GetOrderbookRequest{Market: market, Limit: limit})
 }
 
-// GetOrderbookStream subscribes to a stream for changes to the requested market updates (e.g. asks and bids. Set limit to 0 for all bids/ asks).
-func (g *GRPCClient) GetOrderbookStream(ctx context.Context, markets []string, limit uint32, outputChan chan *pb.GetOrderbooksStreamResponse) error {
-	stream, err := g.apiClient.GetOrderbooksStream(ctx, &pb.GetOrderbooksRequest{Markets: markets, Limit: limit})
-	if err != nil {
-		return err
-	}
+// GetOrderbooksStream subscribes to a stream for changes to the requested market updates (e.g. asks and bids. Set limit to 0 for all bids/ asks).
+func (g *GRPCClient) GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, outputChan chan *pb.GetOrderbooksStreamResponse) error {
+	return g.orderbooksStream(ctx, markets, limit, newSubscription(ctx, outputChan))
+}
+
+// SubscribeOrderbooks is GetOrderbooksStream returning a Subscription, which tells why the stream ended
+func (g *GRPCClient) SubscribeOrderbooks(ctx context.Context, markets []string, limit uint32) (*Subscription[*pb.GetOrderbooksStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+		return g.orderbooksStream(ctx, markets, limit, sub)
+	})
+}
 
-	return connections.GRPCStream[pb.GetOrderbooksStreamResponse](stream, fmt.Sprint(markets), outputChan)
+func (g *GRPCClient) orderbooksStream(ctx context.Context, markets []string, limit uint32, sub *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+	request := &pb.GetOrderbooksRequest{Markets: markets, Limit: limit}
+	return shareStream(g.streams, ctx, "GetOrderbooksStream", request, sub, orderbookMarket, func(ctx context.Context, feed *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+		stream, err := g.apiClient.GetOrderbooksStream(ctx, request)
+		if err != nil {
+			return err
+		}
+		return grpcStream[pb.GetOrderbooksStreamResponse](stream, fmt.Sprint(markets), feed)
+	})
+}
+
+// GetOrderbookStream is an alias of GetOrderbooksStream.
+//
+// Deprecated: use GetOrderbooksStream, which matches the name used by the other providers.
+func (g *GRPCClient) GetOrderbookStream(ctx context.Context, markets []string, limit uint32, outputChan chan *pb.GetOrderbooksStreamResponse) error {
+	return g.GetOrderbooksStream(ctx, markets, limit, outputChan)
 }
 
 // GetTrades returns the requested market's currently executing trades. Set limit to 0 for all trades.
@@ -65,22 +107,94 @@ func (g *GRPCClient) GetTrades(ctx context.Context, market string, limit uint32)
 
 // GetTradesStream subscribes to a stream for trades as they execute. Set limit to 0 for all trades.
 func (g *GRPCClient) GetTradesStream(ctx context.Context, market string, limit uint32, outputChan chan *pb.GetTradesStreamResponse) error {
-	stream, err := g.apiClient.GetTradesStream(ctx, &pb.GetTradesRequest{Market: market, Limit: limit})
-	if err != nil {
-		return err
-	}
+	return g.tradesStream(ctx, market, limit, newSubscription(ctx, outputChan))
+}
 
-	return connections.GRPCStream[pb.GetTradesStreamResponse](stream, market, outputChan)
+// SubscribeTrades is GetTradesStream returning a Subscription, which tells why the stream ended
+func (g *GRPCClient) SubscribeTrades(ctx context.Context, market string, limit uint32) (*Subscription[*pb.GetTradesStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetTradesStreamResponse]) error {
+		return g.tradesStream(ctx, market, limit, sub)
+	})
+}
+
+func (g *GRPCClient) tradesStream(ctx context.Context, market string, limit uint32, sub *Subscription[*pb.GetTradesStreamResponse]) error {
+	request := &pb.GetTradesRequest{Market: market, Limit: limit}
+	return shareStream(g.streams, ctx, "GetTradesStream", request, sub, streamMarket[*pb.GetTradesStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetTradesStreamResponse]) error {
+		stream, err := g.apiClient.GetTradesStream(ctx, request)
+		if err != nil {
+			return err
+		}
+		return grpcStream[pb.GetTradesStreamResponse](stream, market, feed)
+	})
 }
 
 // GetOrderStatusStream subscribes to a stream that shows updates to the owner's orders
 func (g *GRPCClient) GetOrderStatusStream(ctx context.Context, market, ownerAddress string, outputChan chan *pb.GetOrderStatusStreamResponse) error {
-	stream, err := g.apiClient.GetOrderStatusStream(ctx, &pb.GetOrderStatusStreamRequest{Market: market, OwnerAddress: ownerAddress})
-	if err != nil {
-		return err
-	}
+	return g.orderStatusStream(ctx, market, ownerAddress, newSubscription(ctx, outputChan))
+}
+
+// SubscribeOrderStatus is GetOrderStatusStream returning a Subscription, which tells why the stream ended
+func (g *GRPCClient) SubscribeOrderStatus(ctx context.Context, market, ownerAddress string) (*Subscription[*pb.GetOrderStatusStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+		return g.orderStatusStream(ctx, market, ownerAddress, sub)
+	})
+}
+
+func (g *GRPCClient) orderStatusStream(ctx context.Context, market, ownerAddress string, sub *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+	request := &pb.GetOrderStatusStreamRequest{Market: market, OwnerAddress: ownerAddress}
+	return shareStream(g.streams, ctx, "GetOrderStatusStream", request, sub, streamMarket[*pb.GetOrderStatusStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+		stream, err := g.apiClient.GetOrderStatusStream(ctx, request)
+		if err != nil {
+			return err
+		}
+		return grpcStream[pb.GetOrderStatusStreamResponse](stream, market, feed)
+	})
+}
 
-	return connections.GRPCStream[pb.GetOrderStatusStreamResponse](stream, market, outputChan)
+// GetTickersStream subscribes to a stream for the requested market's top of book. Set market to "" for all markets.
+func (g *GRPCClient) GetTickersStream(ctx context.Context, market string, outputChan chan *pb.GetTickersStreamResponse) error {
+	return g.tickersStream(ctx, market, newSubscription(ctx, outputChan))
+}
+
+// SubscribeTickers is GetTickersStream returning a Subscription, which tells why the stream ended
+func (g *GRPCClient) SubscribeTickers(ctx context.Context, market string) (*Subscription[*pb.GetTickersStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetTickersStreamResponse]) error {
+		return g.tickersStream(ctx, market, sub)
+	})
+}
+
+func (g *GRPCClient) tickersStream(ctx context.Context, market string, sub *Subscription[*pb.GetTickersStreamResponse]) error {
+	request := &pb.GetTickersRequest{Market: market}
+	return shareStream(g.streams, ctx, "GetTickersStream", request, sub, streamMarket[*pb.GetTickersStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetTickersStreamResponse]) error {
+		stream, err := g.apiClient.GetTickersStream(ctx, request)
+		if err != nil {
+			return err
+		}
+		return grpcStream[pb.GetTickersStreamResponse](stream, market, feed)
+	})
+}
+
+// GetMarketDepthStream subscribes to a stream of aggregated bid/ask depth changes. Each tick references the block height of the previous one.
+func (g *GRPCClient) GetMarketDepthStream(ctx context.Context, outputChan chan *pb.GetMarketDepthStreamResponse) error {
+	return g.marketDepthStream(ctx, newSubscription(ctx, outputChan))
+}
+
+// SubscribeMarketDepth is GetMarketDepthStream returning a Subscription, which tells why the stream ended
+func (g *GRPCClient) SubscribeMarketDepth(ctx context.Context) (*Subscription[*pb.GetMarketDepthStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+		return g.marketDepthStream(ctx, sub)
+	})
+}
+
+func (g *GRPCClient) marketDepthStream(ctx context.Context, sub *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+	request := &pb.GetMarketsRequest{}
+	return shareStream(g.streams, ctx, "GetMarketDepthStream", request, sub, streamMarket[*pb.GetMarketDepthStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+		stream, err := g.apiClient.GetMarketDepthStream(ctx, request)
+		if err != nil {
+			return err
+		}
+		return grpcStream[pb.GetMarketDepthStreamResponse](stream, "market depth", feed)
+	})
 }
 
 // GetTickers returns the requested market tickets. Set market to "" for all markets.
@@ -108,12 +222,40 @@ func (g *GRPCClient) GetAccountBalance(ctx context.Context, owner string) (*pb.G
 	return g.apiClient.GetAccountBalance(ctx, &pb.GetAccountBalanceRequest{OwnerAddress: owner})
 }
 
-// signAndSubmit signs the given transaction and submits it.
+// GetKline returns candles for the requested market between from and to. Resolution indicates the candle duration (e.g. 1d, 4h, 1h, 30m, 15m, 1m).
+func (g *GRPCClient) GetKline(ctx context.Context, market string, from, to time.Time, resolution string, limit uint32) (*pb.GetKlineResponse, error) {
+	return g.apiClient.GetKline(ctx, getKlineRequest(market, from, to, resolution, limit))
+}
+
+// GetOrders returns the owner's orders for a market, filtered by opts
+func (g *GRPCClient) GetOrders(ctx context.Context, market, owner string, opts GetOrdersOpts) (*pb.GetOrdersResponse, error) {
+	return g.apiClient.GetOrders(ctx, getOrdersRequest(market, owner, opts))
+}
+
+// GetOrderByID returns the order with the given ID. Market is optional but speeds up the lookup.
+func (g *GRPCClient) GetOrderByID(ctx context.Context, market, orderID string) (*pb.GetOrderByIDResponse, error) {
+	return g.apiClient.GetOrderByID(ctx, &pb.GetOrderByIDRequest{Market: market, OrderID: orderID})
+}
+
+// GetServerTime returns the Serum API server's current time
+func (g *GRPCClient) GetServerTime(ctx context.Context) (*pb.GetServerTimeResponse, error) {
+	return g.apiClient.GetServerTime(ctx, &pb.GetServerTimeRequest{})
+}
+
+// ConfirmTransaction waits until the transaction reaches the requested commitment on RPCOpts.SolanaRPCEndpoint
+func (g *GRPCClient) ConfirmTransaction(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error) {
+	return g.confirmer.confirm(ctx, signature, opts)
+}
+
+// signAndSubmit signs the given transaction and submits it. With RPCOpts.WaitForConfirmation set, it also waits for
+// the transaction to be confirmed.
 func (g *GRPCClient) signAndSubmit(ctx context.Context, tx string, skipPreFlight bool) (string, error) {
-	if g.privateKey == nil {
+	if g.signer == nil {
 		return "", ErrPrivateKeyNotFound
 	}
-	txBase64, err := transaction.SignTxWithPrivateKey(tx, *g.privateKey)
+	txBase64, err := traced(ctx, g.tracer, "SignTransaction", func(ctx context.Context) (string, error) {
+		return transaction.SignTxWithSigner(ctx, tx, g.signer)
+	})
 	if err != nil {
 		return "", err
 	}
@@ -123,12 +265,12 @@ func (g *GRPCClient) signAndSubmit(ctx context.Context, tx string, skipPreFlight
 		return "", err
 	}
 
-	return response.Signature, nil
+	return g.confirmer.afterSubmit(ctx, response.Signature)
 }
 
 // PostOrder returns a partially signed transaction for placing a Serum market order. Typically, you want to use SubmitOrder instead of this.
 func (g *GRPCClient) PostOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (*pb.PostOrderResponse, error) {
-	return g.apiClient.PostOrder(ctx, &pb.PostOrderRequest{
+	request := &pb.PostOrderRequest{
 		OwnerAddress:      owner,
 		PayerAddress:      payer,
 		Market:            market,
@@ -138,23 +280,31 @@ func (g *GRPCClient) PostOrder(ctx context.Context, owner, payer, market string,
 		Price:             price,
 		OpenOrdersAddress: opts.OpenOrdersAddress,
 		ClientOrderID:     opts.ClientOrderID,
-	})
+	}
+	if err := g.validator.validate(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return g.apiClient.PostOrder(ctx, request)
 }
 
 // PostSubmit posts the transaction string to the Solana network.
 func (g *GRPCClient) PostSubmit(ctx context.Context, txBase64 string, skipPreFlight bool) (*pb.PostSubmitResponse, error) {
-	return g.apiClient.PostSubmit(ctx, &pb.PostSubmitRequest{Transaction: txBase64,
-		SkipPreFlight: skipPreFlight})
+	return g.retrier.submit(ctx, txBase64, func(ctx context.Context) (*pb.PostSubmitResponse, error) {
+		return g.apiClient.PostSubmit(ctx, &pb.PostSubmitRequest{Transaction: txBase64, SkipPreFlight: skipPreFlight})
+	})
 }
 
 // SubmitOrder builds a Serum market order, signs it, and submits to the network.
 func (g *GRPCClient) SubmitOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error) {
-	order, err := g.PostOrder(ctx, owner, payer, market, side, types, amount, price, opts)
-	if err != nil {
-		return "", err
-	}
+	return traced(ctx, g.tracer, "SubmitOrder", func(ctx context.Context) (string, error) {
+		order, err := g.PostOrder(ctx, owner, payer, market, side, types, amount, price, opts)
+		if err != nil {
+			return "", err
+		}
 
-	return g.signAndSubmit(ctx, order.Transaction, opts.SkipPreFlight)
+		return g.signAndSubmit(ctx, order.Transaction, opts.SkipPreFlight)
+	})
 }
 
 // PostCancelOrder builds a Serum cancel order.
@@ -185,12 +335,14 @@ func (g *GRPCClient) SubmitCancelOrder(
 	openOrders string,
 	skipPreFlight bool,
 ) (string, error) {
-	order, err := g.PostCancelOrder(ctx, orderID, side, owner, market, openOrders)
-	if err != nil {
-		return "", err
-	}
+	return traced(ctx, g.tracer, "SubmitCancelOrder", func(ctx context.Context) (string, error) {
+		order, err := g.PostCancelOrder(ctx, orderID, side, owner, market, openOrders)
+		if err != nil {
+			return "", err
+		}
 
-	return g.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+		return g.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+	})
 }
 
 // PostCancelByClientOrderID builds a Serum cancel order by client ID.
@@ -218,12 +370,14 @@ func (g *GRPCClient) SubmitCancelByClientOrderID(
 	openOrders string,
 	skipPreFlight bool,
 ) (string, error) {
-	order, err := g.PostCancelByClientOrderID(ctx, clientOrderID, owner, market, openOrders)
-	if err != nil {
-		return "", err
-	}
+	return traced(ctx, g.tracer, "SubmitCancelByClientOrderID", func(ctx context.Context) (string, error) {
+		order, err := g.PostCancelByClientOrderID(ctx, clientOrderID, owner, market, openOrders)
+		if err != nil {
+			return "", err
+		}
 
-	return g.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+		return g.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+	})
 }
 
 func (g *GRPCClient) PostCancelAll(ctx context.Context, market, owner string, openOrders []string) (*pb.PostCancelAllResponse, error) {
@@ -235,22 +389,24 @@ func (g *GRPCClient) PostCancelAll(ctx context.Context, market, owner string, op
 }
 
 func (g *GRPCClient) SubmitCancelAll(ctx context.Context, market, owner string, openOrdersAddresses []string, skipPreFlight bool) ([]string, error) {
-	orders, err := g.PostCancelAll(ctx, market, owner, openOrdersAddresses)
-	if err != nil {
-		return nil, err
-	}
-
-	var signatures []string
-	for _, tx := range orders.Transactions {
-		signature, err := g.signAndSubmit(ctx, tx, skipPreFlight)
+	return traced(ctx, g.tracer, "SubmitCancelAll", func(ctx context.Context) ([]string, error) {
+		orders, err := g.PostCancelAll(ctx, market, owner, openOrdersAddresses)
 		if err != nil {
-			return signatures, err
+			return nil, err
 		}
 
-		signatures = append(signatures, signature)
-	}
+		var signatures []string
+		for _, tx := range orders.Transactions {
+			signature, err := g.signAndSubmit(ctx, tx, skipPreFlight)
+			if err != nil {
+				return signatures, err
+			}
 
-	return signatures, nil
+			signatures = append(signatures, signature)
+		}
+
+		return signatures, nil
+	})
 }
 
 // PostSettle returns a partially signed transaction for settling market funds. Typically, you want to use SubmitSettle instead of this.
@@ -266,10 +422,28 @@ func (g *GRPCClient) PostSettle(ctx context.Context, owner, market, baseTokenWal
 
 // SubmitSettle builds a market SubmitSettle transaction, signs it, and submits to the network.
 func (g *GRPCClient) SubmitSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error) {
-	order, err := g.PostSettle(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+	return traced(ctx, g.tracer, "SubmitSettle", func(ctx context.Context) (string, error) {
+		order, err := g.PostSettle(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+		if err != nil {
+			return "", err
+		}
+
+		return g.signAndSubmit(ctx, order.Transaction, skipPreflight)
+	})
+}
+
+// Close closes the underlying GRPC connection
+func (g *GRPCClient) Close() error {
+	return g.conn.Close()
+}
+
+// grpcStream sends the responses of stream to feed
+func grpcStream[T any](stream grpc.ClientStream, input string, feed *Subscription[*T]) error {
+	generator, err := connections.GRPCStreamGenerator[T](stream, input)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return g.signAndSubmit(ctx, order.Transaction, skipPreflight)
+	go runStream(generator, feed)
+	return nil
 }
@@ -1,23 +1,40 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
-	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/metrics"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
 	pb "github.com/bloXroute-Labs/serum-client-go/proto"
 	"github.com/bloXroute-Labs/serum-client-go/utils"
-	"github.com/gagliardetto/solana-go"
 )
 
 type HTTPClient struct {
 	pb.UnimplementedApiServer
 
-	baseURL    string
-	httpClient *http.Client
-	requestID  utils.RequestID
-	privateKey *solana.PrivateKey
+	baseURL     string
+	httpClient  *http.Client
+	requestID   utils.RequestID
+	signer      transaction.Signer
+	confirmer   *confirmer
+	validator   *orderValidator
+	retrier     *retrier
+	rateLimiter *RateLimiter
+	metrics     metrics.Metrics
+	tracer      *tracer
+	log         logger.Logger
+
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	maxPollFailures int
+	streamOverflow  *StreamOverflow
+	depthMarket     string
 }
 
 // NewHTTPClient connects to Mainnet Serum API
@@ -38,110 +55,278 @@ func NewHTTPClientWithOpts(client *http.Client, opts RPCOpts) *HTTPClient {
 		client = &http.Client{Timeout: opts.Timeout}
 	}
 
-	return &HTTPClient{
-		baseURL:    opts.Endpoint,
-		httpClient: client,
-		privateKey: opts.PrivateKey,
+	pollInterval := opts.HTTPPollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultHTTPPollInterval
 	}
+	maxPollInterval := opts.HTTPMaxPollInterval
+	if maxPollInterval < pollInterval {
+		maxPollInterval = defaultHTTPMaxPollInterval
+		if maxPollInterval < pollInterval {
+			maxPollInterval = pollInterval
+		}
+	}
+
+	maxPollFailures := opts.HTTPMaxPollFailures
+	if maxPollFailures <= 0 {
+		maxPollFailures = defaultHTTPMaxPollFailures
+	}
+
+	tracer := newTracer(opts)
+	h := &HTTPClient{
+		baseURL:         opts.Endpoint,
+		httpClient:      tracer.httpClient(client),
+		signer:          opts.signer(),
+		confirmer:       newConfirmer(opts),
+		retrier:         newRetrier(opts),
+		rateLimiter:     opts.RateLimiter,
+		metrics:         opts.metrics(),
+		tracer:          tracer,
+		log:             opts.logger(bxerrors.TransportHTTP).With(logger.Fields{"endpoint": opts.Endpoint}),
+		pollInterval:    pollInterval,
+		maxPollInterval: maxPollInterval,
+		maxPollFailures: maxPollFailures,
+		streamOverflow:  opts.StreamOverflow,
+		depthMarket:     opts.HTTPMarketDepthMarket,
+	}
+	h.validator = newOrderValidator(opts, h.GetMarketsWithContext)
+	return h
 }
 
-// GetOrderbook returns the requested market's orderbook (e.g. asks and bids). Set limit to 0 for all bids / asks.
+// GetOrderbook is GetOrderbookWithContext without a context.
+//
+// Deprecated: use GetOrderbookWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetOrderbook(market string, limit uint32) (*pb.GetOrderbookResponse, error) {
+	return h.GetOrderbookWithContext(context.Background(), market, limit)
+}
+
+// GetOrderbookWithContext returns the requested market's orderbook (e.g. asks and bids). Set limit to 0 for all bids / asks.
+func (h *HTTPClient) GetOrderbookWithContext(ctx context.Context, market string, limit uint32) (*pb.GetOrderbookResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/market/orderbooks/%s?limit=%v", h.baseURL, market, limit)
 	orderbook := new(pb.GetOrderbookResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetOrderbookResponse](url, h.httpClient, orderbook); err != nil {
+	if err := httpGet[*pb.GetOrderbookResponse](ctx, h, "GetOrderbook", url, orderbook); err != nil {
 		return nil, err
 	}
 
 	return orderbook, nil
 }
 
-// GetTrades returns the requested market's currently executing trades. Set limit to 0 for all trades.
+// GetTrades is GetTradesWithContext without a context.
+//
+// Deprecated: use GetTradesWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetTrades(market string, limit uint32) (*pb.GetTradesResponse, error) {
+	return h.GetTradesWithContext(context.Background(), market, limit)
+}
+
+// GetTradesWithContext returns the requested market's currently executing trades. Set limit to 0 for all trades.
+func (h *HTTPClient) GetTradesWithContext(ctx context.Context, market string, limit uint32) (*pb.GetTradesResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/market/trades/%s?limit=%v", h.baseURL, market, limit)
 	marketTrades := new(pb.GetTradesResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetTradesResponse](url, h.httpClient, marketTrades); err != nil {
+	if err := httpGet[*pb.GetTradesResponse](ctx, h, "GetTrades", url, marketTrades); err != nil {
 		return nil, err
 	}
 
 	return marketTrades, nil
 }
 
-// GetTickers returns the requested market tickets. Set market to "" for all markets.
+// GetTickers is GetTickersWithContext without a context.
+//
+// Deprecated: use GetTickersWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetTickers(market string) (*pb.GetTickersResponse, error) {
+	return h.GetTickersWithContext(context.Background(), market)
+}
+
+// GetTickersWithContext returns the requested market tickets. Set market to "" for all markets.
+func (h *HTTPClient) GetTickersWithContext(ctx context.Context, market string) (*pb.GetTickersResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/market/tickers/%s", h.baseURL, market)
 	tickers := new(pb.GetTickersResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetTickersResponse](url, h.httpClient, tickers); err != nil {
+	if err := httpGet[*pb.GetTickersResponse](ctx, h, "GetTickers", url, tickers); err != nil {
 		return nil, err
 	}
 
 	return tickers, nil
 }
 
-// GetOpenOrders returns all opened orders by owner address and market
+// GetOpenOrders is GetOpenOrdersWithContext without a context.
+//
+// Deprecated: use GetOpenOrdersWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetOpenOrders(market string, owner string) (*pb.GetOpenOrdersResponse, error) {
+	return h.GetOpenOrdersWithContext(context.Background(), market, owner)
+}
+
+// GetOpenOrdersWithContext returns all opened orders by owner address and market
+func (h *HTTPClient) GetOpenOrdersWithContext(ctx context.Context, market string, owner string) (*pb.GetOpenOrdersResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/openorders/%s?address=%s", h.baseURL, market, owner)
 	orders := new(pb.GetOpenOrdersResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetOpenOrdersResponse](url, h.httpClient, orders); err != nil {
+	if err := httpGet[*pb.GetOpenOrdersResponse](ctx, h, "GetOpenOrders", url, orders); err != nil {
 		return nil, err
 	}
 
 	return orders, nil
 }
 
-// GetMarkets returns the list of all available named markets
+// GetMarkets is GetMarketsWithContext without a context.
+//
+// Deprecated: use GetMarketsWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetMarkets() (*pb.GetMarketsResponse, error) {
+	return h.GetMarketsWithContext(context.Background())
+}
+
+// GetMarketsWithContext returns the list of all available named markets
+func (h *HTTPClient) GetMarketsWithContext(ctx context.Context) (*pb.GetMarketsResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/market/markets", h.baseURL)
 	markets := new(pb.GetMarketsResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetMarketsResponse](url, h.httpClient, markets); err != nil {
+	if err := httpGet[*pb.GetMarketsResponse](ctx, h, "GetMarkets", url, markets); err != nil {
 		return nil, err
 	}
 
 	return markets, nil
 }
 
-// GetUnsettled returns all OpenOrders accounts for a given market with the amounts of unsettled funds
+// GetUnsettled is GetUnsettledWithContext without a context.
+//
+// Deprecated: use GetUnsettledWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetUnsettled(market string, owner string) (*pb.GetUnsettledResponse, error) {
+	return h.GetUnsettledWithContext(context.Background(), market, owner)
+}
+
+// GetUnsettledWithContext returns all OpenOrders accounts for a given market with the amounts of unsettled funds
+func (h *HTTPClient) GetUnsettledWithContext(ctx context.Context, market string, owner string) (*pb.GetUnsettledResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/unsettled/%s?owner=%s", h.baseURL, market, owner)
 	result := new(pb.GetUnsettledResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetUnsettledResponse](url, h.httpClient, result); err != nil {
+	if err := httpGet[*pb.GetUnsettledResponse](ctx, h, "GetUnsettled", url, result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
-// GetAccountBalance returns all OpenOrders accounts for a given market with the amounts of unsettled funds
+// GetAccountBalance is GetAccountBalanceWithContext without a context.
+//
+// Deprecated: use GetAccountBalanceWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) GetAccountBalance(owner string) (*pb.GetAccountBalanceResponse, error) {
+	return h.GetAccountBalanceWithContext(context.Background(), owner)
+}
+
+// GetAccountBalanceWithContext returns all OpenOrders accounts for a given market with the amounts of unsettled funds
+func (h *HTTPClient) GetAccountBalanceWithContext(ctx context.Context, owner string) (*pb.GetAccountBalanceResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/account/balance?ownerAddress=%s", h.baseURL, owner)
 	result := new(pb.GetAccountBalanceResponse)
-	if err := connections.HTTPGetWithClient[*pb.GetAccountBalanceResponse](url, h.httpClient, result); err != nil {
+	if err := httpGet[*pb.GetAccountBalanceResponse](ctx, h, "GetAccountBalance", url, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetKline returns candles for the requested market between from and to. Resolution indicates the candle duration (e.g. 1d, 4h, 1h, 30m, 15m, 1m).
+func (h *HTTPClient) GetKline(ctx context.Context, market string, from, to time.Time, resolution string, limit uint32) (*pb.GetKlineResponse, error) {
+	params := url.Values{}
+	if !from.IsZero() {
+		params.Set("from", from.UTC().Format(time.RFC3339Nano))
+	}
+	if !to.IsZero() {
+		params.Set("to", to.UTC().Format(time.RFC3339Nano))
+	}
+	if resolution != "" {
+		params.Set("resolution", resolution)
+	}
+	params.Set("limit", fmt.Sprint(limit))
+
+	url := fmt.Sprintf("%s/api/v1/market/kline/%s?%s", h.baseURL, market, params.Encode())
+	result := new(pb.GetKlineResponse)
+	if err := httpGet[*pb.GetKlineResponse](ctx, h, "GetKline", url, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetOrders returns the owner's orders for a market, filtered by opts
+func (h *HTTPClient) GetOrders(ctx context.Context, market, owner string, opts GetOrdersOpts) (*pb.GetOrdersResponse, error) {
+	params := url.Values{}
+	params.Set("address", owner)
+	if opts.Status != pb.OrderStatus_OS_UNKNOWN {
+		params.Set("status", opts.Status.String())
+	}
+	if opts.Side != pb.Side_S_UNKNOWN {
+		params.Set("side", opts.Side.String())
+	}
+	for _, orderType := range opts.Types {
+		params.Add("types", orderType.String())
+	}
+	if !opts.From.IsZero() {
+		params.Set("from", opts.From.UTC().Format(time.RFC3339Nano))
+	}
+	params.Set("limit", fmt.Sprint(opts.Limit))
+	params.Set("direction", opts.Direction.String())
+
+	url := fmt.Sprintf("%s/api/v1/trade/orders/%s?%s", h.baseURL, market, params.Encode())
+	result := new(pb.GetOrdersResponse)
+	if err := httpGet[*pb.GetOrdersResponse](ctx, h, "GetOrders", url, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetOrderByID returns the order with the given ID. Market is optional but speeds up the lookup.
+func (h *HTTPClient) GetOrderByID(ctx context.Context, market, orderID string) (*pb.GetOrderByIDResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/trade/orderbyid/%s?market=%s", h.baseURL, orderID, market)
+	result := new(pb.GetOrderByIDResponse)
+	if err := httpGet[*pb.GetOrderByIDResponse](ctx, h, "GetOrderByID", url, result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
-// signAndSubmit signs the given transaction and submits it.
-func (h *HTTPClient) signAndSubmit(tx string, skipPreFlight bool) (string, error) {
-	if h.privateKey == nil {
+// GetServerTime returns the Serum API server's current time
+func (h *HTTPClient) GetServerTime(ctx context.Context) (*pb.GetServerTimeResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/system/time", h.baseURL)
+	result := new(pb.GetServerTimeResponse)
+	if err := httpGet[*pb.GetServerTimeResponse](ctx, h, "GetServerTime", url, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ConfirmTransaction waits until the transaction reaches the requested commitment on RPCOpts.SolanaRPCEndpoint
+func (h *HTTPClient) ConfirmTransaction(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error) {
+	return h.confirmer.confirm(ctx, signature, opts)
+}
+
+// signAndSubmit signs the given transaction and submits it. With RPCOpts.WaitForConfirmation set, it also waits for
+// the transaction to be confirmed.
+func (h *HTTPClient) signAndSubmit(ctx context.Context, tx string, skipPreFlight bool) (string, error) {
+	if h.signer == nil {
 		return "", ErrPrivateKeyNotFound
 	}
-	txBase64, err := transaction.SignTxWithPrivateKey(tx, *h.privateKey)
+	txBase64, err := traced(ctx, h.tracer, "SignTransaction", func(ctx context.Context) (string, error) {
+		return transaction.SignTxWithSigner(ctx, tx, h.signer)
+	})
 	if err != nil {
 		return "", err
 	}
 
-	response, err := h.PostSubmit(txBase64, skipPreFlight)
+	response, err := h.PostSubmitWithContext(ctx, txBase64, skipPreFlight)
 	if err != nil {
 		return "", err
 	}
 
-	return response.Signature, nil
+	return h.confirmer.afterSubmit(ctx, response.Signature)
 }
 
-// PostOrder returns a partially signed transaction for placing a Serum market order. Typically, you want to use SubmitOrder instead of this.
+// PostOrder is PostOrderWithContext without a context.
+//
+// Deprecated: use PostOrderWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) PostOrder(owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (*pb.PostOrderResponse, error) {
+	return h.PostOrderWithContext(context.Background(), owner, payer, market, side, types, amount, price, opts)
+}
+
+// PostOrderWithContext returns a partially signed transaction for placing a Serum market order. Typically, you want to use SubmitOrder instead of this.
+func (h *HTTPClient) PostOrderWithContext(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (*pb.PostOrderResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/place", h.baseURL)
 	request := &pb.PostOrderRequest{
 		OwnerAddress:      owner,
@@ -154,46 +339,81 @@ func (h *HTTPClient) PostOrder(owner, payer, market string, side pb.Side, types
 		OpenOrdersAddress: opts.OpenOrdersAddress,
 		ClientOrderID:     opts.ClientOrderID,
 	}
+	if err := h.validator.validate(ctx, request); err != nil {
+		return nil, err
+	}
 
 	var response pb.PostOrderResponse
-	err := connections.HTTPPostWithClient[*pb.PostOrderResponse](url, h.httpClient, request, &response)
+	err := httpPost[*pb.PostOrderResponse](ctx, h, "PostOrder", url, request, &response)
 	if err != nil {
 		return nil, err
 	}
 	return &response, nil
 }
 
-// PostSubmit posts the transaction string to the Solana network.
+// PostSubmit is PostSubmitWithContext without a context.
+//
+// Deprecated: use PostSubmitWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) PostSubmit(txBase64 string, skipPreFlight bool) (*pb.PostSubmitResponse, error) {
+	return h.PostSubmitWithContext(context.Background(), txBase64, skipPreFlight)
+}
+
+// PostSubmitWithContext posts the transaction string to the Solana network.
+func (h *HTTPClient) PostSubmitWithContext(ctx context.Context, txBase64 string, skipPreFlight bool) (*pb.PostSubmitResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/submit", h.baseURL)
 	request := &pb.PostSubmitRequest{Transaction: txBase64, SkipPreFlight: skipPreFlight}
 
-	var response pb.PostSubmitResponse
-	err := connections.HTTPPostWithClient[*pb.PostSubmitResponse](url, h.httpClient, request, &response)
-	if err != nil {
-		return nil, err
-	}
-	return &response, nil
+	return h.retrier.submit(ctx, txBase64, func(ctx context.Context) (*pb.PostSubmitResponse, error) {
+		var response pb.PostSubmitResponse
+		err := httpPost[*pb.PostSubmitResponse](ctx, h, "PostSubmit", url, request, &response)
+		if err != nil {
+			return nil, err
+		}
+		return &response, nil
+	})
 }
 
-// SubmitOrder builds a Serum market order, signs it, and submits to the network.
+// SubmitOrder is SubmitOrderWithContext without a context.
+//
+// Deprecated: use SubmitOrderWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) SubmitOrder(owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error) {
-	order, err := h.PostOrder(owner, payer, market, side, types, amount, price, opts)
-	if err != nil {
-		return "", err
-	}
+	return h.SubmitOrderWithContext(context.Background(), owner, payer, market, side, types, amount, price, opts)
+}
 
-	sig, err := h.signAndSubmit(order.Transaction, opts.SkipPreFlight)
-	return sig, err
+// SubmitOrderWithContext builds a Serum market order, signs it, and submits to the network.
+func (h *HTTPClient) SubmitOrderWithContext(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error) {
+	return traced(ctx, h.tracer, "SubmitOrder", func(ctx context.Context) (string, error) {
+		order, err := h.PostOrderWithContext(ctx, owner, payer, market, side, types, amount, price, opts)
+		if err != nil {
+			return "", err
+		}
+
+		sig, err := h.signAndSubmit(ctx, order.Transaction, opts.SkipPreFlight)
+		return sig, err
+	})
 }
 
-// PostCancelOrder builds a Serum cancel order.
+// PostCancelOrder is PostCancelOrderWithContext without a context.
+//
+// Deprecated: use PostCancelOrderWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) PostCancelOrder(
 	orderID string,
 	side pb.Side,
 	owner,
 	market,
 	openOrders string,
+) (*pb.PostCancelOrderResponse, error) {
+	return h.PostCancelOrderWithContext(context.Background(), orderID, side, owner, market, openOrders)
+}
+
+// PostCancelOrderWithContext builds a Serum cancel order.
+func (h *HTTPClient) PostCancelOrderWithContext(
+	ctx context.Context,
+	orderID string,
+	side pb.Side,
+	owner,
+	market,
+	openOrders string,
 ) (*pb.PostCancelOrderResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/cancel", h.baseURL)
 	request := &pb.PostCancelOrderRequest{
@@ -205,7 +425,7 @@ func (h *HTTPClient) PostCancelOrder(
 	}
 
 	var response pb.PostCancelOrderResponse
-	err := connections.HTTPPostWithClient[*pb.PostCancelOrderResponse](url, h.httpClient, request, &response)
+	err := httpPost[*pb.PostCancelOrderResponse](ctx, h, "PostCancelOrder", url, request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +433,9 @@ func (h *HTTPClient) PostCancelOrder(
 	return &response, nil
 }
 
-// SubmitCancelOrder builds a Serum cancel order, signs and submits it to the network.
+// SubmitCancelOrder is SubmitCancelOrderWithContext without a context.
+//
+// Deprecated: use SubmitCancelOrderWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) SubmitCancelOrder(
 	orderID string,
 	side pb.Side,
@@ -222,20 +444,48 @@ func (h *HTTPClient) SubmitCancelOrder(
 	openOrders string,
 	skipPreFlight bool,
 ) (string, error) {
-	order, err := h.PostCancelOrder(orderID, side, owner, market, openOrders)
-	if err != nil {
-		return "", err
-	}
+	return h.SubmitCancelOrderWithContext(context.Background(), orderID, side, owner, market, openOrders, skipPreFlight)
+}
+
+// SubmitCancelOrderWithContext builds a Serum cancel order, signs and submits it to the network.
+func (h *HTTPClient) SubmitCancelOrderWithContext(
+	ctx context.Context,
+	orderID string,
+	side pb.Side,
+	owner,
+	market,
+	openOrders string,
+	skipPreFlight bool,
+) (string, error) {
+	return traced(ctx, h.tracer, "SubmitCancelOrder", func(ctx context.Context) (string, error) {
+		order, err := h.PostCancelOrderWithContext(ctx, orderID, side, owner, market, openOrders)
+		if err != nil {
+			return "", err
+		}
 
-	return h.signAndSubmit(order.Transaction, skipPreFlight)
+		return h.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+	})
 }
 
-// PostCancelByClientOrderID builds a Serum cancel order by client ID.
+// PostCancelByClientOrderID is PostCancelByClientOrderIDWithContext without a context.
+//
+// Deprecated: use PostCancelByClientOrderIDWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) PostCancelByClientOrderID(
 	clientOrderID uint64,
 	owner,
 	market,
 	openOrders string,
+) (*pb.PostCancelOrderResponse, error) {
+	return h.PostCancelByClientOrderIDWithContext(context.Background(), clientOrderID, owner, market, openOrders)
+}
+
+// PostCancelByClientOrderIDWithContext builds a Serum cancel order by client ID.
+func (h *HTTPClient) PostCancelByClientOrderIDWithContext(
+	ctx context.Context,
+	clientOrderID uint64,
+	owner,
+	market,
+	openOrders string,
 ) (*pb.PostCancelOrderResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/cancelbyid", h.baseURL)
 	request := &pb.PostCancelByClientOrderIDRequest{
@@ -246,7 +496,7 @@ func (h *HTTPClient) PostCancelByClientOrderID(
 	}
 
 	var response pb.PostCancelOrderResponse
-	err := connections.HTTPPostWithClient[*pb.PostCancelOrderResponse](url, h.httpClient, request, &response)
+	err := httpPost[*pb.PostCancelOrderResponse](ctx, h, "PostCancelByClientOrderID", url, request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -254,7 +504,9 @@ func (h *HTTPClient) PostCancelByClientOrderID(
 	return &response, nil
 }
 
-// SubmitCancelByClientOrderID builds a Serum cancel order by client ID, signs and submits it to the network.
+// SubmitCancelByClientOrderID is SubmitCancelByClientOrderIDWithContext without a context.
+//
+// Deprecated: use SubmitCancelByClientOrderIDWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) SubmitCancelByClientOrderID(
 	clientOrderID uint64,
 	owner,
@@ -262,15 +514,36 @@ func (h *HTTPClient) SubmitCancelByClientOrderID(
 	openOrders string,
 	skipPreFlight bool,
 ) (string, error) {
-	order, err := h.PostCancelByClientOrderID(clientOrderID, owner, market, openOrders)
-	if err != nil {
-		return "", err
-	}
+	return h.SubmitCancelByClientOrderIDWithContext(context.Background(), clientOrderID, owner, market, openOrders, skipPreFlight)
+}
+
+// SubmitCancelByClientOrderIDWithContext builds a Serum cancel order by client ID, signs and submits it to the network.
+func (h *HTTPClient) SubmitCancelByClientOrderIDWithContext(
+	ctx context.Context,
+	clientOrderID uint64,
+	owner,
+	market,
+	openOrders string,
+	skipPreFlight bool,
+) (string, error) {
+	return traced(ctx, h.tracer, "SubmitCancelByClientOrderID", func(ctx context.Context) (string, error) {
+		order, err := h.PostCancelByClientOrderIDWithContext(ctx, clientOrderID, owner, market, openOrders)
+		if err != nil {
+			return "", err
+		}
 
-	return h.signAndSubmit(order.Transaction, skipPreFlight)
+		return h.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+	})
 }
 
+// PostCancelAll is PostCancelAllWithContext without a context.
+//
+// Deprecated: use PostCancelAllWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) PostCancelAll(market, owner string, openOrdersAddresses []string) (*pb.PostCancelAllResponse, error) {
+	return h.PostCancelAllWithContext(context.Background(), market, owner, openOrdersAddresses)
+}
+
+func (h *HTTPClient) PostCancelAllWithContext(ctx context.Context, market, owner string, openOrdersAddresses []string) (*pb.PostCancelAllResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/cancelall", h.baseURL)
 	request := &pb.PostCancelAllRequest{
 		Market:              market,
@@ -279,7 +552,7 @@ func (h *HTTPClient) PostCancelAll(market, owner string, openOrdersAddresses []s
 	}
 
 	var response pb.PostCancelAllResponse
-	err := connections.HTTPPostWithClient[*pb.PostCancelAllResponse](url, h.httpClient, request, &response)
+	err := httpPost[*pb.PostCancelAllResponse](ctx, h, "PostCancelAll", url, request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -287,27 +560,43 @@ func (h *HTTPClient) PostCancelAll(market, owner string, openOrdersAddresses []s
 	return &response, nil
 }
 
+// SubmitCancelAll is SubmitCancelAllWithContext without a context.
+//
+// Deprecated: use SubmitCancelAllWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) SubmitCancelAll(market, owner string, openOrders []string, skipPreFlight bool) ([]string, error) {
-	orders, err := h.PostCancelAll(market, owner, openOrders)
-	if err != nil {
-		return nil, err
-	}
+	return h.SubmitCancelAllWithContext(context.Background(), market, owner, openOrders, skipPreFlight)
+}
 
-	var signatures []string
-	for _, tx := range orders.Transactions {
-		signature, err := h.signAndSubmit(tx, skipPreFlight)
+func (h *HTTPClient) SubmitCancelAllWithContext(ctx context.Context, market, owner string, openOrders []string, skipPreFlight bool) ([]string, error) {
+	return traced(ctx, h.tracer, "SubmitCancelAll", func(ctx context.Context) ([]string, error) {
+		orders, err := h.PostCancelAllWithContext(ctx, market, owner, openOrders)
 		if err != nil {
-			return signatures, err
+			return nil, err
 		}
 
-		signatures = append(signatures, signature)
-	}
+		var signatures []string
+		for _, tx := range orders.Transactions {
+			signature, err := h.signAndSubmit(ctx, tx, skipPreFlight)
+			if err != nil {
+				return signatures, err
+			}
+
+			signatures = append(signatures, signature)
+		}
 
-	return signatures, nil
+		return signatures, nil
+	})
 }
 
-// PostSettle returns a partially signed transaction for settling market funds. Typically, you want to use SubmitSettle instead of this.
+// PostSettle is PostSettleWithContext without a context.
+//
+// Deprecated: use PostSettleWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) PostSettle(owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string) (*pb.PostSettleResponse, error) {
+	return h.PostSettleWithContext(context.Background(), owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+}
+
+// PostSettleWithContext returns a partially signed transaction for settling market funds. Typically, you want to use SubmitSettle instead of this.
+func (h *HTTPClient) PostSettleWithContext(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string) (*pb.PostSettleResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/trade/settle", h.baseURL)
 	request := &pb.PostSettleRequest{
 		OwnerAddress:      owner,
@@ -318,19 +607,116 @@ func (h *HTTPClient) PostSettle(owner, market, baseTokenWallet, quoteTokenWallet
 	}
 
 	var response pb.PostSettleResponse
-	err := connections.HTTPPostWithClient[*pb.PostSettleResponse](url, h.httpClient, request, &response)
+	err := httpPost[*pb.PostSettleResponse](ctx, h, "PostSettle", url, request, &response)
 	if err != nil {
 		return nil, err
 	}
 	return &response, nil
 }
 
-// SubmitSettle builds a market SubmitSettle transaction, signs it, and submits to the network.
+// SubmitSettle is SubmitSettleWithContext without a context.
+//
+// Deprecated: use SubmitSettleWithContext, or Client for a Client whose methods take a context.
 func (h *HTTPClient) SubmitSettle(owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error) {
-	order, err := h.PostSettle(owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
-	if err != nil {
-		return "", err
-	}
+	return h.SubmitSettleWithContext(context.Background(), owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount, skipPreflight)
+}
+
+// SubmitSettleWithContext builds a market SubmitSettle transaction, signs it, and submits to the network.
+func (h *HTTPClient) SubmitSettleWithContext(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error) {
+	return traced(ctx, h.tracer, "SubmitSettle", func(ctx context.Context) (string, error) {
+		order, err := h.PostSettleWithContext(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+		if err != nil {
+			return "", err
+		}
+
+		return h.signAndSubmit(ctx, order.Transaction, skipPreflight)
+	})
+}
+
+// Close releases any idle connections held by the underlying HTTP client
+func (h *HTTPClient) Close() error {
+	h.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// Client returns h as a Client, whose methods take a context
+func (h *HTTPClient) Client() *HTTPContextClient {
+	return &HTTPContextClient{HTTPClient: h}
+}
+
+// HTTPContextClient is the Client of an HTTPClient, which calls its WithContext methods
+type HTTPContextClient struct {
+	*HTTPClient
+}
+
+func (c *HTTPContextClient) GetOrderbook(ctx context.Context, market string, limit uint32) (*pb.GetOrderbookResponse, error) {
+	return c.GetOrderbookWithContext(ctx, market, limit)
+}
+
+func (c *HTTPContextClient) GetTrades(ctx context.Context, market string, limit uint32) (*pb.GetTradesResponse, error) {
+	return c.GetTradesWithContext(ctx, market, limit)
+}
+
+func (c *HTTPContextClient) GetTickers(ctx context.Context, market string) (*pb.GetTickersResponse, error) {
+	return c.GetTickersWithContext(ctx, market)
+}
+
+func (c *HTTPContextClient) GetOpenOrders(ctx context.Context, market string, owner string) (*pb.GetOpenOrdersResponse, error) {
+	return c.GetOpenOrdersWithContext(ctx, market, owner)
+}
+
+func (c *HTTPContextClient) GetMarkets(ctx context.Context) (*pb.GetMarketsResponse, error) {
+	return c.GetMarketsWithContext(ctx)
+}
+
+func (c *HTTPContextClient) GetUnsettled(ctx context.Context, market string, owner string) (*pb.GetUnsettledResponse, error) {
+	return c.GetUnsettledWithContext(ctx, market, owner)
+}
+
+func (c *HTTPContextClient) GetAccountBalance(ctx context.Context, owner string) (*pb.GetAccountBalanceResponse, error) {
+	return c.GetAccountBalanceWithContext(ctx, owner)
+}
+
+func (c *HTTPContextClient) PostOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (*pb.PostOrderResponse, error) {
+	return c.PostOrderWithContext(ctx, owner, payer, market, side, types, amount, price, opts)
+}
+
+func (c *HTTPContextClient) PostSubmit(ctx context.Context, txBase64 string, skipPreFlight bool) (*pb.PostSubmitResponse, error) {
+	return c.PostSubmitWithContext(ctx, txBase64, skipPreFlight)
+}
+
+func (c *HTTPContextClient) SubmitOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error) {
+	return c.SubmitOrderWithContext(ctx, owner, payer, market, side, types, amount, price, opts)
+}
+
+func (c *HTTPContextClient) PostCancelOrder(ctx context.Context, orderID string, side pb.Side, owner, market, openOrders string) (*pb.PostCancelOrderResponse, error) {
+	return c.PostCancelOrderWithContext(ctx, orderID, side, owner, market, openOrders)
+}
+
+func (c *HTTPContextClient) SubmitCancelOrder(ctx context.Context, orderID string, side pb.Side, owner, market, openOrders string, skipPreFlight bool) (string, error) {
+	return c.SubmitCancelOrderWithContext(ctx, orderID, side, owner, market, openOrders, skipPreFlight)
+}
+
+func (c *HTTPContextClient) PostCancelByClientOrderID(ctx context.Context, clientOrderID uint64, owner, market, openOrders string) (*pb.PostCancelOrderResponse, error) {
+	return c.PostCancelByClientOrderIDWithContext(ctx, clientOrderID, owner, market, openOrders)
+}
+
+func (c *HTTPContextClient) SubmitCancelByClientOrderID(ctx context.Context, clientOrderID uint64, owner, market, openOrders string, skipPreFlight bool) (string, error) {
+	return c.SubmitCancelByClientOrderIDWithContext(ctx, clientOrderID, owner, market, openOrders, skipPreFlight)
+}
+
+func (c *HTTPContextClient) PostCancelAll(ctx context.Context, market, owner string, openOrders []string) (*pb.PostCancelAllResponse, error) {
+	return c.PostCancelAllWithContext(ctx, market, owner, openOrders)
+}
+
+func (c *HTTPContextClient) SubmitCancelAll(ctx context.Context, market, owner string, openOrders []string, skipPreFlight bool) ([]string, error) {
+	return c.SubmitCancelAllWithContext(ctx, market, owner, openOrders, skipPreFlight)
+}
+
+func (c *HTTPContextClient) PostSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string) (*pb.PostSettleResponse, error) {
+	return c.PostSettleWithContext(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+}
 
-	return h.signAndSubmit(order.Transaction, skipPreflight)
+func (c *HTTPContextClient) SubmitSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error) {
+	return c.SubmitSettleWithContext(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount, skipPreflight)
 }
@@ -0,0 +1,354 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// The Serum API gateway does not expose its streaming RPCs over HTTP, so HTTPClient emulates them by polling the
+// equivalent request/response endpoints. Only changes are emitted, and the poll interval backs off while nothing changes.
+// Block heights are not available over HTTP and are left as 0 on every update.
+
+// ErrNoDepthMarket is returned by HTTP market depth streams when RPCOpts.HTTPMarketDepthMarket isn't set
+var ErrNoDepthMarket = errors.New("RPCOpts.HTTPMarketDepthMarket must be set to stream market depth over HTTP")
+
+const (
+	defaultHTTPPollInterval    = time.Second
+	defaultHTTPMaxPollInterval = 10 * time.Second
+	defaultHTTPMaxPollFailures = 5
+)
+
+// GetOrderbooksStream polls the requested markets' orderbooks and emits a market's orderbook whenever it changes. Set limit to 0 for all bids / asks.
+func (h *HTTPClient) GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error {
+	return h.orderbooksStream(ctx, markets, limit, newSubscription(ctx, orderbookChan))
+}
+
+// SubscribeOrderbooks is GetOrderbooksStream returning a Subscription, which tells why the stream ended
+func (h *HTTPClient) SubscribeOrderbooks(ctx context.Context, markets []string, limit uint32) (*Subscription[*pb.GetOrderbooksStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+		return h.orderbooksStream(ctx, markets, limit, sub)
+	})
+}
+
+func (h *HTTPClient) orderbooksStream(ctx context.Context, markets []string, limit uint32, sub *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+	orderbooks := make(map[string]*pb.GetOrderbookResponse)
+	return httpPollStream(ctx, h, "GetOrderbooksStream", orderbookMarket, func(ctx context.Context) ([]*pb.GetOrderbooksStreamResponse, error) {
+		var updates []*pb.GetOrderbooksStreamResponse
+		for _, market := range markets {
+			orderbook, err := h.GetOrderbookWithContext(ctx, market, limit)
+			if err != nil {
+				return nil, err
+			}
+			if proto.Equal(orderbooks[market], orderbook) {
+				continue
+			}
+
+			orderbooks[market] = orderbook
+			updates = append(updates, &pb.GetOrderbooksStreamResponse{Orderbook: orderbook})
+		}
+		return updates, nil
+	}, sub)
+}
+
+// GetTradesStream polls the requested market's trades and emits the trades that were not part of the previous poll. Set limit to 0 for all trades.
+func (h *HTTPClient) GetTradesStream(ctx context.Context, market string, limit uint32, tradesChan chan *pb.GetTradesStreamResponse) error {
+	return h.tradesStream(ctx, market, limit, newSubscription(ctx, tradesChan))
+}
+
+// SubscribeTrades is GetTradesStream returning a Subscription, which tells why the stream ended
+func (h *HTTPClient) SubscribeTrades(ctx context.Context, market string, limit uint32) (*Subscription[*pb.GetTradesStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetTradesStreamResponse]) error {
+		return h.tradesStream(ctx, market, limit, sub)
+	})
+}
+
+func (h *HTTPClient) tradesStream(ctx context.Context, market string, limit uint32, sub *Subscription[*pb.GetTradesStreamResponse]) error {
+	var previous []*pb.Trade
+	return httpPollStream(ctx, h, "GetTradesStream", streamMarket[*pb.GetTradesStreamResponse], func(ctx context.Context) ([]*pb.GetTradesStreamResponse, error) {
+		trades, err := h.GetTradesWithContext(ctx, market, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		executed := newTrades(previous, trades.Trades)
+		previous = trades.Trades
+		if len(executed) == 0 {
+			return nil, nil
+		}
+		return []*pb.GetTradesStreamResponse{{Trades: &pb.GetTradesResponse{Trades: executed}}}, nil
+	}, sub)
+}
+
+// GetTickersStream polls the requested market's tickers and emits them whenever they change. Set market to "" for all markets.
+func (h *HTTPClient) GetTickersStream(ctx context.Context, market string, tickersChan chan *pb.GetTickersStreamResponse) error {
+	return h.tickersStream(ctx, market, newSubscription(ctx, tickersChan))
+}
+
+// SubscribeTickers is GetTickersStream returning a Subscription, which tells why the stream ended
+func (h *HTTPClient) SubscribeTickers(ctx context.Context, market string) (*Subscription[*pb.GetTickersStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetTickersStreamResponse]) error {
+		return h.tickersStream(ctx, market, sub)
+	})
+}
+
+func (h *HTTPClient) tickersStream(ctx context.Context, market string, sub *Subscription[*pb.GetTickersStreamResponse]) error {
+	var previous *pb.GetTickersResponse
+	return httpPollStream(ctx, h, "GetTickersStream", streamMarket[*pb.GetTickersStreamResponse], func(ctx context.Context) ([]*pb.GetTickersStreamResponse, error) {
+		tickers, err := h.GetTickersWithContext(ctx, market)
+		if err != nil {
+			return nil, err
+		}
+		if proto.Equal(previous, tickers) {
+			return nil, nil
+		}
+
+		previous = tickers
+		return []*pb.GetTickersStreamResponse{{Ticker: tickers}}, nil
+	}, sub)
+}
+
+// GetMarketDepthStream polls the orderbook of RPCOpts.HTTPMarketDepthMarket and emits ticks like the streaming
+// transports: the first tick is a snapshot with a PrevBlockHeight of 0, and the following ones carry the levels that
+// changed, with a size of 0 for removed levels. As block heights aren't available over HTTP, the BlockHeight of a tick
+// counts the polls that found changes, so that every tick's PrevBlockHeight is the BlockHeight of the previous one.
+func (h *HTTPClient) GetMarketDepthStream(ctx context.Context, depthChan chan *pb.GetMarketDepthStreamResponse) error {
+	return h.marketDepthStream(ctx, newSubscription(ctx, depthChan))
+}
+
+// SubscribeMarketDepth is GetMarketDepthStream returning a Subscription, which tells why the stream ended
+func (h *HTTPClient) SubscribeMarketDepth(ctx context.Context) (*Subscription[*pb.GetMarketDepthStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+		return h.marketDepthStream(ctx, sub)
+	})
+}
+
+func (h *HTTPClient) marketDepthStream(ctx context.Context, sub *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+	if h.depthMarket == "" {
+		return ErrNoDepthMarket
+	}
+
+	var previous *pb.GetOrderbookResponse
+	var height int64
+	return httpPollStream(ctx, h, "GetMarketDepthStream", streamMarket[*pb.GetMarketDepthStreamResponse], func(ctx context.Context) ([]*pb.GetMarketDepthStreamResponse, error) {
+		orderbook, err := h.GetOrderbookWithContext(ctx, h.depthMarket, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		tick := &pb.MarketDepthTick{Bids: orderbook.Bids, Asks: orderbook.Asks}
+		if previous != nil {
+			tick.PrevBlockHeight = height
+			tick.Bids = depthChanges(previous.Bids, orderbook.Bids)
+			tick.Asks = depthChanges(previous.Asks, orderbook.Asks)
+			if len(tick.Bids) == 0 && len(tick.Asks) == 0 {
+				return nil, nil
+			}
+		}
+
+		previous = orderbook
+		height++
+		return []*pb.GetMarketDepthStreamResponse{{BlockHeight: height, Tick: tick}}, nil
+	}, sub)
+}
+
+// GetOrderStatusStream polls the owner's open orders and emits a status update whenever an order is opened, partially
+// filled or removed from the book. Orders that are already open when the stream starts are not reported.
+func (h *HTTPClient) GetOrderStatusStream(ctx context.Context, market, ownerAddress string, statusUpdateChan chan *pb.GetOrderStatusStreamResponse) error {
+	return h.orderStatusStream(ctx, market, ownerAddress, newSubscription(ctx, statusUpdateChan))
+}
+
+// SubscribeOrderStatus is GetOrderStatusStream returning a Subscription, which tells why the stream ended
+func (h *HTTPClient) SubscribeOrderStatus(ctx context.Context, market, ownerAddress string) (*Subscription[*pb.GetOrderStatusStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+		return h.orderStatusStream(ctx, market, ownerAddress, sub)
+	})
+}
+
+func (h *HTTPClient) orderStatusStream(ctx context.Context, market, ownerAddress string, sub *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+	var previous map[string]*pb.Order
+	return httpPollStream(ctx, h, "GetOrderStatusStream", streamMarket[*pb.GetOrderStatusStreamResponse], func(ctx context.Context) ([]*pb.GetOrderStatusStreamResponse, error) {
+		openOrders, err := h.GetOpenOrdersWithContext(ctx, market, ownerAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		current := make(map[string]*pb.Order)
+		for _, order := range openOrders.Orders {
+			current[order.OrderID] = order
+		}
+		if previous == nil {
+			previous = current
+			return nil, nil
+		}
+
+		var updates []*pb.GetOrderStatusStreamResponse
+		for _, order := range openOrders.Orders {
+			prevOrder, ok := previous[order.OrderID]
+			if !ok {
+				updates = append(updates, orderStatusUpdate(order, pb.OrderStatus_OS_OPEN, 0))
+			} else if order.RemainingSize < prevOrder.RemainingSize {
+				updates = append(updates, orderStatusUpdate(order, pb.OrderStatus_OS_PARTIAL_FILL, prevOrder.RemainingSize-order.RemainingSize))
+			}
+		}
+		for orderID, prevOrder := range previous {
+			if _, ok := current[orderID]; ok {
+				continue
+			}
+			updates = append(updates, h.closedOrderStatusUpdate(ctx, prevOrder))
+		}
+
+		previous = current
+		return updates, nil
+	}, sub)
+}
+
+// closedOrderStatusUpdate looks up an order that is no longer open to tell whether it was filled or cancelled
+func (h *HTTPClient) closedOrderStatusUpdate(ctx context.Context, prevOrder *pb.Order) *pb.GetOrderStatusStreamResponse {
+	response, err := h.GetOrderByID(ctx, prevOrder.Market, prevOrder.OrderID)
+	if err != nil || response.Order == nil {
+		return orderStatusUpdate(prevOrder, pb.OrderStatus_OS_UNKNOWN, 0)
+	}
+	if response.Order.RemainingSize == 0 {
+		return orderStatusUpdate(response.Order, pb.OrderStatus_OS_FILLED, prevOrder.RemainingSize)
+	}
+	return orderStatusUpdate(response.Order, pb.OrderStatus_OS_CANCELLED, prevOrder.RemainingSize-response.Order.RemainingSize)
+}
+
+func orderStatusUpdate(order *pb.Order, status pb.OrderStatus, quantityReleased float64) *pb.GetOrderStatusStreamResponse {
+	clientOrderID, _ := strconv.ParseUint(order.ClientOrderID, 10, 64)
+	return &pb.GetOrderStatusStreamResponse{OrderInfo: &pb.GetOrderStatusResponse{
+		Market:           order.Market,
+		OpenOrderAddress: order.OpenOrderAccount,
+		OrderID:          order.OrderID,
+		ClientOrderID:    clientOrderID,
+		QuantityReleased: float32(quantityReleased),
+		Price:            float32(order.Price),
+		Side:             order.Side,
+		OrderStatus:      status,
+	}}
+}
+
+// depthChanges returns the levels of current whose size differs from previous, and the levels of previous that are no
+// longer in current with a size of 0
+func depthChanges(previous, current []*pb.OrderbookItem) []*pb.OrderbookItem {
+	sizes := make(map[float64]float64, len(previous))
+	for _, level := range previous {
+		sizes[level.Price] = level.Size
+	}
+
+	var changes []*pb.OrderbookItem
+	for _, level := range current {
+		size, ok := sizes[level.Price]
+		if !ok || size != level.Size {
+			changes = append(changes, level)
+		}
+		delete(sizes, level.Price)
+	}
+	for _, level := range previous {
+		if _, removed := sizes[level.Price]; removed {
+			changes = append(changes, &pb.OrderbookItem{Price: level.Price, Size: 0})
+		}
+	}
+	return changes
+}
+
+// newTrades returns the trades in current that were not in previous, preserving their order
+func newTrades(previous, current []*pb.Trade) []*pb.Trade {
+	matched := make([]bool, len(previous))
+	var trades []*pb.Trade
+	for _, trade := range current {
+		seen := false
+		for i, prevTrade := range previous {
+			if !matched[i] && proto.Equal(prevTrade, trade) {
+				matched[i] = true
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			trades = append(trades, trade)
+		}
+	}
+	return trades
+}
+
+// httpPollStream runs the first poll synchronously so request errors (e.g. an unknown market) are returned to the
+// caller, then keeps polling in the background until ctx is done or polls fail for good, and ends sub with the cause.
+// The interval resets to HTTPPollInterval after every poll that produced updates and doubles up to HTTPMaxPollInterval
+// otherwise.
+func httpPollStream[T any](ctx context.Context, h *HTTPClient, streamName string, market func(*T) string, poll func(ctx context.Context) ([]*T, error), sub *Subscription[*T]) error {
+	updates, err := poll(ctx)
+	if err != nil {
+		return err
+	}
+
+	sender := newStreamSender(ctx, h.streamOverflow, sub.updates, market)
+	ended := trackSubscription(ctx, h.metrics, bxerrors.TransportHTTP, streamName)
+	h.log.Debug("subscribed", logger.Fields{"stream": streamName})
+	go func() {
+		defer ended()
+		defer h.log.Debug("unsubscribed", logger.Fields{"stream": streamName})
+
+		sub.end(httpPoll(ctx, h, streamName, updates, poll, sender))
+	}()
+	return nil
+}
+
+// httpPoll delivers updates, then keeps polling for more until ctx is done, the overflow policy disconnects the
+// subscriber, or polls fail for good. Failed polls are retried at the backed off interval, up to HTTPMaxPollFailures
+// in a row.
+func httpPoll[T any](ctx context.Context, h *HTTPClient, streamName string, updates []*T, poll func(ctx context.Context) ([]*T, error), sender streamSender[*T]) error {
+	var err error
+	interval := h.pollInterval
+	failures := 0
+	for {
+		for _, update := range updates {
+			h.metrics.ObserveStreamMessage(bxerrors.TransportHTTP, streamName)
+			if !sender.send(update) {
+				h.log.Warn("disconnected slow stream subscriber", logger.Fields{"stream": streamName})
+				return ErrSlowSubscriber
+			}
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		updates, err = poll(ctx)
+		switch {
+		case err == nil && len(updates) > 0:
+			failures = 0
+			interval = h.pollInterval
+			continue
+		case err == nil:
+			failures = 0
+		case ctx.Err() != nil:
+			return ctx.Err()
+		default:
+			failures++
+			if permanentPollError(err) || failures >= h.maxPollFailures {
+				h.log.Warn("stream polling failed", logger.Fields{"stream": streamName, "failures": failures, "error": err})
+				return err
+			}
+			h.log.Warn("stream poll failed", logger.Fields{"stream": streamName, "failures": failures, "error": err})
+		}
+		interval *= 2
+		if interval > h.maxPollInterval {
+			interval = h.maxPollInterval
+		}
+	}
+}
+
+// permanentPollError reports whether polling again can't succeed
+func permanentPollError(err error) bool {
+	return errors.Is(err, bxerrors.ErrMarketNotFound) || errors.Is(err, bxerrors.ErrInvalidAddress)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// logger returns the configured Logger for the transport, falling back to discarding the logs
+func (o RPCOpts) logger(transport bxerrors.Transport) logger.Logger {
+	var l logger.Logger = logger.Nop{}
+	if o.Logger != nil {
+		l = o.Logger
+	}
+	return l.With(logger.Fields{"transport": string(transport)})
+}
+
+// logRequest logs the error of a failed request: transport errors are warnings, while errors returned by the API are
+// part of normal operation
+func logRequest(l logger.Logger, method string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	fields := logger.Fields{"method": method, "error": err}
+	switch {
+	case canceled(err):
+		l.Debug("request canceled", fields)
+	case errors.Is(err, bxerrors.ErrTransportClosed), errors.Is(err, bxerrors.ErrTimeout), errors.Is(err, bxerrors.ErrRateLimited):
+		l.Warn("request failed", fields)
+	default:
+		l.Info("request rejected", fields)
+	}
+	return err
+}
+
+func canceled(err error) bool {
+	return errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+}
+
+func grpcLoggingInterceptor(l logger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return logRequest(l, method[strings.LastIndex(method, "/")+1:], invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+func grpcLoggingStreamInterceptor(l logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		name := method[strings.LastIndex(method, "/")+1:]
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, logRequest(l, name, err)
+		}
+
+		l.Debug("subscribed", logger.Fields{"stream": name})
+		return &loggingStream{ClientStream: stream, log: l, name: name}, nil
+	}
+}
+
+// loggingStream logs how the stream ended
+type loggingStream struct {
+	grpc.ClientStream
+	log   logger.Logger
+	name  string
+	ended bool
+}
+
+func (s *loggingStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil || s.ended {
+		return err
+	}
+
+	s.ended = true
+	fields := logger.Fields{"stream": s.name}
+	switch {
+	case err == io.EOF:
+		s.log.Debug("stream ended by server", fields)
+	case canceled(err):
+		s.log.Debug("unsubscribed", fields)
+	default:
+		fields["error"] = err
+		s.log.Warn("stream failed", fields)
+	}
+	return err
+}
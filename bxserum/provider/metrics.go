@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/metrics"
+	"google.golang.org/grpc"
+)
+
+// metrics returns the configured Metrics, falling back to discarding them
+func (o RPCOpts) metrics() metrics.Metrics {
+	if o.Metrics == nil {
+		return metrics.Nop{}
+	}
+	return o.Metrics
+}
+
+// observe times fn as a request of the method
+func observe(m metrics.Metrics, transport bxerrors.Transport, method string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	m.ObserveRequest(transport, method, time.Since(start), err)
+	return err
+}
+
+// trackSubscription counts the subscription as active until ctx is done or the returned function is called
+func trackSubscription(ctx context.Context, m metrics.Metrics, transport bxerrors.Transport, stream string) func() {
+	m.AddSubscriptions(transport, stream, 1)
+
+	ended := make(chan struct{})
+	var once sync.Once
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-ended:
+		}
+		m.AddSubscriptions(transport, stream, -1)
+	}()
+	return func() {
+		once.Do(func() {
+			close(ended)
+		})
+	}
+}
+
+func grpcMetricsInterceptor(m metrics.Metrics) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return observe(m, bxerrors.TransportGRPC, method[strings.LastIndex(method, "/")+1:], func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+func grpcMetricsStreamInterceptor(m metrics.Metrics) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		// the stream context is done once the stream ends for any reason
+		name := method[strings.LastIndex(method, "/")+1:]
+		trackSubscription(stream.Context(), m, bxerrors.TransportGRPC, name)
+		return metricsStream{ClientStream: stream, metrics: m, name: name}, nil
+	}
+}
+
+type metricsStream struct {
+	grpc.ClientStream
+	metrics metrics.Metrics
+	name    string
+}
+
+func (s metricsStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.metrics.ObserveStreamMessage(bxerrors.TransportGRPC, s.name)
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+// OverflowPolicy decides what happens to a stream update when the subscriber's channel is full. Policies other than
+// OverflowBlock make room in the channel's buffer, so they need a buffered channel.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the subscriber to read the channel. Updates of a websocket subscription queue up on the
+	// connection meanwhile, without holding up its other subscriptions.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest removes the oldest update from the channel to make room for the new one
+	OverflowDropOldest
+	// OverflowDropNewest drops the new update
+	OverflowDropNewest
+	// OverflowConflate replaces the update of the same market waiting in the channel with the new one, so the
+	// subscriber gets the latest update of every market. This suits streams of snapshots, such as orderbooks and
+	// tickers, rather than trades or market depth changes. The oldest update is dropped if there is none for the market.
+	OverflowConflate
+	// OverflowDisconnect ends the subscription and closes the channel. Websocket subscriptions are also ended if updates
+	// pile up on the connection before they reach the channel.
+	OverflowDisconnect
+)
+
+// StreamOverflow configures the overflow policy of stream subscriptions
+type StreamOverflow struct {
+	Policy OverflowPolicy
+	// Stats counts the updates dropped and subscriptions disconnected by the policy, and can be shared between
+	// subscriptions
+	Stats *StreamStats
+}
+
+// StreamStats counts the updates that streams couldn't deliver to their subscriber
+type StreamStats struct {
+	dropped     uint64
+	disconnects uint64
+}
+
+// Dropped returns the number of updates dropped or replaced by a newer one
+func (s *StreamStats) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Disconnects returns the number of subscriptions ended by OverflowDisconnect
+func (s *StreamStats) Disconnects() uint64 {
+	return atomic.LoadUint64(&s.disconnects)
+}
+
+func (s *StreamStats) drop() {
+	if s != nil {
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+func (s *StreamStats) disconnect() {
+	if s != nil {
+		atomic.AddUint64(&s.disconnects, 1)
+	}
+}
+
+type streamOverflowKey struct{}
+
+// WithStreamOverflow sets the overflow policy of the stream subscriptions made with ctx, instead of
+// RPCOpts.StreamOverflow:
+//
+//	stats := &provider.StreamStats{}
+//	ctx = provider.WithStreamOverflow(ctx, provider.StreamOverflow{Policy: provider.OverflowConflate, Stats: stats})
+//	err := client.GetOrderbooksStream(ctx, markets, 0, orderbookChan)
+func WithStreamOverflow(ctx context.Context, overflow StreamOverflow) context.Context {
+	return context.WithValue(ctx, streamOverflowKey{}, overflow)
+}
+
+func streamOverflow(ctx context.Context, defaults *StreamOverflow) StreamOverflow {
+	if overflow, ok := ctx.Value(streamOverflowKey{}).(StreamOverflow); ok {
+		return overflow
+	}
+	if defaults != nil {
+		return *defaults
+	}
+	return StreamOverflow{}
+}
+
+// streamSender delivers the updates of a subscription to its subscriber's channel following its overflow policy
+type streamSender[T any] struct {
+	ctx      context.Context
+	ch       chan T
+	overflow StreamOverflow
+	// market keys updates for OverflowConflate
+	market func(T) string
+}
+
+func newStreamSender[T any](ctx context.Context, defaults *StreamOverflow, ch chan T, market func(T) string) streamSender[T] {
+	return streamSender[T]{ctx: ctx, ch: ch, overflow: streamOverflow(ctx, defaults), market: market}
+}
+
+// send delivers update, unless the subscriber's context is done. It returns false if the subscription must be
+// disconnected.
+func (s streamSender[T]) send(update T) bool {
+	if s.overflow.Policy == OverflowBlock {
+		select {
+		case s.ch <- update:
+		case <-s.ctx.Done():
+		}
+		return true
+	}
+
+	select {
+	case s.ch <- update:
+		return true
+	default:
+	}
+
+	switch s.overflow.Policy {
+	case OverflowDropNewest:
+		s.overflow.Stats.drop()
+	case OverflowDropOldest:
+		s.dropOldest(update)
+	case OverflowConflate:
+		s.conflate(update)
+	case OverflowDisconnect:
+		s.overflow.Stats.drop()
+		s.overflow.Stats.disconnect()
+		return false
+	}
+	return true
+}
+
+// dropOldest makes room for update, or drops it if the subscriber keeps filling the channel
+func (s streamSender[T]) dropOldest(update T) {
+	for i := 0; i < cap(s.ch)+1; i++ {
+		select {
+		case <-s.ch:
+			s.overflow.Stats.drop()
+		default:
+		}
+		select {
+		case s.ch <- update:
+			return
+		default:
+		}
+	}
+	s.overflow.Stats.drop()
+}
+
+// conflate replaces the updates of the same market waiting in the channel with update. The channel only has a single
+// sender, so it can be refilled without blocking once drained.
+func (s streamSender[T]) conflate(update T) {
+	var pending []T
+drain:
+	for {
+		select {
+		case p := <-s.ch:
+			pending = append(pending, p)
+		default:
+			break drain
+		}
+	}
+
+	market := s.market(update)
+	conflated := make([]T, 0, len(pending)+1)
+	replaced := false
+	for _, p := range pending {
+		if s.market(p) != market {
+			conflated = append(conflated, p)
+			continue
+		}
+		s.overflow.Stats.drop()
+		if !replaced {
+			conflated = append(conflated, update)
+			replaced = true
+		}
+	}
+	if !replaced {
+		conflated = append(conflated, update)
+	}
+
+	// without a waiting update of the same market, the oldest ones make room
+	for len(conflated) > cap(s.ch) {
+		conflated = conflated[1:]
+		s.overflow.Stats.drop()
+	}
+	for _, p := range conflated {
+		select {
+		case s.ch <- p:
+		default:
+			s.overflow.Stats.drop()
+		}
+	}
+}
+
+// forwardStream sends the updates of feed to sub until the feed ends, and ends sub with the same cause. The feed is
+// canceled if the overflow policy disconnects sub.
+func forwardStream[T any](feed *Subscription[T], sub *Subscription[T], sender streamSender[T], cancel context.CancelFunc, log logger.Logger, streamName string) {
+	defer cancel()
+
+	for update := range feed.updates {
+		if !sender.send(update) {
+			log.Warn("disconnected slow stream subscriber", logger.Fields{"stream": streamName})
+			sub.end(ErrSlowSubscriber)
+			cancel()
+			// the feed ends with its context: let it finish sending
+			for range feed.updates {
+			}
+			return
+		}
+	}
+	sub.end(feed.err)
+}
+
+// orderbookMarket keys orderbook updates by market
+func orderbookMarket(update *pb.GetOrderbooksStreamResponse) string {
+	return update.GetOrderbook().GetMarket()
+}
+
+// streamMarket keys the updates of single market streams
+func streamMarket[T any](T) string {
+	return ""
+}
@@ -0,0 +1,730 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultHealthCheckInterval = 5 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+	// latencySmoothing is the weight of the newest health check in an endpoint's latency
+	latencySmoothing = 0.5
+	// resubscribeBackoff is the wait before a pooled stream subscribes again after losing an endpoint
+	resubscribeBackoff = 500 * time.Millisecond
+)
+
+var ErrNoPoolMembers = errors.New("pool needs at least one endpoint")
+
+// PoolMember is a client connected to one of the endpoints of a Pool
+type PoolMember struct {
+	Endpoint string
+	Client   StreamingClient
+}
+
+// PoolOpts configures how a Pool checks and chooses its endpoints
+type PoolOpts struct {
+	// HealthCheckInterval is how often every endpoint is checked with GetServerTime (defaults to 5s)
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout fails health checks that take longer (defaults to 2s)
+	HealthCheckTimeout time.Duration
+	// RedundantStreams is the number of endpoints every stream subscribes to at once (defaults to 1). Updates older
+	// than the last one forwarded and copies of updates already forwarded at the same block height are dropped, so
+	// streams keep flowing without gaps while one of the endpoints falls behind or fails.
+	RedundantStreams int
+	// Logger receives endpoints' health changes and failovers, and defaults to RPCOpts.Logger in NewGRPCPool and NewWSPool
+	Logger logger.Logger
+}
+
+// EndpointStatus is the health of a Pool endpoint as last seen
+type EndpointStatus struct {
+	Endpoint string
+	Healthy  bool
+	// Latency is the smoothed round trip time of health checks
+	Latency   time.Duration
+	LastCheck time.Time
+	// LastErr is the error that made the endpoint unhealthy, nil while healthy
+	LastErr error
+}
+
+// poolMember tracks the health of a single endpoint
+type poolMember struct {
+	PoolMember
+
+	m      sync.Mutex
+	status EndpointStatus
+}
+
+func (m *poolMember) snapshot() EndpointStatus {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return m.status
+}
+
+// Pool is a StreamingClient spread across several Serum API endpoints, e.g. in different regions. Requests go to the
+// healthy endpoint with the lowest latency and fail over to the next one on transport errors, timeouts and server side
+// rate limits. Submit* methods build and sign a new transaction on every attempt, so they only fail over when the
+// server rate limited them, which means it didn't submit anything: after other errors their transaction could have
+// landed, and a second one would place the order twice. PostSubmit sends the same transaction, which can't land twice,
+// so it fails over like other requests.
+type Pool struct {
+	members []*poolMember
+	opts    PoolOpts
+	log     logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ StreamingClient = (*Pool)(nil)
+
+// NewPool checks the health of every member, then keeps checking them in the background until the pool is closed
+func NewPool(members []PoolMember, opts PoolOpts) (*Pool, error) {
+	if len(members) == 0 {
+		return nil, ErrNoPoolMembers
+	}
+	if opts.HealthCheckInterval <= 0 {
+		opts.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if opts.HealthCheckTimeout <= 0 {
+		opts.HealthCheckTimeout = defaultHealthCheckTimeout
+	}
+	if opts.RedundantStreams <= 0 {
+		opts.RedundantStreams = 1
+	}
+	var log logger.Logger = logger.Nop{}
+	if opts.Logger != nil {
+		log = opts.Logger
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{opts: opts, log: log, ctx: ctx, cancel: cancel}
+	for _, member := range members {
+		p.members = append(p.members, &poolMember{PoolMember: member, status: EndpointStatus{Endpoint: member.Endpoint}})
+	}
+
+	p.checkHealth()
+	p.wg.Add(1)
+	go p.healthLoop()
+	return p, nil
+}
+
+// NewGRPCPool creates a GRPC client for every endpoint, all configured with opts
+func NewGRPCPool(endpoints []string, opts RPCOpts, poolOpts PoolOpts) (*Pool, error) {
+	if poolOpts.Logger == nil {
+		poolOpts.Logger = opts.Logger
+	}
+	var members []PoolMember
+	for _, endpoint := range endpoints {
+		opts.Endpoint = endpoint
+		g, err := NewGRPCClientWithOpts(opts)
+		if err != nil {
+			closeMembers(members)
+			return nil, err
+		}
+		members = append(members, PoolMember{Endpoint: endpoint, Client: g})
+	}
+	return NewPool(members, poolOpts)
+}
+
+// NewWSPool connects a websocket client to every endpoint, all configured with opts. Endpoints that can't be reached
+// are left out of the pool, unless none can. Set opts.WSReconnect so that members recover from lost connections.
+func NewWSPool(endpoints []string, opts RPCOpts, poolOpts PoolOpts) (*Pool, error) {
+	if poolOpts.Logger == nil {
+		poolOpts.Logger = opts.Logger
+	}
+	var members []PoolMember
+	var lastErr error
+	for _, endpoint := range endpoints {
+		opts.Endpoint = endpoint
+		w, err := NewWSClientWithOpts(opts)
+		if err != nil {
+			lastErr = fmt.Errorf("could not connect to %v: %w", endpoint, err)
+			if poolOpts.Logger != nil {
+				poolOpts.Logger.Warn("leaving endpoint out of pool", logger.Fields{"endpoint": endpoint, "error": err})
+			}
+			continue
+		}
+		members = append(members, PoolMember{Endpoint: endpoint, Client: w})
+	}
+	if len(members) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return NewPool(members, poolOpts)
+}
+
+func closeMembers(members []PoolMember) {
+	for _, member := range members {
+		_ = member.Client.Close()
+	}
+}
+
+// Endpoints returns the status of every endpoint, in the order requests try them
+func (p *Pool) Endpoints() []EndpointStatus {
+	var statuses []EndpointStatus
+	for _, member := range p.candidates() {
+		statuses = append(statuses, member.snapshot())
+	}
+	return statuses
+}
+
+func (p *Pool) healthLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.opts.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.checkHealth()
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkHealth times GetServerTime on every endpoint at once
+func (p *Pool) checkHealth() {
+	var wg sync.WaitGroup
+	for _, member := range p.members {
+		wg.Add(1)
+		go func(member *poolMember) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(p.ctx, p.opts.HealthCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			_, err := member.Client.GetServerTime(ctx)
+			if p.ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				p.markUnhealthy(member, err)
+				return
+			}
+			p.markHealthy(member, time.Since(start))
+		}(member)
+	}
+	wg.Wait()
+}
+
+func (p *Pool) markHealthy(member *poolMember, latency time.Duration) {
+	member.m.Lock()
+	recovered := !member.status.Healthy && !member.status.LastCheck.IsZero()
+	if member.status.Latency == 0 {
+		member.status.Latency = latency
+	} else {
+		member.status.Latency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(member.status.Latency))
+	}
+	member.status.Healthy = true
+	member.status.LastErr = nil
+	member.status.LastCheck = time.Now()
+	member.m.Unlock()
+
+	if recovered {
+		p.log.Info("pool endpoint recovered", logger.Fields{"endpoint": member.Endpoint})
+	}
+}
+
+func (p *Pool) markUnhealthy(member *poolMember, err error) {
+	member.m.Lock()
+	wasHealthy := member.status.Healthy
+	member.status.Healthy = false
+	member.status.LastErr = err
+	member.status.LastCheck = time.Now()
+	member.m.Unlock()
+
+	if wasHealthy {
+		p.log.Warn("pool endpoint unhealthy", logger.Fields{"endpoint": member.Endpoint, "error": err})
+	}
+}
+
+// candidates orders the members by preference: healthy ones by latency, then unhealthy ones as a last resort
+func (p *Pool) candidates() []*poolMember {
+	type candidate struct {
+		member *poolMember
+		status EndpointStatus
+	}
+	var candidates []candidate
+	for _, member := range p.members {
+		candidates = append(candidates, candidate{member: member, status: member.snapshot()})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].status.Healthy != candidates[j].status.Healthy {
+			return candidates[i].status.Healthy
+		}
+		return candidates[i].status.Latency < candidates[j].status.Latency
+	})
+
+	members := make([]*poolMember, 0, len(candidates))
+	for _, c := range candidates {
+		members = append(members, c.member)
+	}
+	return members
+}
+
+// failover reports whether err is specific to the endpoint, so that another endpoint could succeed
+func failover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrClientRateLimited) {
+		return false
+	}
+	return errors.Is(err, bxerrors.ErrTransportClosed) || errors.Is(err, bxerrors.ErrTimeout) ||
+		errors.Is(err, bxerrors.ErrRateLimited)
+}
+
+// poolRequest calls fn on the preferred endpoint, failing over to the others in order
+func poolRequest[T any](ctx context.Context, p *Pool, fn func(c StreamingClient) (T, error)) (T, error) {
+	var result T
+	var err error
+	for _, member := range p.candidates() {
+		result, err = fn(member.Client)
+		if err == nil || !failover(ctx, err) {
+			return result, err
+		}
+
+		p.markUnhealthy(member, err)
+		p.log.Warn("failing over request", logger.Fields{"endpoint": member.Endpoint, "error": err})
+	}
+	return result, err
+}
+
+// poolSubmit calls fn on the preferred endpoint, failing over to the others in order only when it was rate limited by
+// the server
+func poolSubmit[T any](ctx context.Context, p *Pool, fn func(c StreamingClient) (T, error)) (T, error) {
+	var result T
+	var err error
+	for _, member := range p.candidates() {
+		result, err = fn(member.Client)
+		if err == nil || ctx.Err() != nil || !errors.Is(err, bxerrors.ErrRateLimited) {
+			return result, err
+		}
+
+		p.log.Warn("failing over rate limited submission", logger.Fields{"endpoint": member.Endpoint, "error": err})
+	}
+	return result, err
+}
+
+// poolStream subscribes to the stream on the preferred RedundantStreams endpoints and forwards the updates that aren't
+// older than the last one forwarded for the same key, nor a copy of one forwarded at the same height. Subscriptions that
+// end are moved to the next available endpoint, until ctx is done and outputChan is closed.
+func poolStream[T any](ctx context.Context, p *Pool, subscribe func(ctx context.Context, c StreamingClient, ch chan *T) error, blockHeight func(*T) int64, key func(*T) string, outputChan chan *T) error {
+	redundancy := p.opts.RedundantStreams
+	if redundancy > len(p.members) {
+		redundancy = len(p.members)
+	}
+
+	// the overflow policy applies to outputChan, rather than to the subscriptions deduplicated into it
+	sender := newStreamSender(ctx, nil, outputChan, key)
+	ctx, cancel := context.WithCancel(ctx)
+	updates := make(chan *T, cap(outputChan))
+	s := &poolSubscription[T]{pool: p, ctx: WithStreamOverflow(ctx, StreamOverflow{Policy: OverflowBlock}), subscribe: subscribe, updates: updates, active: make(map[*poolMember]bool)}
+
+	// the first subscription reports errors such as unknown markets to the caller, the others are best effort
+	if err := s.start(); err != nil {
+		cancel()
+		return err
+	}
+	for i := 1; i < redundancy; i++ {
+		go s.run(nil)
+	}
+
+	go func() {
+		defer close(outputChan)
+		defer cancel()
+
+		last := make(map[string]*forwarded)
+		for {
+			select {
+			case update := <-updates:
+				k := key(update)
+				f, ok := last[k]
+				if !ok {
+					f = &forwarded{}
+					last[k] = f
+				}
+				if !f.add(blockHeight(update), update) {
+					continue
+				}
+
+				if !sender.send(update) {
+					p.log.Warn("disconnected slow stream subscriber", nil)
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// forwarded is what a pooled stream forwarded for a key at the latest block height, to drop the copies that redundant
+// subscriptions send
+type forwarded struct {
+	height int64
+	hashes map[uint64]bool
+}
+
+// add reports whether update is to be forwarded: it isn't if it's older than the latest height, or a copy of an update
+// forwarded at that height. Updates without a block height are always forwarded, since copies can't be told apart from
+// repeated updates.
+func (f *forwarded) add(height int64, update interface{}) bool {
+	switch {
+	case height == 0:
+		return true
+	case height < f.height:
+		return false
+	case height > f.height:
+		f.height, f.hashes = height, make(map[uint64]bool)
+	}
+
+	hash := fnv.New64a()
+	if message, ok := update.(proto.Message); ok {
+		data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		_, _ = hash.Write(data)
+	}
+	if f.hashes[hash.Sum64()] {
+		return false
+	}
+	f.hashes[hash.Sum64()] = true
+	return true
+}
+
+// poolSubscription keeps a stream subscribed on distinct endpoints
+type poolSubscription[T any] struct {
+	pool      *Pool
+	ctx       context.Context
+	subscribe func(ctx context.Context, c StreamingClient, ch chan *T) error
+	updates   chan *T
+
+	m      sync.Mutex
+	active map[*poolMember]bool
+}
+
+// start subscribes on the first endpoint that accepts the subscription, then keeps it subscribed in the background
+func (s *poolSubscription[T]) start() error {
+	member, ch, cancel, err := s.connect()
+	if err != nil {
+		return err
+	}
+	go s.run(&established[T]{member: member, ch: ch, cancel: cancel})
+	return nil
+}
+
+type established[T any] struct {
+	member *poolMember
+	ch     chan *T
+	cancel context.CancelFunc
+}
+
+// run forwards the updates of a subscription, and subscribes again on another endpoint whenever it ends
+func (s *poolSubscription[T]) run(current *established[T]) {
+	for {
+		if current == nil {
+			member, ch, cancel, err := s.connect()
+			if err != nil {
+				select {
+				case <-time.After(resubscribeBackoff):
+					continue
+				case <-s.ctx.Done():
+					return
+				}
+			}
+			current = &established[T]{member: member, ch: ch, cancel: cancel}
+		}
+
+		s.forward(current.ch)
+		current.cancel()
+		s.m.Lock()
+		delete(s.active, current.member)
+		s.m.Unlock()
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		s.pool.markUnhealthy(current.member, errors.New("stream ended"))
+		s.pool.log.Warn("failing over stream", logger.Fields{"endpoint": current.member.Endpoint})
+		current = nil
+	}
+}
+
+// forward copies updates until the subscription's channel is closed or ctx is done
+func (s *poolSubscription[T]) forward(ch chan *T) {
+	for {
+		select {
+		case update, ok := <-ch:
+			if !ok {
+				return
+			}
+			select {
+			case s.updates <- update:
+			case <-s.ctx.Done():
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// connect subscribes on the preferred endpoint that isn't already used by this stream
+func (s *poolSubscription[T]) connect() (*poolMember, chan *T, context.CancelFunc, error) {
+	var err error = ErrNoPoolMembers
+	for _, member := range s.pool.candidates() {
+		s.m.Lock()
+		if s.active[member] {
+			s.m.Unlock()
+			continue
+		}
+		s.active[member] = true
+		s.m.Unlock()
+
+		ctx, cancel := context.WithCancel(s.ctx)
+		ch := make(chan *T, cap(s.updates))
+		err = s.subscribe(ctx, member.Client, ch)
+		if err == nil {
+			return member, ch, cancel, nil
+		}
+
+		cancel()
+		s.m.Lock()
+		delete(s.active, member)
+		s.m.Unlock()
+		if !failover(s.ctx, err) {
+			return nil, nil, nil, err
+		}
+		s.pool.markUnhealthy(member, err)
+	}
+	return nil, nil, nil, err
+}
+
+// GetOrderbook returns the requested market's orderbook (e.g. asks and bids). Set limit to 0 for all bids / asks.
+func (p *Pool) GetOrderbook(ctx context.Context, market string, limit uint32) (*pb.GetOrderbookResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetOrderbookResponse, error) {
+		return c.GetOrderbook(ctx, market, limit)
+	})
+}
+
+// GetTrades returns the requested market's currently executing trades. Set limit to 0 for all trades.
+func (p *Pool) GetTrades(ctx context.Context, market string, limit uint32) (*pb.GetTradesResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetTradesResponse, error) {
+		return c.GetTrades(ctx, market, limit)
+	})
+}
+
+// GetTickers returns the requested market tickets. Set market to "" for all markets.
+func (p *Pool) GetTickers(ctx context.Context, market string) (*pb.GetTickersResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetTickersResponse, error) {
+		return c.GetTickers(ctx, market)
+	})
+}
+
+// GetOpenOrders returns all opened orders by owner address and market
+func (p *Pool) GetOpenOrders(ctx context.Context, market string, owner string) (*pb.GetOpenOrdersResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetOpenOrdersResponse, error) {
+		return c.GetOpenOrders(ctx, market, owner)
+	})
+}
+
+// GetUnsettled returns all OpenOrders accounts for a given market with the amounts of unsettled funds
+func (p *Pool) GetUnsettled(ctx context.Context, market string, owner string) (*pb.GetUnsettledResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetUnsettledResponse, error) {
+		return c.GetUnsettled(ctx, market, owner)
+	})
+}
+
+// GetMarkets returns the list of all available named markets
+func (p *Pool) GetMarkets(ctx context.Context) (*pb.GetMarketsResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetMarketsResponse, error) {
+		return c.GetMarkets(ctx)
+	})
+}
+
+// GetAccountBalance returns all tokens associated with the owner address including Serum unsettled amounts
+func (p *Pool) GetAccountBalance(ctx context.Context, owner string) (*pb.GetAccountBalanceResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetAccountBalanceResponse, error) {
+		return c.GetAccountBalance(ctx, owner)
+	})
+}
+
+// GetKline returns the requested market's candlesticks for the given time range and resolution
+func (p *Pool) GetKline(ctx context.Context, market string, from, to time.Time, resolution string, limit uint32) (*pb.GetKlineResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetKlineResponse, error) {
+		return c.GetKline(ctx, market, from, to, resolution, limit)
+	})
+}
+
+// GetOrders returns the owner's orders on the market, filtered by opts
+func (p *Pool) GetOrders(ctx context.Context, market, owner string, opts GetOrdersOpts) (*pb.GetOrdersResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetOrdersResponse, error) {
+		return c.GetOrders(ctx, market, owner, opts)
+	})
+}
+
+// GetOrderByID returns the order with the given ID on the market
+func (p *Pool) GetOrderByID(ctx context.Context, market, orderID string) (*pb.GetOrderByIDResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetOrderByIDResponse, error) {
+		return c.GetOrderByID(ctx, market, orderID)
+	})
+}
+
+// GetServerTime returns the server time of the preferred endpoint
+func (p *Pool) GetServerTime(ctx context.Context) (*pb.GetServerTimeResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.GetServerTimeResponse, error) {
+		return c.GetServerTime(ctx)
+	})
+}
+
+// PostOrder returns a partially signed transaction for placing a Serum market order. Typically, you want to use SubmitOrder instead of this.
+func (p *Pool) PostOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (*pb.PostOrderResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.PostOrderResponse, error) {
+		return c.PostOrder(ctx, owner, payer, market, side, types, amount, price, opts)
+	})
+}
+
+// PostSubmit posts the transaction string to the Solana network, failing over to other endpoints with the same
+// transaction, which can't land twice
+func (p *Pool) PostSubmit(ctx context.Context, txBase64 string, skipPreFlight bool) (*pb.PostSubmitResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.PostSubmitResponse, error) {
+		return c.PostSubmit(ctx, txBase64, skipPreFlight)
+	})
+}
+
+// SubmitOrder builds a Serum market order, signs it, and submits to the network.
+func (p *Pool) SubmitOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error) {
+	return poolSubmit(ctx, p, func(c StreamingClient) (string, error) {
+		return c.SubmitOrder(ctx, owner, payer, market, side, types, amount, price, opts)
+	})
+}
+
+// PostCancelOrder builds a Serum cancel order.
+func (p *Pool) PostCancelOrder(ctx context.Context, orderID string, side pb.Side, owner, market, openOrders string) (*pb.PostCancelOrderResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.PostCancelOrderResponse, error) {
+		return c.PostCancelOrder(ctx, orderID, side, owner, market, openOrders)
+	})
+}
+
+// SubmitCancelOrder builds a Serum cancel order, signs and submits it to the network.
+func (p *Pool) SubmitCancelOrder(ctx context.Context, orderID string, side pb.Side, owner, market, openOrders string, skipPreFlight bool) (string, error) {
+	return poolSubmit(ctx, p, func(c StreamingClient) (string, error) {
+		return c.SubmitCancelOrder(ctx, orderID, side, owner, market, openOrders, skipPreFlight)
+	})
+}
+
+// PostCancelByClientOrderID builds a Serum cancel order by client ID.
+func (p *Pool) PostCancelByClientOrderID(ctx context.Context, clientOrderID uint64, owner, market, openOrders string) (*pb.PostCancelOrderResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.PostCancelOrderResponse, error) {
+		return c.PostCancelByClientOrderID(ctx, clientOrderID, owner, market, openOrders)
+	})
+}
+
+// SubmitCancelByClientOrderID builds a Serum cancel order by client ID, signs and submits it to the network.
+func (p *Pool) SubmitCancelByClientOrderID(ctx context.Context, clientOrderID uint64, owner, market, openOrders string, skipPreFlight bool) (string, error) {
+	return poolSubmit(ctx, p, func(c StreamingClient) (string, error) {
+		return c.SubmitCancelByClientOrderID(ctx, clientOrderID, owner, market, openOrders, skipPreFlight)
+	})
+}
+
+func (p *Pool) PostCancelAll(ctx context.Context, market, owner string, openOrders []string) (*pb.PostCancelAllResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.PostCancelAllResponse, error) {
+		return c.PostCancelAll(ctx, market, owner, openOrders)
+	})
+}
+
+func (p *Pool) SubmitCancelAll(ctx context.Context, market, owner string, openOrders []string, skipPreFlight bool) ([]string, error) {
+	return poolSubmit(ctx, p, func(c StreamingClient) ([]string, error) {
+		return c.SubmitCancelAll(ctx, market, owner, openOrders, skipPreFlight)
+	})
+}
+
+// PostSettle returns a partially signed transaction for settling market funds. Typically, you want to use SubmitSettle instead of this.
+func (p *Pool) PostSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string) (*pb.PostSettleResponse, error) {
+	return poolRequest(ctx, p, func(c StreamingClient) (*pb.PostSettleResponse, error) {
+		return c.PostSettle(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+	})
+}
+
+// SubmitSettle builds a market SubmitSettle transaction, signs it, and submits to the network.
+func (p *Pool) SubmitSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error) {
+	return poolSubmit(ctx, p, func(c StreamingClient) (string, error) {
+		return c.SubmitSettle(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount, skipPreflight)
+	})
+}
+
+// ConfirmTransaction waits until the transaction reaches the requested commitment, using the preferred endpoint's
+// client
+func (p *Pool) ConfirmTransaction(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error) {
+	return poolSubmit(ctx, p, func(c StreamingClient) (*Confirmation, error) {
+		return c.ConfirmTransaction(ctx, signature, opts)
+	})
+}
+
+// GetOrderbooksStream subscribes to a stream for changes to the requested market updates (e.g. asks and bids. Set limit to 0 for all bids/ asks).
+func (p *Pool) GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error {
+	return poolStream(ctx, p, func(ctx context.Context, c StreamingClient, ch chan *pb.GetOrderbooksStreamResponse) error {
+		return c.GetOrderbooksStream(ctx, markets, limit, ch)
+	}, (*pb.GetOrderbooksStreamResponse).GetBlockHeight, func(update *pb.GetOrderbooksStreamResponse) string {
+		return update.GetOrderbook().GetMarket()
+	}, orderbookChan)
+}
+
+// GetTradesStream subscribes to a stream for trades as they execute. Set limit to 0 for all trades.
+func (p *Pool) GetTradesStream(ctx context.Context, market string, limit uint32, tradesChan chan *pb.GetTradesStreamResponse) error {
+	return poolStream(ctx, p, func(ctx context.Context, c StreamingClient, ch chan *pb.GetTradesStreamResponse) error {
+		return c.GetTradesStream(ctx, market, limit, ch)
+	}, (*pb.GetTradesStreamResponse).GetBlockHeight, func(*pb.GetTradesStreamResponse) string {
+		return ""
+	}, tradesChan)
+}
+
+// GetOrderStatusStream subscribes to a stream that shows updates to the owner's orders
+func (p *Pool) GetOrderStatusStream(ctx context.Context, market, ownerAddress string, statusUpdateChan chan *pb.GetOrderStatusStreamResponse) error {
+	return poolStream(ctx, p, func(ctx context.Context, c StreamingClient, ch chan *pb.GetOrderStatusStreamResponse) error {
+		return c.GetOrderStatusStream(ctx, market, ownerAddress, ch)
+	}, (*pb.GetOrderStatusStreamResponse).GetBlockHeight, func(update *pb.GetOrderStatusStreamResponse) string {
+		return update.GetOrderInfo().GetOrderID() + "/" + update.GetOrderInfo().GetOrderStatus().String()
+	}, statusUpdateChan)
+}
+
+// GetTickersStream subscribes to a stream for getting recent tickers of specified market.
+func (p *Pool) GetTickersStream(ctx context.Context, market string, tickersChan chan *pb.GetTickersStreamResponse) error {
+	return poolStream(ctx, p, func(ctx context.Context, c StreamingClient, ch chan *pb.GetTickersStreamResponse) error {
+		return c.GetTickersStream(ctx, market, ch)
+	}, (*pb.GetTickersStreamResponse).GetBlockHeight, func(*pb.GetTickersStreamResponse) string {
+		return ""
+	}, tickersChan)
+}
+
+// GetMarketDepthStream subscribes to a stream for changes to the market depth
+func (p *Pool) GetMarketDepthStream(ctx context.Context, depthChan chan *pb.GetMarketDepthStreamResponse) error {
+	return poolStream(ctx, p, func(ctx context.Context, c StreamingClient, ch chan *pb.GetMarketDepthStreamResponse) error {
+		return c.GetMarketDepthStream(ctx, ch)
+	}, (*pb.GetMarketDepthStreamResponse).GetBlockHeight, func(*pb.GetMarketDepthStreamResponse) string {
+		return ""
+	}, depthChan)
+}
+
+// Close stops the health checks and closes every member's client
+func (p *Pool) Close() error {
+	p.cancel()
+	p.wg.Wait()
+
+	var err error
+	for _, member := range p.members {
+		if closeErr := member.Client.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"google.golang.org/grpc"
+)
+
+// ErrClientRateLimited is the cause of the requests rejected by a RateLimiter. They match bxerrors.ErrRateLimited,
+// but aren't retried since they haven't reached the server.
+var ErrClientRateLimited = errors.New("client side rate limit exceeded")
+
+// MethodClass groups the API methods that share a rate limit
+type MethodClass int
+
+const (
+	// ReadMethods are the Get* methods, including stream subscriptions
+	ReadMethods MethodClass = iota
+	// OrderMethods are the Post* methods that build transactions: PostOrder, PostCancel*, PostSettle
+	OrderMethods
+	// SubmitMethods is PostSubmit, which the Submit* methods also use
+	SubmitMethods
+)
+
+func (c MethodClass) String() string {
+	switch c {
+	case ReadMethods:
+		return "reads"
+	case OrderMethods:
+		return "orders"
+	case SubmitMethods:
+		return "submissions"
+	default:
+		return fmt.Sprintf("method class %d", int(c))
+	}
+}
+
+func methodClass(method string) MethodClass {
+	switch {
+	case method == "PostSubmit":
+		return SubmitMethods
+	case strings.HasPrefix(method, "Post"):
+		return OrderMethods
+	default:
+		return ReadMethods
+	}
+}
+
+// RateLimit is a token bucket: requests are allowed at Rate per second on average, in bursts of up to Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitOpts configures a RateLimiter. Classes without a limit aren't limited.
+type RateLimitOpts struct {
+	Reads       *RateLimit
+	Orders      *RateLimit
+	Submissions *RateLimit
+	// FailFast rejects requests over the limit with an error matching bxerrors.ErrRateLimited instead of waiting
+	FailFast bool
+}
+
+// RateLimitStats counts the requests of a method class that went through a RateLimiter
+type RateLimitStats struct {
+	Requests int64
+	// Delayed is the number of requests that waited for a token, and WaitTime the total time they waited
+	Delayed  int64
+	WaitTime time.Duration
+	// Rejected is the number of requests that failed because of the limit, in fail fast mode or because they couldn't
+	// get a token before their context deadline
+	Rejected int64
+}
+
+// RateLimiter limits the rate of requests per method class. Set it in RPCOpts.RateLimiter, and share it between clients
+// that share a quota.
+type RateLimiter struct {
+	failFast bool
+	buckets  map[MethodClass]*tokenBucket
+
+	m     sync.Mutex
+	stats map[MethodClass]RateLimitStats
+}
+
+func NewRateLimiter(opts RateLimitOpts) *RateLimiter {
+	l := &RateLimiter{
+		failFast: opts.FailFast,
+		buckets:  make(map[MethodClass]*tokenBucket),
+		stats:    make(map[MethodClass]RateLimitStats),
+	}
+	for class, limit := range map[MethodClass]*RateLimit{ReadMethods: opts.Reads, OrderMethods: opts.Orders, SubmitMethods: opts.Submissions} {
+		if limit != nil && limit.Rate > 0 {
+			l.buckets[class] = newTokenBucket(*limit)
+		}
+	}
+	return l
+}
+
+// Stats returns the counters of the method class
+func (l *RateLimiter) Stats(class MethodClass) RateLimitStats {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.stats[class]
+}
+
+// Wait takes a token for the method, waiting for one unless in fail fast mode. It fails without waiting if no token is
+// available before the context deadline.
+func (l *RateLimiter) Wait(ctx context.Context, method string) error {
+	if l == nil {
+		return nil
+	}
+	class := methodClass(method)
+	bucket, ok := l.buckets[class]
+	if !ok {
+		l.record(class, 0, false)
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	maxWait := time.Duration(-1)
+	if l.failFast {
+		maxWait = 0
+	} else if deadline, ok := ctx.Deadline(); ok {
+		// a negative wait means there is no limit
+		if maxWait = time.Until(deadline); maxWait <= 0 {
+			return context.DeadlineExceeded
+		}
+	}
+	wait, ok := bucket.reserve(time.Now(), maxWait)
+	if !ok {
+		l.record(class, 0, true)
+		cause := fmt.Errorf("%w for %v", ErrClientRateLimited, class)
+		if !l.failFast {
+			cause = fmt.Errorf("%w: no token for %v before the context deadline", ErrClientRateLimited, class)
+		}
+		return &bxerrors.Error{Kind: bxerrors.ErrRateLimited, Message: cause.Error(), Cause: cause}
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			bucket.cancel()
+			l.record(class, 0, true)
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	l.record(class, wait, false)
+	return nil
+}
+
+func (l *RateLimiter) record(class MethodClass, wait time.Duration, rejected bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	stats := l.stats[class]
+	stats.Requests++
+	if wait > 0 {
+		stats.Delayed++
+		stats.WaitTime += wait
+	}
+	if rejected {
+		stats.Rejected++
+	}
+	l.stats[class] = stats
+}
+
+// grpcInterceptor limits the unary GRPC calls
+func (l *RateLimiter) grpcInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := l.Wait(ctx, method[strings.LastIndex(method, "/")+1:]); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// grpcStreamInterceptor limits the GRPC stream subscriptions
+func (l *RateLimiter) grpcStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := l.Wait(ctx, method[strings.LastIndex(method, "/")+1:]); err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	m      sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait until it's available. Tokens that would take longer than maxWait
+// aren't taken (a negative maxWait waits as long as needed).
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	if maxWait >= 0 && wait > maxWait {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel returns a reserved token that won't be used
+func (b *tokenBucket) cancel() {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 2 * time.Second
+	defaultRetryMultiplier = 2
+	defaultRetryJitter     = 0.2
+)
+
+// RetryPolicy retries the read-only requests (the Get* methods other than streams) that fail with a transient error
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a request, including the first one
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each failed attempt
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction (e.g. 0.2 for +/-20%)
+	Jitter float64
+
+	// Retryable decides which errors are retried, defaults to IsRetryable
+	Retryable func(error) bool
+	// RetrySubmit also retries PostSubmit (and so the Submit* methods). The same signed transaction is sent again, so
+	// it can't land twice: if an earlier attempt did land, its signature is returned.
+	RetrySubmit bool
+	// OnAttempt is called after every attempt
+	OnAttempt func(RetryAttempt)
+}
+
+// RetryAttempt describes an attempt of a request
+type RetryAttempt struct {
+	Method string
+	// Attempt counts from 1
+	Attempt int
+	Err     error
+	// Backoff is the wait before the next attempt, 0 if the request isn't retried
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy makes 3 attempts with exponential backoff from 100ms up to 2s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    defaultRetryAttempts,
+		InitialBackoff: defaultRetryBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		Jitter:         defaultRetryJitter,
+	}
+}
+
+// IsRetryable reports whether err is transient: the transport was closed, the request timed out or was rate limited by
+// the server, or the server aborted it
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrClientRateLimited) {
+		return false
+	}
+	if errors.Is(err, bxerrors.ErrTransportClosed) || errors.Is(err, bxerrors.ErrTimeout) || errors.Is(err, bxerrors.ErrRateLimited) {
+		return true
+	}
+	return status.Code(err) == codes.Aborted
+}
+
+// retrier applies a RetryPolicy. A nil retrier makes a single attempt.
+type retrier struct {
+	policy RetryPolicy
+}
+
+func newRetrier(opts RPCOpts) *retrier {
+	if opts.Retry == nil || opts.Retry.MaxAttempts <= 1 {
+		return nil
+	}
+	r := &retrier{policy: *opts.Retry}
+	if r.policy.Retryable == nil {
+		r.policy.Retryable = IsRetryable
+	}
+	return r
+}
+
+func (r *retrier) do(ctx context.Context, method string, fn func(ctx context.Context) error) error {
+	if r == nil {
+		return fn(ctx)
+	}
+
+	backoff := connections.ReconnectPolicy{
+		InitialBackoff: r.policy.InitialBackoff,
+		MaxBackoff:     r.policy.MaxBackoff,
+		Multiplier:     r.policy.Multiplier,
+		Jitter:         r.policy.Jitter,
+	}
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+
+		var wait time.Duration
+		retry := err != nil && attempt < r.policy.MaxAttempts && ctx.Err() == nil && r.policy.Retryable(err)
+		if retry {
+			wait = backoff.Backoff(attempt)
+		}
+		if r.policy.OnAttempt != nil {
+			r.policy.OnAttempt(RetryAttempt{Method: method, Attempt: attempt, Err: err, Backoff: wait})
+		}
+		if !retry {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// readOnly retries the Get* methods
+func (r *retrier) readOnly(ctx context.Context, method string, fn func(ctx context.Context) error) error {
+	if !strings.HasPrefix(method, "Get") {
+		return fn(ctx)
+	}
+	return r.do(ctx, method, fn)
+}
+
+// submit retries PostSubmit if enabled. Retries that find the transaction already processed succeed with its signature.
+func (r *retrier) submit(ctx context.Context, txBase64 string, fn func(ctx context.Context) (*pb.PostSubmitResponse, error)) (*pb.PostSubmitResponse, error) {
+	if r == nil || !r.policy.RetrySubmit {
+		return fn(ctx)
+	}
+
+	var response *pb.PostSubmitResponse
+	attempt := 0
+	err := r.do(ctx, "PostSubmit", func(ctx context.Context) error {
+		attempt++
+		var err error
+		response, err = fn(ctx)
+		if err != nil && attempt > 1 && errors.Is(err, bxerrors.ErrAlreadyProcessed) {
+			if signature, sigErr := txSignature(txBase64); sigErr == nil {
+				response, err = &pb.PostSubmitResponse{Signature: signature}, nil
+			}
+		}
+		return err
+	})
+	return response, err
+}
+
+// grpcInterceptor retries the read-only GRPC calls
+func (r *retrier) grpcInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return r.readOnly(ctx, method[strings.LastIndex(method, "/")+1:], func(ctx context.Context) error {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+}
+
+// txSignature returns the signature a transaction is identified by on chain
+func txSignature(txBase64 string) (string, error) {
+	txBytes, err := solanarpc.DataBytesOrJSONFromBase64(txBase64)
+	if err != nil {
+		return "", err
+	}
+	tx, err := (&solanarpc.TransactionWithMeta{Transaction: txBytes}).GetTransaction()
+	if err != nil {
+		return "", err
+	}
+	if len(tx.Signatures) == 0 {
+		return "", errors.New("transaction is not signed")
+	}
+	return tx.Signatures[0].String(), nil
+}
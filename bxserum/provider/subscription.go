@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// SubscriptionBuffer is the number of updates the Updates channel of the Subscribe* methods holds
+const SubscriptionBuffer = 100
+
+var (
+	// ErrStreamEnded is the cause of subscriptions to streams the server ended
+	ErrStreamEnded = errors.New("stream ended by the server")
+	// ErrSlowSubscriber is the cause of subscriptions ended by OverflowDisconnect
+	ErrSlowSubscriber = errors.New("stream subscriber disconnected for not keeping up")
+)
+
+// Subscription is a stream subscription returned by the Subscribe* methods. Updates arrive on Updates until the stream
+// ends, then Done is closed and Err tells why it ended:
+//   - the subscription's context error (context.Canceled or context.DeadlineExceeded) once the context is done
+//   - ErrStreamEnded if the server ended the stream
+//   - ErrSlowSubscriber if the overflow policy disconnected the subscriber
+//   - the transport error otherwise, e.g. one matching bxerrors.ErrTransportClosed when the connection is lost
+type Subscription[T any] struct {
+	ctx     context.Context
+	updates chan T
+	done    chan struct{}
+	err     error
+	once    sync.Once
+}
+
+func newSubscription[T any](ctx context.Context, updates chan T) *Subscription[T] {
+	return &Subscription[T]{ctx: ctx, updates: updates, done: make(chan struct{})}
+}
+
+// NewSubscription returns a Subscription to the updates of generator, which is called until it fails. The subscription
+// ends with its error, or ErrStreamEnded if it's io.EOF, so that other sources of stream updates can be consumed like
+// the clients' streams.
+func NewSubscription[T any](ctx context.Context, generator func() (T, error)) *Subscription[T] {
+	sub := newSubscription(ctx, make(chan T, SubscriptionBuffer))
+	go runStream(generator, sub)
+	return sub
+}
+
+// subscribe opens a Subscription with a SubscriptionBuffer channel
+func subscribe[T any](ctx context.Context, open func(sub *Subscription[T]) error) (*Subscription[T], error) {
+	sub := newSubscription(ctx, make(chan T, SubscriptionBuffer))
+	if err := open(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Updates returns the channel of stream updates, which is closed once the stream ends
+func (s *Subscription[T]) Updates() <-chan T {
+	return s.updates
+}
+
+// Done is closed once the stream ends
+func (s *Subscription[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the stream ended, or nil while it hasn't
+func (s *Subscription[T]) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Next waits for the next update. It returns Err once the stream has ended and its updates were read, or the error of
+// ctx if it's done first.
+func (s *Subscription[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	select {
+	case update, ok := <-s.updates:
+		if !ok {
+			return zero, s.err
+		}
+		return update, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// end closes the updates channel with the cause of the end of the stream. Only the goroutine sending the updates may
+// end the subscription.
+func (s *Subscription[T]) end(err error) {
+	s.once.Do(func() {
+		// streams fail in transport specific ways once their context is done
+		switch {
+		case s.ctx.Err() != nil:
+			err = s.ctx.Err()
+		case errors.Is(err, io.EOF):
+			err = ErrStreamEnded
+		}
+		s.err = err
+		close(s.updates)
+		close(s.done)
+	})
+}
+
+// runStream sends the updates of generator to sub until it fails
+func runStream[T any](generator func() (T, error), sub *Subscription[T]) {
+	for {
+		update, err := generator()
+		if err != nil {
+			sub.end(err)
+			return
+		}
+		sub.updates <- update
+	}
+}
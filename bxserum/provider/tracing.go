@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const tracerName = "github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+
+// TracingOpts configures the OpenTelemetry spans of a client. Every request gets a client span named after its method
+// (e.g. PostOrder or PostSubmit), and the Submit* methods add a parent span for the whole call with SignTransaction and
+// ConfirmTransaction spans for the local steps. The trace context is sent in GRPC metadata, HTTP headers and the
+// "meta" field of websocket JSON-RPC requests.
+type TracingOpts struct {
+	// TracerProvider creates the spans, defaults to the global otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+	// Propagator encodes the trace context into requests, defaults to W3C trace context headers
+	Propagator propagation.TextMapPropagator
+}
+
+// tracer creates the spans of a client: a nil tracer creates none and propagates nothing
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newTracer(opts RPCOpts) *tracer {
+	if opts.Tracing == nil {
+		return nil
+	}
+
+	provider := opts.Tracing.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := opts.Tracing.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &tracer{tracer: provider.Tracer(tracerName), propagator: propagator}
+}
+
+// start begins a span as a child of any span in ctx. The returned function ends it, recording err if not nil.
+func (t *tracer) start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	if t == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// request runs fn in a client span for the method
+func (t *tracer) request(ctx context.Context, transport bxerrors.Transport, method string, fn func(ctx context.Context) error) error {
+	ctx, end := t.start(ctx, method, trace.SpanKindClient,
+		attribute.String("rpc.system", "serum-api"),
+		attribute.String("rpc.method", method),
+		attribute.String("serum.transport", string(transport)),
+	)
+	err := fn(ctx)
+	end(err)
+	return err
+}
+
+// traced runs fn in an internal span, e.g. for a step of a Submit* method
+func traced[T any](ctx context.Context, t *tracer, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, end := t.start(ctx, name, trace.SpanKindInternal)
+	result, err := fn(ctx)
+	end(err)
+	return result, err
+}
+
+// wsMeta is the websocket JSON-RPC meta field carrying the trace context of ctx
+func (t *tracer) wsMeta(ctx context.Context) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	carrier := propagation.MapCarrier{}
+	t.propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// httpClient returns a copy of client that sends the trace context of each request in its headers
+func (t *tracer) httpClient(client *http.Client) *http.Client {
+	if t == nil {
+		return client
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = tracingTransport{base: transport, propagator: t.propagator}
+	return &c
+}
+
+type tracingTransport struct {
+	base       http.RoundTripper
+	propagator propagation.TextMapPropagator
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// round trippers must not modify the request
+	req = req.Clone(req.Context())
+	t.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return t.base.RoundTrip(req)
+}
+
+// grpcOutgoingContext adds the trace context of ctx to the outgoing GRPC metadata
+func (t *tracer) grpcOutgoingContext(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{}
+	t.propagator.Inject(ctx, carrier)
+	for key, value := range carrier {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+	return ctx
+}
+
+func (t *tracer) grpcInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if t == nil {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return t.request(ctx, bxerrors.TransportGRPC, method[strings.LastIndex(method, "/")+1:], func(ctx context.Context) error {
+		return invoker(t.grpcOutgoingContext(ctx), method, req, reply, cc, opts...)
+	})
+}
+
+// grpcStreamInterceptor traces the subscription request of streams, which carries the trace context to the server
+func (t *tracer) grpcStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if t == nil {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	var stream grpc.ClientStream
+	err := t.request(ctx, bxerrors.TransportGRPC, method[strings.LastIndex(method, "/")+1:], func(ctx context.Context) error {
+		var err error
+		stream, err = streamer(t.grpcOutgoingContext(ctx), desc, cc, method, opts...)
+		return err
+	})
+	return stream, err
+}
@@ -0,0 +1,363 @@
+package provider
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+)
+
+var (
+	ErrInvalidSide       = errors.New("side must be bid or ask")
+	ErrInvalidOrderTypes = errors.New("order types must include exactly one of market or limit, and at most one of IOC or post")
+	ErrInvalidPrice      = errors.New("price must be positive")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+	ErrInvalidAddress    = errors.New("address must be a base58 public key")
+	ErrPriceNotOnTick    = errors.New("price must be a multiple of the market tick size")
+	ErrAmountNotOnLot    = errors.New("amount must be a multiple of the market lot size")
+)
+
+const (
+	// serum market accounts (v2) start with 5 bytes of padding and the account flags
+	serumMarketLength       = 388
+	serumMarketBaseMint     = 53
+	serumMarketQuoteMint    = 85
+	serumMarketBaseLotSize  = 349
+	serumMarketQuoteLotSize = 357
+
+	// tolerance for float imprecision when checking multiples of tick and lot sizes
+	sizeTolerance = 1e-9
+
+	defaultSpecFailureTTL = 30 * time.Second
+)
+
+// ValidationError is returned when an order fails client side validation, before any request is sent. Err is one of
+// the ErrInvalid*, ErrPriceNotOnTick or ErrAmountNotOnLot errors.
+type ValidationError struct {
+	Field string
+	Value interface{}
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %v %v: %v", e.Field, e.Value, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches bxerrors.ErrInvalidAddress for invalid addresses, so they are handled like those rejected by the server
+func (e *ValidationError) Is(target error) bool {
+	return e.Err == ErrInvalidAddress && target == bxerrors.ErrInvalidAddress
+}
+
+// MarketSpec is the tick size (price increment) and lot size (amount increment) of a market, in token units
+type MarketSpec struct {
+	TickSize float64
+	LotSize  float64
+}
+
+// OrderValidation enables client side validation of orders (see RPCOpts.OrderValidation)
+type OrderValidation struct {
+	// Markets provides the specs of markets by name or address. Specs of other markets are loaded from chain and
+	// cached when RPCOpts.SolanaRPCEndpoint is set, otherwise their prices and amounts aren't checked.
+	Markets map[string]MarketSpec
+	// Round rounds prices to the tick size, away from the other side of the book, and amounts down to the lot size
+	// instead of rejecting them
+	Round bool
+	// SpecFailureTTL is how long a failure to load the specs of a market is returned to orders on that market before
+	// the specs are loaded again, defaults to 30s
+	SpecFailureTTL time.Duration
+}
+
+// ValidatePostOrderRequest checks the order types, side, price, amount and addresses of the request and, if spec is
+// provided, that its price and amount are multiples of the market tick and lot sizes. With round set, the price and
+// amount are rounded to them instead.
+func ValidatePostOrderRequest(request *pb.PostOrderRequest, spec *MarketSpec, round bool) error {
+	if err := validateOrderFields(request); err != nil {
+		return err
+	}
+	if spec == nil {
+		return nil
+	}
+	return applyMarketSpec(request, *spec, round)
+}
+
+func validateOrderFields(request *pb.PostOrderRequest) error {
+	if err := validateOrderTypes(request.Type); err != nil {
+		return err
+	}
+	if request.Side != pb.Side_S_BID && request.Side != pb.Side_S_ASK {
+		return &ValidationError{Field: "side", Value: request.Side, Err: ErrInvalidSide}
+	}
+	if !(request.Price > 0) || math.IsInf(request.Price, 0) {
+		return &ValidationError{Field: "price", Value: request.Price, Err: ErrInvalidPrice}
+	}
+	if !(request.Amount > 0) || math.IsInf(request.Amount, 0) {
+		return &ValidationError{Field: "amount", Value: request.Amount, Err: ErrInvalidAmount}
+	}
+
+	if err := validateAddress("owner", request.OwnerAddress); err != nil {
+		return err
+	}
+	if err := validateAddress("payer", request.PayerAddress); err != nil {
+		return err
+	}
+	if request.OpenOrdersAddress != "" {
+		if err := validateAddress("open orders address", request.OpenOrdersAddress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMarketSpec(request *pb.PostOrderRequest, spec MarketSpec, round bool) error {
+	if spec.TickSize > 0 {
+		price, ok := roundToMultiple(request.Price, spec.TickSize, request.Side == pb.Side_S_ASK)
+		switch {
+		case ok:
+		case round && price > 0:
+			request.Price = price
+		default:
+			return &ValidationError{Field: "price", Value: request.Price, Err: ErrPriceNotOnTick}
+		}
+	}
+	if spec.LotSize > 0 {
+		amount, ok := roundToMultiple(request.Amount, spec.LotSize, false)
+		switch {
+		case ok:
+		case round && amount > 0:
+			request.Amount = amount
+		default:
+			return &ValidationError{Field: "amount", Value: request.Amount, Err: ErrAmountNotOnLot}
+		}
+	}
+	return nil
+}
+
+func validateAddress(field, address string) error {
+	if _, err := solana.PublicKeyFromBase58(address); err != nil {
+		return &ValidationError{Field: field, Value: address, Err: ErrInvalidAddress}
+	}
+	return nil
+}
+
+func validateOrderTypes(types []pb.OrderType) error {
+	seen := make(map[pb.OrderType]bool)
+	for _, t := range types {
+		if _, ok := pb.OrderType_name[int32(t)]; !ok || seen[t] {
+			return &ValidationError{Field: "order types", Value: types, Err: ErrInvalidOrderTypes}
+		}
+		seen[t] = true
+	}
+
+	market, limit := seen[pb.OrderType_OT_MARKET], seen[pb.OrderType_OT_LIMIT]
+	ioc, post := seen[pb.OrderType_OT_IOC], seen[pb.OrderType_OT_POST]
+	if market == limit || (ioc && post) || (market && post) {
+		return &ValidationError{Field: "order types", Value: types, Err: ErrInvalidOrderTypes}
+	}
+	return nil
+}
+
+// roundToMultiple returns value rounded to a multiple of step, up if roundUp is set and down otherwise, and whether
+// value already was a multiple
+func roundToMultiple(value, step float64, roundUp bool) (float64, bool) {
+	steps := value / step
+	nearest := math.Round(steps)
+	if math.Abs(steps-nearest) <= sizeTolerance*math.Max(1, nearest) {
+		return value, true
+	}
+
+	rounded := math.Floor(steps)
+	if roundUp {
+		rounded = math.Ceil(steps)
+	}
+	// drop the float noise of the multiplication beyond the precision of step
+	decimals := 0
+	if formatted := strconv.FormatFloat(step, 'f', -1, 64); strings.Contains(formatted, ".") {
+		decimals = len(formatted) - strings.Index(formatted, ".") - 1
+	}
+	result, _ := strconv.ParseFloat(strconv.FormatFloat(rounded*step, 'f', decimals, 64), 64)
+	return result, false
+}
+
+// orderValidator validates orders before they are sent, caching the market specs it loads
+type orderValidator struct {
+	opts       OrderValidation
+	rpcClient  *solanarpc.Client
+	getMarkets func(ctx context.Context) (*pb.GetMarketsResponse, error)
+
+	m     sync.Mutex
+	specs map[string]*specLoad
+}
+
+// specLoad is the load of the specs of a market, which orders on the market wait for. Its results are set once done
+// is closed.
+type specLoad struct {
+	done     chan struct{}
+	spec     *MarketSpec
+	err      error
+	failedAt time.Time
+	// canceled is set when the load failed because the context of the order that started it ended
+	canceled bool
+}
+
+// newOrderValidator returns nil if validation isn't enabled, which skips validation
+func newOrderValidator(opts RPCOpts, getMarkets func(ctx context.Context) (*pb.GetMarketsResponse, error)) *orderValidator {
+	if opts.OrderValidation == nil {
+		return nil
+	}
+
+	v := &orderValidator{opts: *opts.OrderValidation, getMarkets: getMarkets, specs: make(map[string]*specLoad)}
+	if v.opts.SpecFailureTTL == 0 {
+		v.opts.SpecFailureTTL = defaultSpecFailureTTL
+	}
+	if opts.SolanaRPCEndpoint != "" {
+		v.rpcClient = solanarpc.New(opts.SolanaRPCEndpoint)
+	}
+	return v
+}
+
+func (v *orderValidator) validate(ctx context.Context, request *pb.PostOrderRequest) error {
+	if v == nil {
+		return nil
+	}
+
+	// fields are checked first, since loading market specs may take requests
+	if err := validateOrderFields(request); err != nil {
+		return err
+	}
+	spec, err := v.spec(ctx, request.Market)
+	if err != nil || spec == nil {
+		return err
+	}
+	return applyMarketSpec(request, *spec, v.opts.Round)
+}
+
+// spec returns the specs of the market, nil if they aren't known and can't be loaded. Specs are loaded once for all
+// the orders on a market, and failures are returned until SpecFailureTTL has passed.
+func (v *orderValidator) spec(ctx context.Context, market string) (*MarketSpec, error) {
+	for name, spec := range v.opts.Markets {
+		if name == market || normalizeMarketName(name) == normalizeMarketName(market) {
+			spec := spec
+			return &spec, nil
+		}
+	}
+	if v.rpcClient == nil {
+		return nil, nil
+	}
+
+	for {
+		v.m.Lock()
+		load, ok := v.specs[market]
+		if !ok || (load.err != nil && time.Since(load.failedAt) >= v.opts.SpecFailureTTL) {
+			load = &specLoad{done: make(chan struct{})}
+			v.specs[market] = load
+			v.m.Unlock()
+
+			v.load(ctx, market, load)
+			return load.spec, load.err
+		}
+		v.m.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-load.done:
+		}
+		if !load.canceled {
+			return load.spec, load.err
+		}
+	}
+}
+
+// load loads the specs of market and sets the results of load. The mutex must not be held, so that orders on other
+// markets aren't held up by the requests.
+func (v *orderValidator) load(ctx context.Context, market string, load *specLoad) {
+	address, err := v.marketAddress(ctx, market)
+	var spec *MarketSpec
+	if err == nil {
+		spec, err = v.loadSpec(ctx, address)
+		if err != nil {
+			err = fmt.Errorf("failed to load specs of market %v: %w", market, err)
+		}
+	}
+
+	v.m.Lock()
+	load.spec, load.err = spec, err
+	if err != nil {
+		load.failedAt = time.Now()
+		// the end of the order's context says nothing about the market, so the next order loads the specs again
+		if ctx.Err() != nil {
+			load.canceled = true
+			delete(v.specs, market)
+		}
+	}
+	v.m.Unlock()
+	close(load.done)
+}
+
+// marketAddress resolves market names with GetMarkets
+func (v *orderValidator) marketAddress(ctx context.Context, market string) (solana.PublicKey, error) {
+	if address, err := solana.PublicKeyFromBase58(market); err == nil {
+		return address, nil
+	}
+
+	markets, err := v.getMarkets(ctx)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	for name, m := range markets.Markets {
+		if normalizeMarketName(name) == normalizeMarketName(market) {
+			return solana.PublicKeyFromBase58(m.Address)
+		}
+	}
+	return solana.PublicKey{}, fmt.Errorf("market %v not found", market)
+}
+
+// loadSpec reads the lot sizes of the Serum market account and the decimals of its mints
+func (v *orderValidator) loadSpec(ctx context.Context, address solana.PublicKey) (*MarketSpec, error) {
+	account, err := v.rpcClient.GetAccountInfo(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	data := account.Value.Data.GetBinary()
+	if len(data) != serumMarketLength {
+		return nil, fmt.Errorf("unsupported market account length %v", len(data))
+	}
+
+	baseMint := solana.PublicKeyFromBytes(data[serumMarketBaseMint : serumMarketBaseMint+32])
+	quoteMint := solana.PublicKeyFromBytes(data[serumMarketQuoteMint : serumMarketQuoteMint+32])
+	baseLotSize := binary.LittleEndian.Uint64(data[serumMarketBaseLotSize:])
+	quoteLotSize := binary.LittleEndian.Uint64(data[serumMarketQuoteLotSize:])
+
+	var base, quote token.Mint
+	if err := v.rpcClient.GetAccountDataInto(ctx, baseMint, &base); err != nil {
+		return nil, fmt.Errorf("failed to get base mint: %w", err)
+	}
+	if err := v.rpcClient.GetAccountDataInto(ctx, quoteMint, &quote); err != nil {
+		return nil, fmt.Errorf("failed to get quote mint: %w", err)
+	}
+
+	baseMultiplier, quoteMultiplier := math.Pow10(int(base.Decimals)), math.Pow10(int(quote.Decimals))
+	return &MarketSpec{
+		TickSize: float64(quoteLotSize) * baseMultiplier / (float64(baseLotSize) * quoteMultiplier),
+		LotSize:  float64(baseLotSize) / baseMultiplier,
+	}, nil
+}
+
+func normalizeMarketName(market string) string {
+	return strings.ToUpper(strings.NewReplacer("/", "", "-", "", ":", "").Replace(market))
+}
@@ -4,17 +4,28 @@ import (
 	"context"
 	"errors"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/metrics"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
 	pb "github.com/bloXroute-Labs/serum-client-go/proto"
-	"github.com/gagliardetto/solana-go"
+	"time"
 )
 
 type WSClient struct {
 	pb.UnimplementedApiServer
 
-	addr       string
-	conn       *connections.WS
-	privateKey *solana.PrivateKey
+	addr        string
+	conn        *connections.WS
+	signer      transaction.Signer
+	confirmer   *confirmer
+	validator   *orderValidator
+	retrier     *retrier
+	rateLimiter *RateLimiter
+	metrics     metrics.Metrics
+	tracer      *tracer
+	log         logger.Logger
+	streams     *streamManager
 }
 
 // NewWSClient connects to Mainnet Serum API
@@ -31,22 +42,39 @@ func NewWSClientTestnet() (*WSClient, error) {
 
 // NewWSClientWithOpts connects to custom Serum API
 func NewWSClientWithOpts(opts RPCOpts) (*WSClient, error) {
-	conn, err := connections.NewWS(opts.Endpoint)
+	tracer := newTracer(opts)
+	log := opts.logger(bxerrors.TransportWS)
+	conn, err := connections.NewWSWithOpts(opts.Endpoint, connections.WSOpts{
+		Reconnect:         opts.WSReconnect,
+		OnConnectionEvent: opts.OnWSConnectionEvent,
+		OnWriteQueueDepth: opts.metrics().SetWSWriteQueueDepth,
+		RequestMeta:       tracer.wsMeta,
+		Logger:            log,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &WSClient{
-		addr:       opts.Endpoint,
-		conn:       conn,
-		privateKey: opts.PrivateKey,
-	}, nil
+	w := &WSClient{
+		addr:        opts.Endpoint,
+		conn:        conn,
+		signer:      opts.signer(),
+		confirmer:   newConfirmer(opts),
+		retrier:     newRetrier(opts),
+		rateLimiter: opts.RateLimiter,
+		metrics:     opts.metrics(),
+		tracer:      tracer,
+		log:         log.With(logger.Fields{"endpoint": opts.Endpoint}),
+	}
+	w.validator = newOrderValidator(opts, w.GetMarkets)
+	w.streams = newStreamManager(opts, w.log)
+	return w, nil
 }
 
 // GetOrderbook returns the requested market's orderbook (e.g. asks and bids). Set limit to 0 for all bids / asks.
 func (w *WSClient) GetOrderbook(ctx context.Context, market string, limit uint32) (*pb.GetOrderbookResponse, error) {
 	var response pb.GetOrderbookResponse
-	err := w.conn.Request(ctx, "GetOrderbook", &pb.GetOrderbookRequest{Market: market, Limit: limit}, &response)
+	err := w.request(ctx, "GetOrderbook", &pb.GetOrderbookRequest{Market: market, Limit: limit}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -55,34 +83,30 @@ func (w *WSClient) GetOrderbook(ctx context.Context, market string, limit uint32
 
 // GetOrderbooksStream subscribes to a stream for changes to the requested market updates (e.g. asks and bids. Set limit to 0 for all bids/ asks).
 func (w *WSClient) GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error {
-	generator, err := connections.WSStream(w.conn, ctx, "GetOrderbooksStream", &pb.GetOrderbooksRequest{
-		Markets: markets,
-		Limit:   limit,
-	}, func() *pb.GetOrderbooksStreamResponse {
-		var v pb.GetOrderbooksStreamResponse
-		return &v
+	return w.orderbooksStream(ctx, markets, limit, newSubscription(ctx, orderbookChan))
+}
+
+// SubscribeOrderbooks is GetOrderbooksStream returning a Subscription, which tells why the stream ended
+func (w *WSClient) SubscribeOrderbooks(ctx context.Context, markets []string, limit uint32) (*Subscription[*pb.GetOrderbooksStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+		return w.orderbooksStream(ctx, markets, limit, sub)
 	})
-	if err != nil {
-		return err
-	}
+}
 
-	go func() {
-		for {
-			result, err := generator()
-			if err != nil {
-				close(orderbookChan)
-				return
-			}
-			orderbookChan <- result
-		}
-	}()
-	return nil
+func (w *WSClient) orderbooksStream(ctx context.Context, markets []string, limit uint32, sub *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+	request := &pb.GetOrderbooksRequest{Markets: markets, Limit: limit}
+	return shareStream(w.streams, ctx, "GetOrderbooksStream", request, sub, orderbookMarket, func(ctx context.Context, feed *Subscription[*pb.GetOrderbooksStreamResponse]) error {
+		return wsSubscribe(w, ctx, "GetOrderbooksStream", request, feed, func() *pb.GetOrderbooksStreamResponse {
+			var v pb.GetOrderbooksStreamResponse
+			return &v
+		})
+	})
 }
 
 // GetTrades returns the requested market's currently executing trades. Set limit to 0 for all trades.
 func (w *WSClient) GetTrades(ctx context.Context, market string, limit uint32) (*pb.GetTradesResponse, error) {
 	var response pb.GetTradesResponse
-	err := w.conn.Request(ctx, "GetTrades", &pb.GetTradesRequest{Market: market, Limit: limit}, &response)
+	err := w.request(ctx, "GetTrades", &pb.GetTradesRequest{Market: market, Limit: limit}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -91,62 +115,96 @@ func (w *WSClient) GetTrades(ctx context.Context, market string, limit uint32) (
 
 // GetTradesStream subscribes to a stream for trades as they execute. Set limit to 0 for all trades.
 func (w *WSClient) GetTradesStream(ctx context.Context, market string, limit uint32, tradesChan chan *pb.GetTradesStreamResponse) error {
-	generator, err := connections.WSStream(w.conn, ctx, "GetTradesStream", &pb.GetTradesRequest{
-		Market: market,
-		Limit:  limit,
-	}, func() *pb.GetTradesStreamResponse {
-		var v pb.GetTradesStreamResponse
-		return &v
-	})
-	if err != nil {
-		return err
-	}
+	return w.tradesStream(ctx, market, limit, newSubscription(ctx, tradesChan))
+}
 
-	go func() {
-		for {
-			result, err := generator()
-			if err != nil {
-				close(tradesChan)
-				return
-			}
-			tradesChan <- result
-		}
-	}()
+// SubscribeTrades is GetTradesStream returning a Subscription, which tells why the stream ended
+func (w *WSClient) SubscribeTrades(ctx context.Context, market string, limit uint32) (*Subscription[*pb.GetTradesStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetTradesStreamResponse]) error {
+		return w.tradesStream(ctx, market, limit, sub)
+	})
+}
 
-	return nil
+func (w *WSClient) tradesStream(ctx context.Context, market string, limit uint32, sub *Subscription[*pb.GetTradesStreamResponse]) error {
+	request := &pb.GetTradesRequest{Market: market, Limit: limit}
+	return shareStream(w.streams, ctx, "GetTradesStream", request, sub, streamMarket[*pb.GetTradesStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetTradesStreamResponse]) error {
+		return wsSubscribe(w, ctx, "GetTradesStream", request, feed, func() *pb.GetTradesStreamResponse {
+			var v pb.GetTradesStreamResponse
+			return &v
+		})
+	})
 }
 
 // GetOrderStatusStream subscribes to a stream that shows updates to the owner's orders
 func (w *WSClient) GetOrderStatusStream(ctx context.Context, market, ownerAddress string, statusUpdateChan chan *pb.GetOrderStatusStreamResponse) error {
-	generator, err := connections.WSStream(w.conn, ctx, "GetOrderStatusStream", &pb.GetOrderStatusStreamRequest{
-		Market:       market,
-		OwnerAddress: ownerAddress,
-	}, func() *pb.GetOrderStatusStreamResponse {
-		var v pb.GetOrderStatusStreamResponse
-		return &v
+	return w.orderStatusStream(ctx, market, ownerAddress, newSubscription(ctx, statusUpdateChan))
+}
+
+// SubscribeOrderStatus is GetOrderStatusStream returning a Subscription, which tells why the stream ended
+func (w *WSClient) SubscribeOrderStatus(ctx context.Context, market, ownerAddress string) (*Subscription[*pb.GetOrderStatusStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+		return w.orderStatusStream(ctx, market, ownerAddress, sub)
 	})
-	if err != nil {
-		return err
-	}
+}
 
-	go func() {
-		for {
-			result, err := generator()
-			if err != nil {
-				close(statusUpdateChan)
-				return
-			}
-			statusUpdateChan <- result
-		}
-	}()
+func (w *WSClient) orderStatusStream(ctx context.Context, market, ownerAddress string, sub *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+	request := &pb.GetOrderStatusStreamRequest{Market: market, OwnerAddress: ownerAddress}
+	return shareStream(w.streams, ctx, "GetOrderStatusStream", request, sub, streamMarket[*pb.GetOrderStatusStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetOrderStatusStreamResponse]) error {
+		return wsSubscribe(w, ctx, "GetOrderStatusStream", request, feed, func() *pb.GetOrderStatusStreamResponse {
+			var v pb.GetOrderStatusStreamResponse
+			return &v
+		})
+	})
+}
+
+// GetTickersStream subscribes to a stream for the requested market's top of book. Set market to "" for all markets.
+func (w *WSClient) GetTickersStream(ctx context.Context, market string, tickersChan chan *pb.GetTickersStreamResponse) error {
+	return w.tickersStream(ctx, market, newSubscription(ctx, tickersChan))
+}
+
+// SubscribeTickers is GetTickersStream returning a Subscription, which tells why the stream ended
+func (w *WSClient) SubscribeTickers(ctx context.Context, market string) (*Subscription[*pb.GetTickersStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetTickersStreamResponse]) error {
+		return w.tickersStream(ctx, market, sub)
+	})
+}
 
-	return nil
+func (w *WSClient) tickersStream(ctx context.Context, market string, sub *Subscription[*pb.GetTickersStreamResponse]) error {
+	request := &pb.GetTickersRequest{Market: market}
+	return shareStream(w.streams, ctx, "GetTickersStream", request, sub, streamMarket[*pb.GetTickersStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetTickersStreamResponse]) error {
+		return wsSubscribe(w, ctx, "GetTickersStream", request, feed, func() *pb.GetTickersStreamResponse {
+			var v pb.GetTickersStreamResponse
+			return &v
+		})
+	})
+}
+
+// GetMarketDepthStream subscribes to a stream of aggregated bid/ask depth changes. Each tick references the block height of the previous one.
+func (w *WSClient) GetMarketDepthStream(ctx context.Context, depthChan chan *pb.GetMarketDepthStreamResponse) error {
+	return w.marketDepthStream(ctx, newSubscription(ctx, depthChan))
+}
+
+// SubscribeMarketDepth is GetMarketDepthStream returning a Subscription, which tells why the stream ended
+func (w *WSClient) SubscribeMarketDepth(ctx context.Context) (*Subscription[*pb.GetMarketDepthStreamResponse], error) {
+	return subscribe(ctx, func(sub *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+		return w.marketDepthStream(ctx, sub)
+	})
+}
+
+func (w *WSClient) marketDepthStream(ctx context.Context, sub *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+	request := &pb.GetMarketsRequest{}
+	return shareStream(w.streams, ctx, "GetMarketDepthStream", request, sub, streamMarket[*pb.GetMarketDepthStreamResponse], func(ctx context.Context, feed *Subscription[*pb.GetMarketDepthStreamResponse]) error {
+		return wsSubscribe(w, ctx, "GetMarketDepthStream", request, feed, func() *pb.GetMarketDepthStreamResponse {
+			var v pb.GetMarketDepthStreamResponse
+			return &v
+		})
+	})
 }
 
 // GetTickers returns the requested market tickets. Set market to "" for all markets.
 func (w *WSClient) GetTickers(ctx context.Context, market string) (*pb.GetTickersResponse, error) {
 	var response pb.GetTickersResponse
-	err := w.conn.Request(ctx, "GetTickers", &pb.GetTickersRequest{Market: market}, &response)
+	err := w.request(ctx, "GetTickers", &pb.GetTickersRequest{Market: market}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -156,7 +214,7 @@ func (w *WSClient) GetTickers(ctx context.Context, market string) (*pb.GetTicker
 // GetOpenOrders returns all opened orders by owner address and market
 func (w *WSClient) GetOpenOrders(ctx context.Context, market string, owner string) (*pb.GetOpenOrdersResponse, error) {
 	var response pb.GetOpenOrdersResponse
-	err := w.conn.Request(ctx, "GetOpenOrders", &pb.GetOpenOrdersRequest{Market: market, Address: owner}, &response)
+	err := w.request(ctx, "GetOpenOrders", &pb.GetOpenOrdersRequest{Market: market, Address: owner}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +224,7 @@ func (w *WSClient) GetOpenOrders(ctx context.Context, market string, owner strin
 // GetUnsettled returns all OpenOrders accounts for a given market with the amounts of unsettled funds
 func (w *WSClient) GetUnsettled(ctx context.Context, market string, owner string) (*pb.GetUnsettledResponse, error) {
 	var response pb.GetUnsettledResponse
-	err := w.conn.Request(ctx, "GetUnsettled", &pb.GetUnsettledRequest{Market: market, Owner: owner}, &response)
+	err := w.request(ctx, "GetUnsettled", &pb.GetUnsettledRequest{Market: market, Owner: owner}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +234,7 @@ func (w *WSClient) GetUnsettled(ctx context.Context, market string, owner string
 // GetAccountBalance returns all OpenOrders accounts for a given market with the amounts of unsettled funds
 func (w *WSClient) GetAccountBalance(ctx context.Context, owner string) (*pb.GetAccountBalanceResponse, error) {
 	var response pb.GetAccountBalanceResponse
-	err := w.conn.Request(ctx, "GetAccountBalance", &pb.GetAccountBalanceRequest{OwnerAddress: owner}, &response)
+	err := w.request(ctx, "GetAccountBalance", &pb.GetAccountBalanceRequest{OwnerAddress: owner}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +244,47 @@ func (w *WSClient) GetAccountBalance(ctx context.Context, owner string) (*pb.Get
 // GetMarkets returns the list of all available named markets
 func (w *WSClient) GetMarkets(ctx context.Context) (*pb.GetMarketsResponse, error) {
 	var response pb.GetMarketsResponse
-	err := w.conn.Request(ctx, "GetMarkets", &pb.GetMarketsRequest{}, &response)
+	err := w.request(ctx, "GetMarkets", &pb.GetMarketsRequest{}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetKline returns candles for the requested market between from and to. Resolution indicates the candle duration (e.g. 1d, 4h, 1h, 30m, 15m, 1m).
+func (w *WSClient) GetKline(ctx context.Context, market string, from, to time.Time, resolution string, limit uint32) (*pb.GetKlineResponse, error) {
+	var response pb.GetKlineResponse
+	err := w.request(ctx, "GetKline", getKlineRequest(market, from, to, resolution, limit), &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetOrders returns the owner's orders for a market, filtered by opts
+func (w *WSClient) GetOrders(ctx context.Context, market, owner string, opts GetOrdersOpts) (*pb.GetOrdersResponse, error) {
+	var response pb.GetOrdersResponse
+	err := w.request(ctx, "GetOrders", getOrdersRequest(market, owner, opts), &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetOrderByID returns the order with the given ID. Market is optional but speeds up the lookup.
+func (w *WSClient) GetOrderByID(ctx context.Context, market, orderID string) (*pb.GetOrderByIDResponse, error) {
+	var response pb.GetOrderByIDResponse
+	err := w.request(ctx, "GetOrderByID", &pb.GetOrderByIDRequest{Market: market, OrderID: orderID}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetServerTime returns the Serum API server's current time
+func (w *WSClient) GetServerTime(ctx context.Context) (*pb.GetServerTimeResponse, error) {
+	var response pb.GetServerTimeResponse
+	err := w.request(ctx, "GetServerTime", &pb.GetServerTimeRequest{}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -206,8 +304,12 @@ func (w *WSClient) PostOrder(ctx context.Context, owner, payer, market string, s
 		OpenOrdersAddress: opts.OpenOrdersAddress,
 		ClientOrderID:     opts.ClientOrderID,
 	}
+	if err := w.validator.validate(ctx, request); err != nil {
+		return nil, err
+	}
+
 	var response pb.PostOrderResponse
-	err := w.conn.Request(ctx, "PostOrder", request, &response)
+	err := w.request(ctx, "PostOrder", request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -220,21 +322,31 @@ func (w *WSClient) PostSubmit(ctx context.Context, txBase64 string, skipPreFligh
 		Transaction:   txBase64,
 		SkipPreFlight: skipPreFlight,
 	}
-	var response pb.PostSubmitResponse
-	err := w.conn.Request(ctx, "PostSubmit", request, &response)
-	if err != nil {
-		return nil, err
-	}
-	return &response, nil
+	return w.retrier.submit(ctx, txBase64, func(ctx context.Context) (*pb.PostSubmitResponse, error) {
+		var response pb.PostSubmitResponse
+		err := w.request(ctx, "PostSubmit", request, &response)
+		if err != nil {
+			return nil, err
+		}
+		return &response, nil
+	})
+}
+
+// ConfirmTransaction waits until the transaction reaches the requested commitment on RPCOpts.SolanaRPCEndpoint
+func (w *WSClient) ConfirmTransaction(ctx context.Context, signature string, opts ConfirmationOpts) (*Confirmation, error) {
+	return w.confirmer.confirm(ctx, signature, opts)
 }
 
-// signAndSubmit signs the given transaction and submits it.
+// signAndSubmit signs the given transaction and submits it. With RPCOpts.WaitForConfirmation set, it also waits for
+// the transaction to be confirmed.
 func (w *WSClient) signAndSubmit(ctx context.Context, tx string, skipPreFlight bool) (string, error) {
-	if w.privateKey == nil {
+	if w.signer == nil {
 		return "", ErrPrivateKeyNotFound
 	}
 
-	txBase64, err := transaction.SignTxWithPrivateKey(tx, *w.privateKey)
+	txBase64, err := traced(ctx, w.tracer, "SignTransaction", func(ctx context.Context) (string, error) {
+		return transaction.SignTxWithSigner(ctx, tx, w.signer)
+	})
 	if err != nil {
 		return "", err
 	}
@@ -244,17 +356,19 @@ func (w *WSClient) signAndSubmit(ctx context.Context, tx string, skipPreFlight b
 		return "", err
 	}
 
-	return response.Signature, nil
+	return w.confirmer.afterSubmit(ctx, response.Signature)
 }
 
 // SubmitOrder builds a Serum market order, signs it, and submits to the network.
 func (w *WSClient) SubmitOrder(ctx context.Context, owner, payer, market string, side pb.Side, types []pb.OrderType, amount, price float64, opts PostOrderOpts) (string, error) {
-	order, err := w.PostOrder(ctx, owner, payer, market, side, types, amount, price, opts)
-	if err != nil {
-		return "", err
-	}
+	return traced(ctx, w.tracer, "SubmitOrder", func(ctx context.Context) (string, error) {
+		order, err := w.PostOrder(ctx, owner, payer, market, side, types, amount, price, opts)
+		if err != nil {
+			return "", err
+		}
 
-	return w.signAndSubmit(ctx, order.Transaction, opts.SkipPreFlight)
+		return w.signAndSubmit(ctx, order.Transaction, opts.SkipPreFlight)
+	})
 }
 
 // PostCancelOrder builds a Serum cancel order.
@@ -275,7 +389,7 @@ func (w *WSClient) PostCancelOrder(
 	}
 
 	var response pb.PostCancelOrderResponse
-	err := w.conn.Request(ctx, "PostCancelOrder", request, &response)
+	err := w.request(ctx, "PostCancelOrder", request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -292,12 +406,14 @@ func (w *WSClient) SubmitCancelOrder(
 	openOrders string,
 	skipPreFlight bool,
 ) (string, error) {
-	order, err := w.PostCancelOrder(ctx, orderID, side, owner, market, openOrders)
-	if err != nil {
-		return "", err
-	}
+	return traced(ctx, w.tracer, "SubmitCancelOrder", func(ctx context.Context) (string, error) {
+		order, err := w.PostCancelOrder(ctx, orderID, side, owner, market, openOrders)
+		if err != nil {
+			return "", err
+		}
 
-	return w.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+		return w.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+	})
 }
 
 // PostCancelByClientOrderID builds a Serum cancel order by client ID.
@@ -315,7 +431,7 @@ func (w *WSClient) PostCancelByClientOrderID(
 		OpenOrdersAddress: openOrders,
 	}
 	var response pb.PostCancelOrderResponse
-	err := w.conn.Request(ctx, "PostCancelByClientOrderID", request, &response)
+	err := w.request(ctx, "PostCancelByClientOrderID", request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -331,12 +447,14 @@ func (w *WSClient) SubmitCancelByClientOrderID(
 	openOrders string,
 	skipPreFlight bool,
 ) (string, error) {
-	order, err := w.PostCancelByClientOrderID(ctx, clientOrderID, owner, market, openOrders)
-	if err != nil {
-		return "", err
-	}
+	return traced(ctx, w.tracer, "SubmitCancelByClientOrderID", func(ctx context.Context) (string, error) {
+		order, err := w.PostCancelByClientOrderID(ctx, clientOrderID, owner, market, openOrders)
+		if err != nil {
+			return "", err
+		}
 
-	return w.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+		return w.signAndSubmit(ctx, order.Transaction, skipPreFlight)
+	})
 }
 
 func (w *WSClient) PostCancelAll(
@@ -351,7 +469,7 @@ func (w *WSClient) PostCancelAll(
 		OpenOrdersAddresses: openOrdersAddresses,
 	}
 	var response pb.PostCancelAllResponse
-	err := w.conn.Request(ctx, "PostCancelAll", request, &response)
+	err := w.request(ctx, "PostCancelAll", request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -365,22 +483,24 @@ func (w *WSClient) SubmitCancelAll(
 	openOrdersAddresses []string,
 	skipPreFlight bool,
 ) ([]string, error) {
-	orders, err := w.PostCancelAll(ctx, market, owner, openOrdersAddresses)
-	if err != nil {
-		return nil, err
-	}
-
-	var signatures []string
-	for _, tx := range orders.Transactions {
-		signature, err := w.signAndSubmit(ctx, tx, skipPreFlight)
+	return traced(ctx, w.tracer, "SubmitCancelAll", func(ctx context.Context) ([]string, error) {
+		orders, err := w.PostCancelAll(ctx, market, owner, openOrdersAddresses)
 		if err != nil {
-			return signatures, err
+			return nil, err
 		}
 
-		signatures = append(signatures, signature)
-	}
+		var signatures []string
+		for _, tx := range orders.Transactions {
+			signature, err := w.signAndSubmit(ctx, tx, skipPreFlight)
+			if err != nil {
+				return signatures, err
+			}
+
+			signatures = append(signatures, signature)
+		}
 
-	return signatures, nil
+		return signatures, nil
+	})
 }
 
 // PostSettle returns a partially signed transaction for settling market funds. Typically, you want to use SubmitSettle instead of this.
@@ -393,7 +513,7 @@ func (w *WSClient) PostSettle(ctx context.Context, owner, market, baseTokenWalle
 		OpenOrdersAddress: openOrdersAccount,
 	}
 	var response pb.PostSettleResponse
-	err := w.conn.Request(ctx, "PostSettle", request, &response)
+	err := w.request(ctx, "PostSettle", request, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -402,11 +522,13 @@ func (w *WSClient) PostSettle(ctx context.Context, owner, market, baseTokenWalle
 
 // SubmitSettle builds a market SubmitSettle transaction, signs it, and submits to the network.
 func (w *WSClient) SubmitSettle(ctx context.Context, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount string, skipPreflight bool) (string, error) {
-	order, err := w.PostSettle(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
-	if err != nil {
-		return "", err
-	}
-	return w.signAndSubmit(ctx, order.Transaction, skipPreflight)
+	return traced(ctx, w.tracer, "SubmitSettle", func(ctx context.Context) (string, error) {
+		order, err := w.PostSettle(ctx, owner, market, baseTokenWallet, quoteTokenWallet, openOrdersAccount)
+		if err != nil {
+			return "", err
+		}
+		return w.signAndSubmit(ctx, order.Transaction, skipPreflight)
+	})
 }
 
 func (w *WSClient) Close() error {
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const conformanceTimeout = 5 * time.Second
+
+func TestClient_Conformance(t *testing.T) {
+	s := newStandin(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T) provider.Client
+	}{
+		{"http", func(t *testing.T) provider.Client {
+			return provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk}).Client()
+		}},
+		{"ws", func(t *testing.T) provider.Client {
+			w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T) provider.Client {
+			g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			client := c.newClient(t)
+			defer func() {
+				assert.Nil(t, client.Close())
+			}()
+
+			testClientRequests(t, client, pk.PublicKey())
+			testClientSubmissions(t, client, pk.PublicKey())
+
+			// HTTP streams poll the server instead, see TestHTTP_Streams
+			_, polling := client.(*provider.HTTPContextClient)
+			if streamingClient, ok := client.(provider.StreamingClient); ok && !polling {
+				testClientStreams(t, streamingClient, pk.PublicKey())
+			}
+		})
+	}
+}
+
+func testClientRequests(t *testing.T, client provider.Client, owner solana.PublicKey) {
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	markets, err := client.GetMarkets(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, standinMarketAddress, markets.Markets[standinMarket].Address)
+
+	orderbook, err := client.GetOrderbook(ctx, "SOLUSDC", 2)
+	require.Nil(t, err)
+	assert.Equal(t, standinMarket, orderbook.Market)
+	assert.Equal(t, 2, len(orderbook.Bids))
+	assert.Equal(t, 2, len(orderbook.Asks))
+
+	_, err = client.GetOrderbook(ctx, "market-doesnt-exist", 0)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "provided market name/address was not found")
+
+	trades, err := client.GetTrades(ctx, "SOLUSDC", 0)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(trades.Trades))
+
+	tickers, err := client.GetTickers(ctx, "SOLUSDC")
+	require.Nil(t, err)
+	assert.Equal(t, standinMarket, tickers.Tickers[0].Market)
+
+	openOrders, err := client.GetOpenOrders(ctx, "SOLUSDC", owner.String())
+	require.Nil(t, err)
+	assert.Equal(t, standinMarket, openOrders.Orders[0].Market)
+
+	unsettled, err := client.GetUnsettled(ctx, "SOLUSDC", owner.String())
+	require.Nil(t, err)
+	assert.Equal(t, owner.String(), unsettled.Unsettled[0].Account)
+
+	balance, err := client.GetAccountBalance(ctx, owner.String())
+	require.Nil(t, err)
+	assert.Equal(t, owner.String(), balance.Tokens[0].Address)
+
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	kline, err := client.GetKline(ctx, "SOLUSDC", from, to, "1m", 2)
+	require.Nil(t, err)
+	assert.Equal(t, standinMarket, kline.Market)
+	require.Equal(t, 2, len(kline.Candles))
+	assert.True(t, from.Equal(kline.Candles[0].StartTime.AsTime()))
+	assert.True(t, to.Equal(kline.Candles[0].UpdateTime.AsTime()))
+
+	orders, err := client.GetOrders(ctx, "SOLUSDC", owner.String(), provider.GetOrdersOpts{
+		Side:  pb.Side_S_BID,
+		Types: []pb.OrderType{pb.OrderType_OT_LIMIT, pb.OrderType_OT_POST},
+		From:  from,
+		Limit: 10,
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(orders.Orders))
+	assert.Equal(t, pb.Side_S_BID, orders.Orders[0].Side)
+	assert.Equal(t, []pb.OrderType{pb.OrderType_OT_LIMIT, pb.OrderType_OT_POST}, orders.Orders[0].Types)
+	assert.True(t, from.Equal(orders.Orders[0].CreatedAt.AsTime()))
+	assert.Equal(t, owner.String(), orders.Orders[0].OpenOrderAccount)
+
+	order, err := client.GetOrderByID(ctx, "SOLUSDC", "1234")
+	require.Nil(t, err)
+	assert.Equal(t, "1234", order.Order.OrderID)
+
+	serverTime, err := client.GetServerTime(ctx)
+	require.Nil(t, err)
+	parsedTime, err := time.Parse(time.RFC3339Nano, serverTime.Timestamp)
+	require.Nil(t, err)
+	assert.WithinDuration(t, time.Now(), parsedTime, time.Minute)
+}
+
+func testClientSubmissions(t *testing.T, client provider.Client, owner solana.PublicKey) {
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	order, err := client.PostOrder(ctx, owner.String(), owner.String(), "SOLUSDC", pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{OpenOrdersAddress: "oo"})
+	require.Nil(t, err)
+	assert.NotEmpty(t, order.Transaction)
+	assert.Equal(t, "oo", order.OpenOrdersAddress)
+
+	// unsigned transactions are rejected
+	_, err = client.PostSubmit(ctx, order.Transaction, false)
+	assert.NotNil(t, err)
+
+	signature, err := client.SubmitOrder(ctx, owner.String(), owner.String(), "SOLUSDC", pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+
+	signature, err = client.SubmitCancelOrder(ctx, "1", pb.Side_S_ASK, owner.String(), standinMarketAddress, "oo", false)
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+
+	signature, err = client.SubmitCancelByClientOrderID(ctx, 5000, owner.String(), standinMarketAddress, "oo", false)
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+
+	signatures, err := client.SubmitCancelAll(ctx, standinMarketAddress, owner.String(), []string{"oo1", "oo2"}, false)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(signatures))
+
+	signature, err = client.SubmitSettle(ctx, owner.String(), standinMarket, "base", "quote", "oo", false)
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+}
+
+func testClientStreams(t *testing.T, client provider.StreamingClient, owner solana.PublicKey) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	orderbookCh := make(chan *pb.GetOrderbooksStreamResponse, standinStreamUpdates)
+	require.Nil(t, client.GetOrderbooksStream(ctx, []string{"SOLUSDC"}, 1, orderbookCh))
+	for i := 0; i < standinStreamUpdates; i++ {
+		update := bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout)
+		require.NotNil(t, update)
+		assert.Equal(t, int64(i+1), update.BlockHeight)
+		assert.Equal(t, 1, len(update.Orderbook.Bids))
+	}
+
+	tradesCh := make(chan *pb.GetTradesStreamResponse, standinStreamUpdates)
+	require.Nil(t, client.GetTradesStream(ctx, "SOLUSDC", 0, tradesCh))
+	update := bxassert.ReadChanWithTimeout(t, tradesCh, conformanceTimeout)
+	require.NotNil(t, update)
+	assert.Equal(t, 1, len(update.Trades.Trades))
+
+	statusCh := make(chan *pb.GetOrderStatusStreamResponse, standinStreamUpdates)
+	require.Nil(t, client.GetOrderStatusStream(ctx, "SOLUSDC", owner.String(), statusCh))
+	statusUpdate := bxassert.ReadChanWithTimeout(t, statusCh, conformanceTimeout)
+	require.NotNil(t, statusUpdate)
+	assert.Equal(t, pb.OrderStatus_OS_OPEN, statusUpdate.OrderInfo.OrderStatus)
+
+	tickersCh := make(chan *pb.GetTickersStreamResponse, standinStreamUpdates)
+	require.Nil(t, client.GetTickersStream(ctx, "SOLUSDC", tickersCh))
+	tickersUpdate := bxassert.ReadChanWithTimeout(t, tickersCh, conformanceTimeout)
+	require.NotNil(t, tickersUpdate)
+	assert.Equal(t, standinMarket, tickersUpdate.Ticker.Tickers[0].Market)
+
+	depthCh := make(chan *pb.GetMarketDepthStreamResponse, standinStreamUpdates)
+	require.Nil(t, client.GetMarketDepthStream(ctx, depthCh))
+	for i := 0; i < standinStreamUpdates; i++ {
+		depthUpdate := bxassert.ReadChanWithTimeout(t, depthCh, conformanceTimeout)
+		require.NotNil(t, depthUpdate)
+		assert.Equal(t, int64(i+1), depthUpdate.BlockHeight)
+		assert.Equal(t, int64(i), depthUpdate.Tick.PrevBlockHeight)
+	}
+
+	err := client.GetOrderbooksStream(ctx, []string{"market-doesnt-exist"}, 0, make(chan *pb.GetOrderbooksStreamResponse))
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "provided market name/address was not found")
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSolanaRPC answers getSignatureStatuses with the scripted statuses in order, repeating the last one. A nil status
+// means the transaction isn't known yet.
+type fakeSolanaRPC struct {
+	mu       sync.Mutex
+	statuses []*solanarpc.SignatureStatusesResult
+	polls    int
+}
+
+func newFakeSolanaRPC(t *testing.T, statuses ...*solanarpc.SignatureStatusesResult) (*fakeSolanaRPC, string) {
+	f := &fakeSolanaRPC{statuses: statuses}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "getSignatureStatuses", request.Method)
+
+		f.mu.Lock()
+		status := f.statuses[len(f.statuses)-1]
+		if f.polls < len(f.statuses) {
+			status = f.statuses[f.polls]
+		}
+		f.polls++
+		f.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result": map[string]interface{}{
+				"context": map[string]interface{}{"slot": 100},
+				"value":   []*solanarpc.SignatureStatusesResult{status},
+			},
+		})
+	}))
+	t.Cleanup(s.Close)
+	return f, s.URL
+}
+
+func (f *fakeSolanaRPC) pollCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.polls
+}
+
+func signatureStatus(slot uint64, status solanarpc.ConfirmationStatusType, err interface{}) *solanarpc.SignatureStatusesResult {
+	return &solanarpc.SignatureStatusesResult{Slot: slot, ConfirmationStatus: status, Err: err}
+}
+
+func TestConfirm_SubmitWaitsForConfirmation(t *testing.T) {
+	s := newStandin(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+
+	rpc, rpcEndpoint := newFakeSolanaRPC(t,
+		nil,
+		signatureStatus(10, solanarpc.ConfirmationStatusProcessed, nil),
+		signatureStatus(11, solanarpc.ConfirmationStatusConfirmed, nil),
+	)
+	opts := provider.RPCOpts{
+		Endpoint:            s.GRPCEndpoint,
+		Timeout:             conformanceTimeout,
+		PrivateKey:          &pk,
+		SolanaRPCEndpoint:   rpcEndpoint,
+		WaitForConfirmation: &provider.ConfirmationOpts{PollInterval: 10 * time.Millisecond},
+	}
+	g, err := provider.NewGRPCClientWithOpts(opts)
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	signature, err := g.SubmitOrder(ctx, owner, owner, standinMarket, pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+	assert.Equal(t, 3, rpc.pollCount())
+
+	// the same status can be checked for a higher commitment
+	_, err = g.ConfirmTransaction(ctx, signature, provider.ConfirmationOpts{Commitment: solanarpc.CommitmentFinalized, Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+	var timeoutErr *provider.ConfirmationTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, signature, timeoutErr.Signature)
+	require.NotNil(t, timeoutErr.Last)
+	assert.Equal(t, uint64(11), timeoutErr.Last.Slot)
+	assert.Equal(t, solanarpc.ConfirmationStatusConfirmed, timeoutErr.Last.Status)
+}
+
+func TestConfirm_Errors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	signature := solana.Signature{1}.String()
+	pollOpts := provider.ConfirmationOpts{Timeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+
+	// transactions that fail on chain return the slot and error
+	_, rpcEndpoint := newFakeSolanaRPC(t, signatureStatus(12, solanarpc.ConfirmationStatusProcessed, map[string]interface{}{"InstructionError": []interface{}{0, "InvalidArgument"}}))
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: "http://127.0.0.1:0", SolanaRPCEndpoint: rpcEndpoint})
+	_, err := h.ConfirmTransaction(ctx, signature, pollOpts)
+	var failedErr *provider.TransactionFailedError
+	require.True(t, errors.As(err, &failedErr))
+	assert.Equal(t, uint64(12), failedErr.Slot)
+	assert.NotNil(t, failedErr.Err)
+
+	// transactions that are never found time out without a last status
+	_, rpcEndpoint = newFakeSolanaRPC(t, nil)
+	h = provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: "http://127.0.0.1:0", SolanaRPCEndpoint: rpcEndpoint})
+	_, err = h.ConfirmTransaction(ctx, signature, pollOpts)
+	var timeoutErr *provider.ConfirmationTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Nil(t, timeoutErr.Last)
+
+	// polls that hang don't hold up the timeout
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+	h = provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: "http://127.0.0.1:0", SolanaRPCEndpoint: hanging.URL})
+	start := time.Now()
+	_, err = h.ConfirmTransaction(ctx, signature, pollOpts)
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// confirmations need a Solana RPC endpoint
+	h = provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: "http://127.0.0.1:0"})
+	_, err = h.ConfirmTransaction(ctx, signature, pollOpts)
+	assert.Equal(t, provider.ErrSolanaRPCNotConfigured, err)
+}
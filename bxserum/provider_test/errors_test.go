@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrors_Transports(t *testing.T) {
+	clients := []struct {
+		name       string
+		newClient  func(t *testing.T, s *mock.Server) provider.Client
+		code       int
+		httpStatus int
+	}{
+		{"http", func(t *testing.T, s *mock.Server) provider.Client {
+			return provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout}).Client()
+		}, int(codes.NotFound), http.StatusNotFound},
+		{"ws", func(t *testing.T, s *mock.Server) provider.Client {
+			w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout})
+			require.Nil(t, err)
+			return w
+		}, jsonrpc2.CodeInvalidParams, 0},
+		{"grpc", func(t *testing.T, s *mock.Server) provider.Client {
+			g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+			require.Nil(t, err)
+			return g
+		}, int(codes.NotFound), 0},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			_, s := newMockAPI(t)
+			client := c.newClient(t, s)
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			_, err := client.GetOrderbook(ctx, "BTC/USDC", 0)
+			require.NotNil(t, err)
+			assert.True(t, errors.Is(err, bxerrors.ErrMarketNotFound))
+
+			var apiErr *bxerrors.Error
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, "provided market name/address was not found", apiErr.Message)
+			assert.Equal(t, bxerrors.Transport(c.name), apiErr.Transport)
+			assert.Equal(t, c.code, apiErr.Code)
+			assert.Equal(t, c.httpStatus, apiErr.HTTPStatus)
+			assert.NotNil(t, apiErr.Cause)
+
+			_, err = client.GetAccountBalance(ctx, "owner")
+			assert.True(t, errors.Is(err, bxerrors.ErrInvalidAddress))
+
+			// requests on a closed client fail with a transport error, except over HTTP which has no connection to close
+			if c.name != "http" {
+				require.Nil(t, client.Close())
+				_, err = client.GetOrderbook(ctx, standinMarket, 0)
+				assert.True(t, errors.Is(err, bxerrors.ErrTransportClosed), err)
+			}
+		})
+	}
+}
+
+func TestErrors_HTTPStatus(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") == "1" {
+			time.Sleep(time.Second)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("slow down"))
+	}))
+	defer s.Close()
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.URL, Timeout: conformanceTimeout})
+
+	// errors that aren't from the API keep their body as message
+	_, err := h.GetOrderbookWithContext(context.Background(), standinMarket, 0)
+	assert.True(t, errors.Is(err, bxerrors.ErrRateLimited))
+	var apiErr *bxerrors.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.HTTPStatus)
+	assert.Equal(t, "slow down", apiErr.Message)
+
+	// the original cause is kept
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = h.GetOrderbookWithContext(ctx, standinMarket, 1)
+	assert.True(t, errors.Is(err, bxerrors.ErrTimeout))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestErrors_GRPCStatus(t *testing.T) {
+	s := newStandin(t)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	// status codes are still available to callers inspecting them directly
+	_, err = g.GetOrderbook(ctx, "BTC/USDC", 0)
+	require.NotNil(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
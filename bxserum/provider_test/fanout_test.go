@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readUntilBlock skips the updates sent before the consumer is known to have joined the stream
+func readUntilBlock(t *testing.T, ch chan *pb.GetOrderbooksStreamResponse, height int64) *pb.GetOrderbooksStreamResponse {
+	for {
+		update := bxassert.ReadChanWithTimeout(t, ch, conformanceTimeout)
+		if update == nil || update.BlockHeight >= height {
+			return update
+		}
+	}
+}
+
+func TestShareStreams(t *testing.T) {
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.StreamingClient
+	}{
+		{"ws", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.StreamingClient {
+			opts.Endpoint = s.WSEndpoint
+			w, err := provider.NewWSClientWithOpts(opts)
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.StreamingClient {
+			opts.Endpoint = s.GRPCEndpoint
+			g, err := provider.NewGRPCClientWithOpts(opts)
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			api, s := newMockAPI(t)
+			client := c.newClient(t, s, provider.RPCOpts{Timeout: conformanceTimeout, ShareStreams: true})
+			defer func() {
+				_ = client.Close()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			firstCtx, firstCancel := context.WithCancel(ctx)
+			defer firstCancel()
+			first := make(chan *pb.GetOrderbooksStreamResponse, 10)
+			require.Nil(t, client.GetOrderbooksStream(firstCtx, []string{standinMarket}, 0, first))
+			height := bxassert.ReadChanWithTimeout(t, first, conformanceTimeout).BlockHeight
+
+			// identical subscriptions share the API subscription, different ones don't
+			secondCtx, secondCancel := context.WithCancel(ctx)
+			defer secondCancel()
+			second := make(chan *pb.GetOrderbooksStreamResponse, 10)
+			require.Nil(t, client.GetOrderbooksStream(secondCtx, []string{standinMarket}, 0, second))
+			assert.Equal(t, 1, api.Subscribers())
+
+			tickersCtx, tickersCancel := context.WithCancel(ctx)
+			tickers := make(chan *pb.GetTickersStreamResponse, 10)
+			require.Nil(t, client.GetTickersStream(tickersCtx, standinMarket, tickers))
+			assert.Eventually(t, func() bool { return api.Subscribers() == 2 }, conformanceTimeout, 10*time.Millisecond)
+			tickersCancel()
+			assert.Eventually(t, func() bool { return api.Subscribers() == 1 }, conformanceTimeout, 10*time.Millisecond)
+
+			// updates are fanned out to every consumer
+			api.AdvanceBlock()
+			assert.Equal(t, height+1, bxassert.ReadChanWithTimeout(t, first, conformanceTimeout).BlockHeight)
+			assert.Equal(t, height+1, readUntilBlock(t, second, height+1).BlockHeight)
+
+			// the subscription outlives the consumer that made it
+			firstCancel()
+			select {
+			case _, ok := <-first:
+				assert.False(t, ok)
+			case <-time.After(conformanceTimeout):
+				assert.Fail(t, "channel of canceled consumer not closed")
+			}
+			api.AdvanceBlock()
+			assert.Equal(t, height+2, bxassert.ReadChanWithTimeout(t, second, conformanceTimeout).BlockHeight)
+			assert.Equal(t, 1, api.Subscribers())
+
+			// and ends with the last one
+			secondCancel()
+			assert.Eventually(t, func() bool { return api.Subscribers() == 0 }, conformanceTimeout, 10*time.Millisecond)
+
+			// which lets the next consumer subscribe again
+			third := make(chan *pb.GetOrderbooksStreamResponse, 10)
+			require.Nil(t, client.GetOrderbooksStream(ctx, []string{standinMarket}, 0, third))
+			assert.Equal(t, height+2, bxassert.ReadChanWithTimeout(t, third, conformanceTimeout).BlockHeight)
+
+			// a consumer that blocks on its channel doesn't hold up the others
+			stalledCtx, stalledCancel := context.WithCancel(ctx)
+			stalled := make(chan *pb.GetOrderbooksStreamResponse)
+			require.Nil(t, client.GetOrderbooksStream(provider.WithStreamOverflow(stalledCtx, provider.StreamOverflow{Policy: provider.OverflowBlock}), []string{standinMarket}, 0, stalled))
+			for i := int64(3); i < 8; i++ {
+				api.AdvanceBlock()
+				assert.Equal(t, height+i, readUntilBlock(t, third, height+i).BlockHeight)
+			}
+			assert.Equal(t, 1, api.Subscribers())
+
+			// and receives its queued updates once it reads again
+			assert.Equal(t, height+7, readUntilBlock(t, stalled, height+7).BlockHeight)
+			stalledCancel()
+		})
+	}
+}
@@ -1,13 +1,27 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
 	"github.com/gagliardetto/solana-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestHTTP_New(t *testing.T) {
@@ -38,3 +52,253 @@ func TestHTTP_NewWithOpts(t *testing.T) {
 	c := provider.NewHTTPClientWithOpts(nil, o)
 	assert.NotNil(t, c)
 }
+
+// pollingAPI serves an orderbook that only changes every other poll, a trade history that grows by one trade per poll,
+// and an open order that is filled on the third poll
+type pollingAPI struct {
+	m     sync.Mutex
+	polls map[string]int
+}
+
+func (p *pollingAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.m.Lock()
+	path := strings.TrimSuffix(r.URL.Path, "/SOLUSDC")
+	p.polls[path]++
+	poll := p.polls[path]
+	p.m.Unlock()
+
+	var response proto.Message
+	switch path {
+	case "/api/v1/market/orderbooks":
+		response = &pb.GetOrderbookResponse{Market: standinMarket, Bids: []*pb.OrderbookItem{{Price: 99, Size: float64((poll + 1) / 2)}}}
+	case "/api/v1/market/trades":
+		var trades []*pb.Trade
+		for i := 0; i < poll; i++ {
+			trades = append(trades, &pb.Trade{OrderID: fmt.Sprint(i), Price: 100, Size: 1})
+		}
+		response = &pb.GetTradesResponse{Trades: trades}
+	case "/api/v1/trade/openorders":
+		var orders []*pb.Order
+		if poll < 3 {
+			orders = append(orders, &pb.Order{OrderID: "1", Market: standinMarket, RemainingSize: float64(3 - poll), ClientOrderID: "5000"})
+		}
+		response = &pb.GetOpenOrdersResponse{Orders: orders}
+	case "/api/v1/trade/orderbyid/1":
+		response = &pb.GetOrderByIDResponse{Order: &pb.Order{OrderID: "1", Market: standinMarket, ClientOrderID: "5000"}}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code": 5, "message": "provided market name/address was not found"}`))
+		return
+	}
+
+	b, _ := protojson.Marshal(response)
+	_, _ = w.Write(b)
+}
+
+func TestHTTP_Streams(t *testing.T) {
+	server := httptest.NewServer(&pollingAPI{polls: make(map[string]int)})
+	defer server.Close()
+
+	c := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{
+		Endpoint:              server.URL,
+		Timeout:               time.Second,
+		HTTPPollInterval:      time.Millisecond,
+		HTTPMaxPollInterval:   5 * time.Millisecond,
+		HTTPMarketDepthMarket: "SOLUSDC",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// orderbook only changes every other poll: each update should differ from the last
+	orderbookCh := make(chan *pb.GetOrderbooksStreamResponse)
+	require.Nil(t, c.GetOrderbooksStream(ctx, []string{"SOLUSDC"}, 0, orderbookCh))
+	for i := 1; i <= 3; i++ {
+		update := bxassert.ReadChanWithTimeout(t, orderbookCh, time.Second)
+		require.NotNil(t, update)
+		assert.Equal(t, float64(i), update.Orderbook.Bids[0].Size)
+	}
+
+	// market depth starts with a snapshot, then chains ticks of the changed levels
+	depthCh := make(chan *pb.GetMarketDepthStreamResponse)
+	require.Nil(t, c.GetMarketDepthStream(ctx, depthCh))
+	depth := bxassert.ReadChanWithTimeout(t, depthCh, time.Second)
+	assert.Equal(t, int64(1), depth.BlockHeight)
+	assert.Equal(t, int64(0), depth.Tick.PrevBlockHeight)
+	require.Len(t, depth.Tick.Bids, 1)
+	snapshotSize := depth.Tick.Bids[0].Size
+	depth = bxassert.ReadChanWithTimeout(t, depthCh, time.Second)
+	assert.Equal(t, int64(2), depth.BlockHeight)
+	assert.Equal(t, int64(1), depth.Tick.PrevBlockHeight)
+	require.Len(t, depth.Tick.Bids, 1)
+	assert.Equal(t, 99.0, depth.Tick.Bids[0].Price)
+	assert.NotEqual(t, snapshotSize, depth.Tick.Bids[0].Size)
+	err := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: server.URL}).GetMarketDepthStream(ctx, depthCh)
+	assert.ErrorIs(t, err, provider.ErrNoDepthMarket)
+
+	// every poll adds a trade: only the new one should be emitted after the initial history
+	tradesCh := make(chan *pb.GetTradesStreamResponse)
+	require.Nil(t, c.GetTradesStream(ctx, "SOLUSDC", 0, tradesCh))
+	for i := 0; i < 3; i++ {
+		update := bxassert.ReadChanWithTimeout(t, tradesCh, time.Second)
+		require.NotNil(t, update)
+		require.Equal(t, 1, len(update.Trades.Trades))
+		assert.Equal(t, fmt.Sprint(i), update.Trades.Trades[0].OrderID)
+	}
+
+	statusCh := make(chan *pb.GetOrderStatusStreamResponse)
+	require.Nil(t, c.GetOrderStatusStream(ctx, "SOLUSDC", "owner", statusCh))
+	update := bxassert.ReadChanWithTimeout(t, statusCh, time.Second)
+	require.NotNil(t, update)
+	assert.Equal(t, pb.OrderStatus_OS_PARTIAL_FILL, update.OrderInfo.OrderStatus)
+	assert.Equal(t, float32(1), update.OrderInfo.QuantityReleased)
+	assert.Equal(t, uint64(5000), update.OrderInfo.ClientOrderID)
+	update = bxassert.ReadChanWithTimeout(t, statusCh, time.Second)
+	require.NotNil(t, update)
+	assert.Equal(t, pb.OrderStatus_OS_FILLED, update.OrderInfo.OrderStatus)
+
+	err = c.GetTickersStream(ctx, "SOLUSDC", make(chan *pb.GetTickersStreamResponse))
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "provided market name/address was not found")
+
+	// stream channels are closed once the context is done
+	cancel()
+	_, ok := <-orderbookCh
+	assert.False(t, ok)
+}
+
+func TestHTTP_StreamPollFailures(t *testing.T) {
+	// the first poll succeeds, and the following ones fail with status
+	var polls int32
+	failingAPI := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&polls, 1) == 1 {
+				b, _ := protojson.Marshal(&pb.GetTickersResponse{Tickers: []*pb.Ticker{{Market: standinMarket}}})
+				_, _ = w.Write(b)
+				return
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	subscribe := func(server *httptest.Server) *provider.Subscription[*pb.GetTickersStreamResponse] {
+		atomic.StoreInt32(&polls, 0)
+		c := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{
+			Endpoint:            server.URL,
+			Timeout:             time.Second,
+			HTTPPollInterval:    time.Millisecond,
+			HTTPMaxPollInterval: 2 * time.Millisecond,
+			HTTPMaxPollFailures: 3,
+		})
+		sub, err := c.SubscribeTickers(context.Background(), "SOLUSDC")
+		require.Nil(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = sub.Next(ctx)
+		require.Nil(t, err)
+		select {
+		case <-sub.Done():
+		case <-time.After(time.Second):
+			require.Fail(t, "stream didn't end")
+		}
+		return sub
+	}
+
+	// transient errors end the stream once they keep failing
+	unavailable := failingAPI(http.StatusServiceUnavailable, `{"code": 14, "message": "unavailable"}`)
+	defer unavailable.Close()
+	sub := subscribe(unavailable)
+	assert.ErrorIs(t, sub.Err(), bxerrors.ErrTransportClosed)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&polls))
+
+	// errors that polling again can't fix end it right away
+	delisted := failingAPI(http.StatusBadRequest, `{"code": 5, "message": "provided market name/address was not found"}`)
+	defer delisted.Close()
+	sub = subscribe(delisted)
+	assert.ErrorIs(t, sub.Err(), bxerrors.ErrMarketNotFound)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+}
+
+// bodyTracker counts the response bodies that are still open
+type bodyTracker struct {
+	m    sync.Mutex
+	open int
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	tracker *bodyTracker
+	once    sync.Once
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() {
+		b.tracker.m.Lock()
+		b.tracker.open--
+		b.tracker.m.Unlock()
+	})
+	return b.ReadCloser.Close()
+}
+
+func (t *bodyTracker) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	t.m.Lock()
+	t.open++
+	t.m.Unlock()
+	resp.Body = &trackedBody{ReadCloser: resp.Body, tracker: t}
+	return resp, nil
+}
+
+func TestHTTP_Context(t *testing.T) {
+	s := newStandin(t)
+	// the request context is only cancelled on disconnect once the body was read
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+
+	tracker := &bodyTracker{}
+	client := &http.Client{Transport: tracker}
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+
+	// a cancelled context aborts in-flight requests
+	h := provider.NewHTTPClientWithOpts(client, provider.RPCOpts{Endpoint: stalled.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	_, err = h.GetOrderbookWithContext(ctx, "SOLUSDC", 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = h.PostSubmitWithContext(ctx, "tx", false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// bodies are closed on success and on error
+	h = provider.NewHTTPClientWithOpts(client, provider.RPCOpts{Endpoint: s.HTTPEndpoint, PrivateKey: &pk})
+	ctx, cancel = context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	_, err = h.GetOrderbookWithContext(ctx, "SOLUSDC", 0)
+	require.Nil(t, err)
+	_, err = h.GetOrderbookWithContext(ctx, "market-doesnt-exist", 0)
+	require.NotNil(t, err)
+	_, err = h.SubmitOrderWithContext(ctx, pk.PublicKey().String(), pk.PublicKey().String(), "SOLUSDC", pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	require.Nil(t, err)
+
+	// the methods without a context still work
+	_, err = h.GetOrderbook("SOLUSDC", 0)
+	require.Nil(t, err)
+	_, err = h.SubmitOrder(pk.PublicKey().String(), pk.PublicKey().String(), "SOLUSDC", pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	require.Nil(t, err)
+
+	tracker.m.Lock()
+	defer tracker.m.Unlock()
+	assert.Equal(t, 0, tracker.open)
+}
@@ -12,8 +12,7 @@ import (
 
 // Unary response
 func TestGRPCClient_Requests(t *testing.T) {
-	g, err := provider.NewGRPCClient()
-	require.Nil(t, err)
+	g := grpcProviderForEnv(t)
 
 	t.Run("orderbook", func(t *testing.T) {
 		testGetOrderbook(
@@ -104,19 +103,18 @@ func TestGRPCClient_Requests(t *testing.T) {
 
 // Stream response
 func TestGRPCClient_Streams(t *testing.T) {
-	g, err := provider.NewGRPCClient()
-	require.Nil(t, err)
+	g := grpcProviderForEnv(t)
 
 	t.Run("orderbook stream", func(t *testing.T) {
 		testGetOrderbookStream(
 			t,
 			func(ctx context.Context, markets []string, limit uint32, orderbookCh chan *pb.GetOrderbooksStreamResponse) {
-				err := g.GetOrderbookStream(ctx, markets, limit, orderbookCh)
+				err := g.GetOrderbooksStream(ctx, markets, limit, orderbookCh)
 				require.Nil(t, err)
 			},
 			func(ctx context.Context, markets []string, limit uint32) string {
 				orderbookCh := make(chan *pb.GetOrderbooksStreamResponse)
-				err := g.GetOrderbookStream(ctx, markets, limit, orderbookCh)
+				err := g.GetOrderbooksStream(ctx, markets, limit, orderbookCh)
 				require.NotNil(t, err)
 
 				grpcStatus, ok := status.FromError(err)
@@ -11,7 +11,7 @@ import (
 )
 
 func TestHTTPClient_Requests(t *testing.T) {
-	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIHTTP)
+	opts := provider.DefaultRPCOpts(httpEndpointForEnv())
 	opts.Timeout = 60 * time.Second
 	h := provider.NewHTTPClientWithOpts(nil, opts)
 
@@ -19,13 +19,13 @@ func TestHTTPClient_Requests(t *testing.T) {
 		testGetOrderbook(
 			t,
 			func(ctx context.Context, market string, limit uint32) *pb.GetOrderbookResponse {
-				orderbook, err := h.GetOrderbook(market, limit)
+				orderbook, err := h.GetOrderbookWithContext(ctx, market, limit)
 				require.Nil(t, err)
 
 				return orderbook
 			},
 			func(ctx context.Context, market string, limit uint32) string {
-				_, err := h.GetOrderbook(market, limit)
+				_, err := h.GetOrderbookWithContext(ctx, market, limit)
 				require.NotNil(t, err)
 
 				return err.Error()
@@ -37,7 +37,7 @@ func TestHTTPClient_Requests(t *testing.T) {
 		testGetMarkets(
 			t,
 			func(ctx context.Context) *pb.GetMarketsResponse {
-				markets, err := h.GetMarkets()
+				markets, err := h.GetMarketsWithContext(ctx)
 				require.Nil(t, err)
 
 				return markets
@@ -49,7 +49,7 @@ func TestHTTPClient_Requests(t *testing.T) {
 		testGetOpenOrders(
 			t,
 			func(ctx context.Context, market string, owner string) *pb.GetOpenOrdersResponse {
-				orders, err := h.GetOpenOrders(market, owner)
+				orders, err := h.GetOpenOrdersWithContext(ctx, market, owner)
 				require.Nil(t, err)
 				return orders
 			},
@@ -60,7 +60,7 @@ func TestHTTPClient_Requests(t *testing.T) {
 		testUnsettled(
 			t,
 			func(ctx context.Context, market string, owner string) *pb.GetUnsettledResponse {
-				response, err := h.GetUnsettled(market, owner)
+				response, err := h.GetUnsettledWithContext(ctx, market, owner)
 				require.Nil(t, err)
 				return response
 			},
@@ -71,9 +71,9 @@ func TestHTTPClient_Requests(t *testing.T) {
 		testGetTickers(
 			t,
 			func(ctx context.Context, market string) *pb.GetTickersResponse {
-				_, _ = h.GetOrderbook(market, 1) // warm up ticker
+				_, _ = h.GetOrderbookWithContext(ctx, market, 1) // warm up ticker
 
-				tickers, err := h.GetTickers(market)
+				tickers, err := h.GetTickersWithContext(ctx, market)
 				require.Nil(t, err)
 				return tickers
 			})
@@ -83,12 +83,12 @@ func TestHTTPClient_Requests(t *testing.T) {
 		testSubmitOrder(
 			t,
 			func(ctx context.Context, owner, payer, market string, side pb.Side, amount, price float64, opts provider.PostOrderOpts) string {
-				txHash, err := h.SubmitOrder(owner, payer, market, side, []pb.OrderType{pb.OrderType_OT_LIMIT}, amount, price, opts)
+				txHash, err := h.SubmitOrderWithContext(ctx, owner, payer, market, side, []pb.OrderType{pb.OrderType_OT_LIMIT}, amount, price, opts)
 				require.Nil(t, err, "unexpected error %v", err)
 				return txHash
 			},
 			func(ctx context.Context, owner, payer, market string, side pb.Side, amount, price float64, opts provider.PostOrderOpts) string {
-				_, err := h.SubmitOrder(owner, payer, market, side, []pb.OrderType{pb.OrderType_OT_LIMIT}, amount, price, opts)
+				_, err := h.SubmitOrderWithContext(ctx, owner, payer, market, side, []pb.OrderType{pb.OrderType_OT_LIMIT}, amount, price, opts)
 				require.NotNil(t, err)
 
 				return err.Error()
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockBlockInterval = 100 * time.Millisecond
+	// mockOwner has an open order and unsettled funds on SOL/USDC, like the account used against the live API
+	mockOwner = "AFT8VayE7qr8MoQsW3wHsDS83HhEvhGWdbNSHRKeUDfQ"
+)
+
+// mockServer is set when running with SERUM_API_ENV=mock
+var mockServer *mock.Server
+
+func isMockEnv() bool {
+	return os.Getenv("SERUM_API_ENV") == mockEnv
+}
+
+func TestMain(m *testing.M) {
+	if !isMockEnv() {
+		os.Exit(m.Run())
+	}
+
+	privateKey, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		panic(err)
+	}
+	publicKey = privateKey.PublicKey().String()
+	if err := os.Setenv("PRIVATE_KEY", privateKey.String()); err != nil {
+		panic(err)
+	}
+
+	api, err := newMockAPI()
+	if err != nil {
+		panic(err)
+	}
+	mockServer, err = mock.NewServer(api)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go api.ProduceBlocks(ctx, mockBlockInterval)
+
+	code := m.Run()
+	cancel()
+	_ = mockServer.Close()
+	os.Exit(code)
+}
+
+// newMockAPI scripts the markets and accounts that the integration suites expect from the live API
+func newMockAPI() (*mock.API, error) {
+	api := mock.NewAPI()
+	api.AddMarket("SOL/USDC", "9wFFyRfZBsuAha4YcuxcXLKwMxJR43S7fPfQLusDBzvT", 0.1)
+	api.AddMarket("SOL/USDT", "HWHvQhFmJB3NUcu1aihKmrKegfVxBEHzwVX6yZCKEsi1", 0.1)
+	api.AddMarket("MATH/USDT", "CkvNfATB7nky8zPLuwS9bgcFbVRkQdkd5zuKEovyo9rs", 0.1)
+	api.AddMarket("SWAG/USDT", "6URQ4zFWvPm1fhJCKKWorrh8X3mmTFiDDyXEUmSf8Rb2", 0.1)
+
+	err := api.SetOrderbook("SOL/USDC",
+		[]*pb.OrderbookItem{{Price: 39.9, Size: 10}, {Price: 39.8, Size: 20}, {Price: 39.7, Size: 30}},
+		[]*pb.OrderbookItem{{Price: 40.1, Size: 10}, {Price: 40.2, Size: 20}, {Price: 40.3, Size: 30}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.AddTrades("SOL/USDC", &pb.Trade{Side: pb.Side_S_BID, Size: 1, Price: 40}); err != nil {
+		return nil, err
+	}
+
+	_, err = api.AddOrder(mockOwner, &pb.Order{
+		Market:        "SOL/USDC",
+		Side:          pb.Side_S_BID,
+		Types:         []pb.OrderType{pb.OrderType_OT_LIMIT},
+		Price:         30,
+		RemainingSize: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.SetUnsettled("SOL/USDC", mockOwner, &pb.UnsettledAccount{
+		Account:    solana.NewWallet().PublicKey().String(),
+		BaseToken:  &pb.UnsettledAccountToken{Address: "So11111111111111111111111111111111111111112", Amount: 0.5},
+		QuoteToken: &pb.UnsettledAccountToken{Address: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Amount: 20},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return api, nil
+}
+
+func grpcProviderForEnv(t *testing.T) *provider.GRPCClient {
+	if isMockEnv() {
+		g, err := provider.NewGRPCClientWithOpts(provider.DefaultRPCOpts(mockServer.GRPCEndpoint))
+		require.Nil(t, err)
+		return g
+	}
+
+	g, err := provider.NewGRPCClient()
+	require.Nil(t, err)
+	return g
+}
+
+func httpEndpointForEnv() string {
+	if isMockEnv() {
+		return mockServer.HTTPEndpoint
+	}
+	return provider.MainnetSerumAPIHTTP
+}
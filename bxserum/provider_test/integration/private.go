@@ -22,6 +22,9 @@ const (
 	txConfirmationAttempts = 3
 	txConfirmationTimeout  = 5 * time.Second
 	submitGoodOrders       = false
+
+	// mockEnv runs the suites against an in-process mock server instead of the live API
+	mockEnv = "mock"
 )
 
 var publicKey string
@@ -29,6 +32,11 @@ var publicKey string
 func init() {
 	var ok bool
 
+	// the mock server generates its own keypair
+	if os.Getenv("SERUM_API_ENV") == mockEnv {
+		return
+	}
+
 	publicKey, ok = os.LookupEnv("PUBLIC_KEY")
 	if !ok {
 		fmt.Printf("env variable `PUBLIC_KEY` must be set")
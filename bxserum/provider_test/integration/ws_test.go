@@ -17,6 +17,10 @@ func providerForEnv(t *testing.T) *provider.WSClient {
 	}
 
 	switch env {
+	case mockEnv:
+		w, err := provider.NewWSClientWithOpts(provider.DefaultRPCOpts(mockServer.WSEndpoint))
+		require.Nil(t, err)
+		return w
 	case "test":
 		w, err := provider.NewWSClientTestnet()
 		require.Nil(t, err)
@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	bxslog "github.com/bloXroute-Labs/serum-client-go/bxserum/logger/slog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging_Slog(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	log := bxslog.New(l).With(logger.Fields{"transport": "grpc"})
+
+	log.Debug("subscribed", logger.Fields{"stream": "GetTickersStream"})
+	log.Error("closing websocket connection", logger.Fields{"error": "unknown subscription ID"})
+
+	// debug entries are below the handler's level
+	var entry map[string]interface{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "closing websocket connection", entry["msg"])
+	assert.Equal(t, "grpc", entry["transport"])
+	assert.Equal(t, "unknown subscription ID", entry["error"])
+}
@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/logger"
+	bxlogrus "github.com/bloXroute-Labs/serum-client-go/bxserum/logger/logrus"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields logger.Fields
+}
+
+// logRecorder collects log entries, including the ones of the loggers derived from it
+type logRecorder struct {
+	m       *sync.Mutex
+	entries *[]logEntry
+	fields  logger.Fields
+}
+
+func newLogRecorder() logRecorder {
+	return logRecorder{m: &sync.Mutex{}, entries: &[]logEntry{}, fields: logger.Fields{}}
+}
+
+func (r logRecorder) add(level, msg string, fields logger.Fields) {
+	all := logger.Fields{}
+	for k, v := range r.fields {
+		all[k] = v
+	}
+	for k, v := range fields {
+		all[k] = v
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	*r.entries = append(*r.entries, logEntry{level: level, msg: msg, fields: all})
+}
+
+func (r logRecorder) Debug(msg string, fields logger.Fields) { r.add("debug", msg, fields) }
+
+func (r logRecorder) Info(msg string, fields logger.Fields) { r.add("info", msg, fields) }
+
+func (r logRecorder) Warn(msg string, fields logger.Fields) { r.add("warn", msg, fields) }
+
+func (r logRecorder) Error(msg string, fields logger.Fields) { r.add("error", msg, fields) }
+
+func (r logRecorder) With(fields logger.Fields) logger.Logger {
+	derived := logRecorder{m: r.m, entries: r.entries, fields: logger.Fields{}}
+	for k, v := range r.fields {
+		derived.fields[k] = v
+	}
+	for k, v := range fields {
+		derived.fields[k] = v
+	}
+	return derived
+}
+
+// find returns the first entry with the message, if any
+func (r logRecorder) find(msg string) (logEntry, bool) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, entry := range *r.entries {
+		if entry.msg == msg {
+			return entry, true
+		}
+	}
+	return logEntry{}, false
+}
+
+func TestLogging_Transports(t *testing.T) {
+	for _, transport := range []string{"ws", "grpc"} {
+		t.Run(transport, func(t *testing.T) {
+			_, s := newMockAPI(t)
+			log := newLogRecorder()
+			opts := provider.RPCOpts{Timeout: conformanceTimeout, Logger: log}
+
+			var client provider.StreamingClient
+			if transport == "ws" {
+				opts.Endpoint = s.WSEndpoint
+				w, err := provider.NewWSClientWithOpts(opts)
+				require.Nil(t, err)
+				client = w
+
+				entry, ok := log.find("websocket connected")
+				require.True(t, ok)
+				assert.Equal(t, "info", entry.level)
+				assert.Equal(t, s.WSEndpoint, entry.fields["endpoint"])
+			} else {
+				opts.Endpoint = s.GRPCEndpoint
+				g, err := provider.NewGRPCClientWithOpts(opts)
+				require.Nil(t, err)
+				client = g
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			// errors returned by the API are logged with the request
+			_, err := client.GetOrderbook(ctx, "BTC/USDC", 0)
+			require.NotNil(t, err)
+			entry, ok := log.find("request rejected")
+			require.True(t, ok)
+			assert.Equal(t, "info", entry.level)
+			assert.Equal(t, "GetOrderbook", entry.fields["method"])
+			assert.Equal(t, transport, entry.fields["transport"])
+
+			streamCtx, streamCancel := context.WithCancel(ctx)
+			tickersCh := make(chan *pb.GetTickersStreamResponse, 10)
+			require.Nil(t, client.GetTickersStream(streamCtx, standinMarket, tickersCh))
+			entry, ok = log.find("subscribed")
+			require.True(t, ok)
+			assert.Equal(t, "debug", entry.level)
+			assert.Equal(t, "GetTickersStream", entry.fields["stream"])
+
+			streamCancel()
+			// the GRPC stream only notices it ended on the next read
+			go func() {
+				for range tickersCh {
+				}
+			}()
+			assert.Eventually(t, func() bool {
+				_, ok := log.find("unsubscribed")
+				return ok
+			}, conformanceTimeout, 10*time.Millisecond)
+
+			require.Nil(t, client.Close())
+			if transport == "ws" {
+				entry, ok = log.find("websocket connection closed")
+				require.True(t, ok)
+				assert.Equal(t, "info", entry.level)
+			}
+		})
+	}
+}
+
+func TestLogging_WSUnknownSubscription(t *testing.T) {
+	// a server that sends an update for a subscription the client never made
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"subscribe","params":{"subscription":"unknown","result":{}}}`))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer s.Close()
+
+	log := newLogRecorder()
+	ws, err := connections.NewWSWithOpts("ws"+strings.TrimPrefix(s.URL, "http"), connections.WSOpts{Logger: log})
+	require.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, ok := log.find("closing websocket connection")
+		return ok
+	}, conformanceTimeout, 10*time.Millisecond)
+	entry, _ := log.find("closing websocket connection")
+	assert.Equal(t, "error", entry.level)
+	assert.Contains(t, entry.fields["error"].(error).Error(), "unknown subscription ID: unknown")
+
+	// the connection is closed for requests too
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	err = ws.Request(ctx, "GetMarkets", &pb.GetMarketsRequest{}, &pb.GetMarketsResponse{})
+	var connErr *connections.ConnectionError
+	assert.ErrorAs(t, err, &connErr)
+}
+
+func TestLogging_Logrus(t *testing.T) {
+	l, hook := logrustest.NewNullLogger()
+	l.SetLevel(logrus.DebugLevel)
+	log := bxlogrus.New(l).With(logger.Fields{"transport": "ws"})
+
+	log.Debug("subscribed", logger.Fields{"stream": "GetTickersStream"})
+	log.Warn("request failed", logger.Fields{"method": "GetMarkets"})
+
+	require.Equal(t, 2, len(hook.AllEntries()))
+	entry := hook.AllEntries()[0]
+	assert.Equal(t, logrus.DebugLevel, entry.Level)
+	assert.Equal(t, "subscribed", entry.Message)
+	assert.Equal(t, logrus.Fields{"transport": "ws", "stream": "GetTickersStream"}, entry.Data)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "GetMarkets", hook.LastEntry().Data["method"])
+}
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/metrics"
+	bxprometheus "github.com/bloXroute-Labs/serum-client-go/bxserum/metrics/prometheus"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricsRecorder collects the measurements of a client
+type metricsRecorder struct {
+	m             sync.Mutex
+	requests      map[string]int
+	errors        map[string]int
+	subscriptions map[string]int
+	messages      map[string]int
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{
+		requests:      make(map[string]int),
+		errors:        make(map[string]int),
+		subscriptions: make(map[string]int),
+		messages:      make(map[string]int),
+	}
+}
+
+func (r *metricsRecorder) ObserveRequest(_ bxerrors.Transport, method string, _ time.Duration, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.requests[method]++
+	if err != nil {
+		r.errors[metrics.ErrorType(err)]++
+	}
+}
+
+func (r *metricsRecorder) AddSubscriptions(_ bxerrors.Transport, stream string, delta int) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.subscriptions[stream] += delta
+}
+
+func (r *metricsRecorder) ObserveStreamMessage(_ bxerrors.Transport, stream string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.messages[stream]++
+}
+
+func (r *metricsRecorder) SetWSWriteQueueDepth(int) {}
+
+func (r *metricsRecorder) get(values map[string]int, key string) int {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return values[key]
+}
+
+// orderbookStreamer is implemented by the clients of every transport, including HTTP polling
+type orderbookStreamer interface {
+	provider.Client
+	GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error
+}
+
+func TestMetrics_Transports(t *testing.T) {
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T, s *mock.Server, opts provider.RPCOpts) orderbookStreamer
+	}{
+		{"http", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) orderbookStreamer {
+			opts.Endpoint = s.HTTPEndpoint
+			opts.HTTPPollInterval = 10 * time.Millisecond
+			return provider.NewHTTPClientWithOpts(nil, opts).Client()
+		}},
+		{"ws", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) orderbookStreamer {
+			opts.Endpoint = s.WSEndpoint
+			w, err := provider.NewWSClientWithOpts(opts)
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) orderbookStreamer {
+			opts.Endpoint = s.GRPCEndpoint
+			g, err := provider.NewGRPCClientWithOpts(opts)
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			api, s := newMockAPI(t)
+			recorder := newMetricsRecorder()
+			client := c.newClient(t, s, provider.RPCOpts{Timeout: conformanceTimeout, Metrics: recorder})
+			defer func() {
+				_ = client.Close()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			_, err := client.GetOrderbook(ctx, standinMarket, 0)
+			require.Nil(t, err)
+			_, err = client.GetOrderbook(ctx, "BTC/USDC", 0)
+			require.NotNil(t, err)
+			assert.Equal(t, 2, recorder.get(recorder.requests, "GetOrderbook"))
+			assert.Equal(t, 1, recorder.get(recorder.errors, "market_not_found"))
+
+			streamCtx, streamCancel := context.WithCancel(ctx)
+			orderbookCh := make(chan *pb.GetOrderbooksStreamResponse, 10)
+			require.Nil(t, client.GetOrderbooksStream(streamCtx, []string{standinMarket}, 0, orderbookCh))
+			bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout)
+			assert.Equal(t, 1, recorder.get(recorder.subscriptions, "GetOrderbooksStream"))
+
+			require.Nil(t, api.SetOrderbook(standinMarket, []*pb.OrderbookItem{{Price: 100, Size: 1}}, nil))
+			api.AdvanceBlock()
+			bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout)
+			assert.GreaterOrEqual(t, recorder.get(recorder.messages, "GetOrderbooksStream"), 2)
+
+			streamCancel()
+			assert.Eventually(t, func() bool {
+				return recorder.get(recorder.subscriptions, "GetOrderbooksStream") == 0
+			}, conformanceTimeout, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestMetrics_Prometheus(t *testing.T) {
+	_, s := newMockAPI(t)
+	registry := prometheus.NewRegistry()
+	m, err := bxprometheus.New(registry, bxprometheus.Opts{})
+	require.Nil(t, err)
+
+	w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout, Metrics: m})
+	require.Nil(t, err)
+	defer func() {
+		_ = w.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	_, err = w.GetMarkets(ctx)
+	require.Nil(t, err)
+	_, err = w.GetOrderbook(ctx, "BTC/USDC", 0)
+	require.NotNil(t, err)
+
+	expected := `
+# HELP serum_client_request_errors_total Failed requests to Serum API, by transport, method and error type
+# TYPE serum_client_request_errors_total counter
+serum_client_request_errors_total{method="GetOrderbook",transport="ws",type="market_not_found"} 1
+# HELP serum_client_requests_total Requests sent to Serum API, by transport and method
+# TYPE serum_client_requests_total counter
+serum_client_requests_total{method="GetMarkets",transport="ws"} 1
+serum_client_requests_total{method="GetOrderbook",transport="ws"} 1
+# HELP serum_client_ws_write_queue_depth Messages waiting to be written to the websocket connection
+# TYPE serum_client_ws_write_queue_depth gauge
+serum_client_ws_write_queue_depth 0
+`
+	assert.Nil(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"serum_client_requests_total", "serum_client_request_errors_total", "serum_client_ws_write_queue_depth"))
+	count, err := testutil.GatherAndCount(registry, "serum_client_request_duration_seconds")
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	// collectors can only be registered once per registry
+	_, err = bxprometheus.New(registry, bxprometheus.Opts{})
+	assert.NotNil(t, err)
+}
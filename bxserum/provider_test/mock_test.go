@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockAPI(t *testing.T) (*mock.API, *mock.Server) {
+	api := mock.NewAPI()
+	api.AddMarket(standinMarket, standinMarketAddress, 0.1)
+	err := api.SetOrderbook(standinMarket,
+		[]*pb.OrderbookItem{{Price: 99, Size: 1}, {Price: 98, Size: 2}},
+		[]*pb.OrderbookItem{{Price: 101, Size: 1}, {Price: 102, Size: 2}},
+	)
+	require.Nil(t, err)
+
+	s, err := mock.NewServer(api)
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return api, s
+}
+
+func TestMock_OrderLifecycle(t *testing.T) {
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+	payer := solana.NewWallet().PublicKey().String()
+
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T, s *mock.Server) provider.Client
+	}{
+		{"http", func(t *testing.T, s *mock.Server) provider.Client {
+			return provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk}).Client()
+		}},
+		{"ws", func(t *testing.T, s *mock.Server) provider.Client {
+			w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T, s *mock.Server) provider.Client {
+			g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			api, s := newMockAPI(t)
+			client := c.newClient(t, s)
+			defer func() {
+				assert.Nil(t, client.Close())
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			statusCh := make(chan *pb.GetOrderStatusStreamResponse, 10)
+			// HTTP streams poll the server instead of following its blocks, see TestHTTP_Streams
+			streamingClient, streaming := client.(provider.StreamingClient)
+			if _, polling := client.(*provider.HTTPContextClient); polling {
+				streaming = false
+			}
+			if streaming {
+				// the GRPC client only returns once the first update arrives
+				go func() {
+					assert.Nil(t, streamingClient.GetOrderStatusStream(ctx, "SOLUSDC", owner, statusCh))
+				}()
+				require.Eventually(t, func() bool { return api.Subscribers() == 1 }, conformanceTimeout, 10*time.Millisecond)
+			}
+
+			// orders that fail simulation are rejected and never open
+			_, err := client.SubmitOrder(ctx, owner, payer, "SOLUSDC", pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 0.01, 98, provider.PostOrderOpts{})
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), "Transaction simulation failed")
+
+			_, err = client.SubmitOrder(ctx, owner, payer, "SOLUSDC", pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 98, provider.PostOrderOpts{ClientOrderID: 7})
+			require.Nil(t, err)
+			assert.Equal(t, 1, len(api.Submissions()))
+
+			openOrders, err := client.GetOpenOrders(ctx, "SOLUSDC", owner)
+			require.Nil(t, err)
+			require.Equal(t, 1, len(openOrders.Orders))
+			order := openOrders.Orders[0]
+			assert.Equal(t, "7", order.ClientOrderID)
+			assert.Equal(t, float64(1), order.RemainingSize)
+
+			require.Nil(t, api.FillOrder(order.OrderID, 0.4))
+			_, err = client.SubmitCancelByClientOrderID(ctx, 7, owner, "SOLUSDC", order.OpenOrderAccount, false)
+			require.Nil(t, err)
+
+			openOrders, err = client.GetOpenOrders(ctx, "SOLUSDC", owner)
+			require.Nil(t, err)
+			assert.Equal(t, 0, len(openOrders.Orders))
+
+			trades, err := client.GetTrades(ctx, "SOLUSDC", 0)
+			require.Nil(t, err)
+			require.Equal(t, 1, len(trades.Trades))
+			assert.Equal(t, 0.4, trades.Trades[0].Size)
+
+			if !streaming {
+				return
+			}
+			height := api.AdvanceBlock()
+			for _, expected := range []pb.OrderStatus{pb.OrderStatus_OS_OPEN, pb.OrderStatus_OS_PARTIAL_FILL, pb.OrderStatus_OS_CANCELLED} {
+				update := bxassert.ReadChanWithTimeout(t, statusCh, conformanceTimeout)
+				assert.Equal(t, height, update.BlockHeight)
+				assert.Equal(t, expected, update.OrderInfo.OrderStatus)
+				assert.Equal(t, uint64(7), update.OrderInfo.ClientOrderID)
+			}
+		})
+	}
+}
+
+func TestMock_MarketDepthStream(t *testing.T) {
+	api, s := newMockAPI(t)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	depthCh := make(chan *pb.GetMarketDepthStreamResponse, 10)
+	require.Nil(t, g.GetMarketDepthStream(ctx, depthCh))
+
+	snapshot := bxassert.ReadChanWithTimeout(t, depthCh, conformanceTimeout)
+	assert.Equal(t, int64(0), snapshot.Tick.PrevBlockHeight)
+	assert.Equal(t, 2, len(snapshot.Tick.Bids))
+	assert.Equal(t, 2, len(snapshot.Tick.Asks))
+
+	err = api.SetOrderbook(standinMarket,
+		[]*pb.OrderbookItem{{Price: 99, Size: 3}, {Price: 98, Size: 2}},
+		[]*pb.OrderbookItem{{Price: 102, Size: 2}},
+	)
+	require.Nil(t, err)
+	height := api.AdvanceBlock()
+
+	tick := bxassert.ReadChanWithTimeout(t, depthCh, conformanceTimeout)
+	assert.Equal(t, height, tick.BlockHeight)
+	assert.Equal(t, snapshot.BlockHeight, tick.Tick.PrevBlockHeight)
+	require.Equal(t, 1, len(tick.Tick.Bids))
+	assert.Equal(t, float64(99), tick.Tick.Bids[0].Price)
+	assert.Equal(t, float64(3), tick.Tick.Bids[0].Size)
+	require.Equal(t, 1, len(tick.Tick.Asks))
+	assert.Equal(t, float64(101), tick.Tick.Asks[0].Price)
+	assert.Equal(t, float64(0), tick.Tick.Asks[0].Size)
+}
@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/orderbook"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orderbookUpdate(blockHeight int64, bids, asks []*pb.OrderbookItem) *pb.GetOrderbooksStreamResponse {
+	return &pb.GetOrderbooksStreamResponse{
+		BlockHeight: blockHeight,
+		Orderbook:   &pb.GetOrderbookResponse{Market: standinMarket, MarketAddress: standinMarketAddress, Bids: bids, Asks: asks},
+	}
+}
+
+func depthUpdate(blockHeight, prevBlockHeight int64, bids, asks []*pb.OrderbookItem) *pb.GetMarketDepthStreamResponse {
+	return &pb.GetMarketDepthStreamResponse{
+		BlockHeight: blockHeight,
+		Tick:        &pb.MarketDepthTick{PrevBlockHeight: prevBlockHeight, Bids: bids, Asks: asks},
+	}
+}
+
+func TestOrderbook_ApplyOrderbook(t *testing.T) {
+	var changes []orderbook.LevelChange
+	var gaps []*orderbook.GapError
+	m := orderbook.NewManager(orderbook.Opts{
+		OnLevelChange: func(change orderbook.LevelChange) { changes = append(changes, change) },
+		OnGap:         func(gap *orderbook.GapError) { gaps = append(gaps, gap) },
+	})
+
+	_, err := m.ApplyOrderbook(orderbookUpdate(10,
+		[]*pb.OrderbookItem{{Price: 98, Size: 2}, {Price: 99, Size: 1}, {Price: 97, Size: 3}},
+		[]*pb.OrderbookItem{{Price: 101, Size: 1}, {Price: 102, Size: 2}, {Price: 103, Size: 3}},
+	))
+	require.Nil(t, err)
+	assert.Len(t, changes, 6)
+
+	book, ok := m.Book(standinMarketAddress)
+	require.True(t, ok)
+	assert.Equal(t, int64(10), book.BlockHeight())
+	bestBid, ok := book.BestBid()
+	require.True(t, ok)
+	assert.Equal(t, orderbook.Level{Price: 99, Size: 1}, bestBid)
+	bestAsk, ok := book.BestAsk()
+	require.True(t, ok)
+	assert.Equal(t, orderbook.Level{Price: 101, Size: 1}, bestAsk)
+	assert.Equal(t, []orderbook.Level{{Price: 99, Size: 1}, {Price: 98, Size: 2}}, book.Bids(2))
+	assert.Equal(t, 3.0, book.Depth(pb.Side_S_BID, 98))
+	assert.Equal(t, 6.0, book.Depth(pb.Side_S_ASK, 103))
+
+	// buying 2 fills 1 at 101 and 1 at 102
+	vwap, err := book.VWAP(pb.Side_S_BID, 2)
+	require.Nil(t, err)
+	assert.Equal(t, 101.5, vwap)
+	_, err = book.VWAP(pb.Side_S_ASK, 7)
+	assert.Equal(t, orderbook.ErrInsufficientLiquidity, err)
+	for _, size := range []float64{0, -1, math.NaN()} {
+		_, err = book.VWAP(pb.Side_S_BID, size)
+		assert.ErrorIs(t, err, orderbook.ErrInvalidSize)
+	}
+
+	// only the levels that differ are reported, and snapshots may skip blocks
+	changes = nil
+	_, err = m.ApplyOrderbook(orderbookUpdate(12,
+		[]*pb.OrderbookItem{{Price: 99, Size: 5}, {Price: 98, Size: 2}, {Price: 97, Size: 3}},
+		[]*pb.OrderbookItem{{Price: 102, Size: 2}, {Price: 103, Size: 3}},
+	))
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []orderbook.LevelChange{
+		{Market: standinMarket, BlockHeight: 12, Side: pb.Side_S_BID, Price: 99, Size: 5, PrevSize: 1},
+		{Market: standinMarket, BlockHeight: 12, Side: pb.Side_S_ASK, Price: 101, Size: 0, PrevSize: 1},
+	}, changes)
+	assert.Empty(t, gaps)
+
+	// out of order updates are rejected
+	_, err = m.ApplyOrderbook(orderbookUpdate(11, nil, nil))
+	assert.True(t, errors.Is(err, orderbook.ErrStaleUpdate))
+	assert.Equal(t, int64(12), book.BlockHeight())
+
+	// updates without a block height replace the book at its height
+	_, err = m.ApplyOrderbook(orderbookUpdate(0, []*pb.OrderbookItem{{Price: 99, Size: 5}}, nil))
+	require.Nil(t, err)
+	assert.Equal(t, int64(12), book.BlockHeight())
+	assert.Equal(t, []orderbook.Level{{Price: 99, Size: 5}}, book.Bids(0))
+	assert.Empty(t, book.Asks(0))
+}
+
+func TestOrderbook_MarketDepthResync(t *testing.T) {
+	api, s := newMockAPI(t)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	var gaps []*orderbook.GapError
+	m := orderbook.NewManager(orderbook.Opts{OnGap: func(gap *orderbook.GapError) { gaps = append(gaps, gap) }})
+
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(5, 0,
+		[]*pb.OrderbookItem{{Price: 99, Size: 1}},
+		[]*pb.OrderbookItem{{Price: 101, Size: 1}},
+	))
+	require.Nil(t, err)
+	changes, err := m.ApplyMarketDepth(standinMarket, depthUpdate(6, 5, []*pb.OrderbookItem{{Price: 99, Size: 0}, {Price: 98, Size: 2}}, nil))
+	require.Nil(t, err)
+	assert.Len(t, changes, 2)
+
+	// a tick that doesn't follow block 6 leaves the book out of sync until it is resynced
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(9, 8, nil, []*pb.OrderbookItem{{Price: 100, Size: 1}}))
+	var gapErr *orderbook.GapError
+	require.True(t, errors.As(err, &gapErr))
+	assert.Equal(t, int64(6), gapErr.BlockHeight)
+	assert.Equal(t, int64(8), gapErr.PrevBlockHeight)
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(10, 9, nil, nil))
+	require.True(t, errors.As(err, &gapErr))
+	assert.Len(t, gaps, 1)
+
+	// the book moves to the height of the snapshot, so ticks it already includes are dropped
+	var height int64
+	for height < 12 {
+		height = api.AdvanceBlock()
+	}
+	_, err = m.Resync(ctx, g, standinMarket)
+	require.Nil(t, err)
+	book, ok := m.Book(standinMarket)
+	require.True(t, ok)
+	expected, err := api.GetOrderbook(ctx, &pb.GetOrderbookRequest{Market: standinMarket})
+	require.Nil(t, err)
+	assert.Equal(t, expected.Bids, book.Snapshot().Bids)
+	assert.Equal(t, expected.Asks, book.Snapshot().Asks)
+	assert.Equal(t, height, book.BlockHeight())
+
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(11, 10, []*pb.OrderbookItem{{Price: 99, Size: 4}}, nil))
+	assert.True(t, errors.Is(err, orderbook.ErrStaleUpdate))
+	assert.Equal(t, expected.Bids, book.Snapshot().Bids)
+
+	// the next tick only has to be newer than the snapshot
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(height+1, 11, []*pb.OrderbookItem{{Price: 99, Size: 4}}, nil))
+	require.Nil(t, err)
+	bestBid, _ := book.BestBid()
+	assert.Equal(t, orderbook.Level{Price: 99, Size: 4}, bestBid)
+	assert.Equal(t, height+1, book.BlockHeight())
+}
+
+func TestOrderbook_ResyncWithoutBlockHeight(t *testing.T) {
+	api, s := newMockAPI(t)
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout}).Client()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	m := orderbook.NewManager(orderbook.Opts{})
+	_, err := m.ApplyMarketDepth(standinMarket, depthUpdate(5, 0, []*pb.OrderbookItem{{Price: 99, Size: 1}}, nil))
+	require.Nil(t, err)
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(9, 8, nil, nil))
+	var gapErr *orderbook.GapError
+	require.True(t, errors.As(err, &gapErr))
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(10, 9, nil, nil))
+	require.True(t, errors.As(err, &gapErr))
+
+	// HTTP snapshots have no block height, so the book moves to the newest tick received, which the snapshot is newer
+	// than
+	_, err = m.Resync(ctx, h, standinMarket)
+	require.Nil(t, err)
+	book, _ := m.Book(standinMarket)
+	assert.Equal(t, int64(10), book.BlockHeight())
+	expected, err := api.GetOrderbook(ctx, &pb.GetOrderbookRequest{Market: standinMarket})
+	require.Nil(t, err)
+	assert.Equal(t, expected.Bids, book.Snapshot().Bids)
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(10, 9, []*pb.OrderbookItem{{Price: 99, Size: 4}}, nil))
+	assert.True(t, errors.Is(err, orderbook.ErrStaleUpdate))
+
+	// but the next tick is applied over the snapshot even if it was taken after that tick's block, which leaves the
+	// levels it changes stale until the following ticks change them
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(11, 10, []*pb.OrderbookItem{{Price: 99, Size: 4}}, nil))
+	require.Nil(t, err)
+	bestBid, _ := book.BestBid()
+	assert.Equal(t, orderbook.Level{Price: 99, Size: 4}, bestBid)
+	_, err = m.ApplyMarketDepth(standinMarket, depthUpdate(12, 11, []*pb.OrderbookItem{{Price: 99, Size: 1}}, nil))
+	require.Nil(t, err)
+	bestBid, _ = book.BestBid()
+	assert.Equal(t, orderbook.Level{Price: 99, Size: 1}, bestBid)
+}
+
+func TestOrderbook_FollowOrderbooksHTTP(t *testing.T) {
+	api, s := newMockAPI(t)
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, HTTPPollInterval: 10 * time.Millisecond}).Client()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	// HTTP streams have no block heights, so every polled orderbook replaces the book
+	m := orderbook.NewManager(orderbook.Opts{})
+	go func() {
+		_ = m.FollowOrderbooks(ctx, h, []string{standinMarket}, 0)
+	}()
+	expected, err := api.GetOrderbook(ctx, &pb.GetOrderbookRequest{Market: standinMarket})
+	require.Nil(t, err)
+	require.Eventually(t, func() bool {
+		book, ok := m.Book(standinMarket)
+		return ok && len(book.Snapshot().Bids) == len(expected.Bids)
+	}, conformanceTimeout, 10*time.Millisecond)
+	book, _ := m.Book(standinMarket)
+	assert.Equal(t, expected.Bids, book.Snapshot().Bids)
+	assert.Equal(t, expected.Asks, book.Snapshot().Asks)
+
+	bids := []*pb.OrderbookItem{{Price: 99.5, Size: 3}}
+	require.Nil(t, api.SetOrderbook(standinMarket, bids, expected.Asks))
+	require.Eventually(t, func() bool {
+		bestBid, ok := book.BestBid()
+		return ok && bestBid.Price == 99.5
+	}, conformanceTimeout, 10*time.Millisecond)
+	assert.Equal(t, bids, book.Snapshot().Bids)
+	assert.Equal(t, int64(0), book.BlockHeight())
+}
+
+func TestOrderbook_FollowMarketDepth(t *testing.T) {
+	api, s := newMockAPI(t)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	m := orderbook.NewManager(orderbook.Opts{})
+	go func() {
+		_ = m.FollowMarketDepth(ctx, g, standinMarket)
+	}()
+	require.Eventually(t, func() bool { return api.Subscribers() == 1 }, conformanceTimeout, 10*time.Millisecond)
+
+	bids := []*pb.OrderbookItem{{Price: 99.5, Size: 3}, {Price: 98, Size: 2}}
+	asks := []*pb.OrderbookItem{{Price: 100.5, Size: 1}}
+	require.Nil(t, api.SetOrderbook(standinMarket, bids, asks))
+	height := api.AdvanceBlock()
+
+	require.Eventually(t, func() bool {
+		book, ok := m.Book(standinMarket)
+		return ok && book.BlockHeight() == height
+	}, conformanceTimeout, 10*time.Millisecond)
+	book, _ := m.Book(standinMarket)
+	snapshot := book.Snapshot()
+	assert.Equal(t, bids, snapshot.Bids)
+	assert.Equal(t, asks, snapshot.Asks)
+}
+
+func TestOrderbook_FollowStreamEnded(t *testing.T) {
+	_, s := newMockAPI(t)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, HTTPMarketDepthMarket: "BTC/USDC"}).Client()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	m := orderbook.NewManager(orderbook.Opts{})
+
+	// the stream's end error is returned instead of waiting for updates that never come
+	err = m.FollowMarketDepth(ctx, h, "BTC/USDC")
+	assert.ErrorIs(t, err, bxerrors.ErrMarketNotFound)
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- m.FollowOrderbooks(ctx, g, []string{standinMarket}, 0)
+	}()
+	require.Eventually(t, func() bool {
+		book, ok := m.Book(standinMarket)
+		return ok && book.BlockHeight() != 0
+	}, conformanceTimeout, 10*time.Millisecond)
+	require.Nil(t, s.Close())
+	err = bxassert.ReadChanWithTimeout(t, stopped, conformanceTimeout)
+	assert.ErrorIs(t, err, bxerrors.ErrTransportClosed)
+	assert.Nil(t, ctx.Err())
+}
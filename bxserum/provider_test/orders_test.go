@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/orders"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder collects order manager events
+type eventRecorder struct {
+	m      sync.Mutex
+	events []orders.Event
+}
+
+func (r *eventRecorder) record(event orders.Event) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) states(clientOrderID uint64) []orders.State {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	var states []orders.State
+	for _, event := range r.events {
+		if event.Order.ClientOrderID == clientOrderID {
+			states = append(states, event.Order.State)
+		}
+	}
+	return states
+}
+
+func TestOrders_Lifecycle(t *testing.T) {
+	api, s := newMockAPI(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	recorder := &eventRecorder{}
+	m, err := orders.NewManager(g, orders.Opts{
+		Market:             "SOLUSDC",
+		Owner:              owner,
+		Payer:              solana.NewWallet().PublicKey().String(),
+		FirstClientOrderID: 100,
+		OnEvent:            recorder.record,
+	})
+	require.Nil(t, err)
+	go func() {
+		_ = m.Run(ctx, g)
+	}()
+	require.Eventually(t, func() bool { return api.Subscribers() == 1 }, conformanceTimeout, 10*time.Millisecond)
+
+	// rejected submissions fail immediately
+	failed, err := m.Submit(ctx, orders.OrderRequest{Side: pb.Side_S_BID, Types: []pb.OrderType{pb.OrderType_OT_LIMIT}, Amount: 0.01, Price: 98})
+	require.NotNil(t, err)
+	assert.Equal(t, uint64(100), failed.ClientOrderID)
+	assert.Equal(t, orders.StateFailed, failed.State)
+	assert.Equal(t, err, failed.Err)
+
+	placed, err := m.Submit(ctx, orders.OrderRequest{Side: pb.Side_S_BID, Types: []pb.OrderType{pb.OrderType_OT_LIMIT}, Amount: 1, Price: 98})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(101), placed.ClientOrderID)
+	assert.Equal(t, orders.StatePending, placed.State)
+	assert.NotEmpty(t, placed.Signature)
+
+	// the open orders address isn't known until the order is seen on the market
+	_, err = m.Cancel(ctx, placed.ClientOrderID)
+	assert.Equal(t, orders.ErrOpenOrdersUnknown, err)
+
+	api.AdvanceBlock()
+	require.Eventually(t, func() bool {
+		o, _ := m.Order(placed.ClientOrderID)
+		return o.State == orders.StateOpen
+	}, conformanceTimeout, 10*time.Millisecond)
+
+	o, _ := m.Order(placed.ClientOrderID)
+	require.Nil(t, api.FillOrder(o.OrderID, 0.4))
+	_, err = m.Cancel(ctx, placed.ClientOrderID)
+	require.Nil(t, err)
+	api.AdvanceBlock()
+	require.Eventually(t, func() bool {
+		o, _ := m.Order(placed.ClientOrderID)
+		return o.State == orders.StateCancelled
+	}, conformanceTimeout, 10*time.Millisecond)
+
+	o, _ = m.Order(placed.ClientOrderID)
+	assert.True(t, o.CancelRequested)
+	assert.InDelta(t, 0.4, o.FilledSize, 1e-6)
+	assert.Equal(t, []orders.State{orders.StateFailed}, recorder.states(failed.ClientOrderID))
+	assert.Equal(t, []orders.State{orders.StateOpen, orders.StatePartiallyFilled, orders.StateCancelled}, recorder.states(placed.ClientOrderID))
+	assert.Len(t, m.Orders(orders.StateCancelled, orders.StateFailed), 2)
+}
+
+func TestOrders_Reconcile(t *testing.T) {
+	api, s := newMockAPI(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk}).Client()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	// orders left open by a previous run are adopted on startup
+	filledID, err := api.AddOrder(owner, &pb.Order{Market: standinMarket, Side: pb.Side_S_ASK, Price: 101, RemainingSize: 2, ClientOrderID: "7"})
+	require.Nil(t, err)
+	cancelledID, err := api.AddOrder(owner, &pb.Order{Market: standinMarket, Side: pb.Side_S_ASK, Price: 102, RemainingSize: 1, ClientOrderID: "8"})
+	require.Nil(t, err)
+	_, err = api.AddOrder(owner, &pb.Order{Market: standinMarket, Side: pb.Side_S_ASK, Price: 103, RemainingSize: 1})
+	require.Nil(t, err)
+
+	m, err := orders.NewManager(h, orders.Opts{Market: "SOLUSDC", Owner: owner})
+	require.Nil(t, err)
+	require.Nil(t, m.Reconcile(ctx))
+	require.Len(t, m.Orders(orders.StateOpen), 2)
+	o, ok := m.Order(7)
+	require.True(t, ok)
+	assert.Equal(t, filledID, o.OrderID)
+	assert.NotEmpty(t, o.OpenOrdersAddress)
+
+	// changes missed while not following the status stream are found on the next reconciliation
+	require.Nil(t, api.FillOrder(filledID, 2))
+	require.Nil(t, api.CancelOrder(cancelledID))
+	require.Nil(t, m.Reconcile(ctx))
+
+	o, _ = m.Order(7)
+	assert.Equal(t, orders.StateFilled, o.State)
+	assert.Equal(t, 2.0, o.FilledSize)
+	o, _ = m.Order(8)
+	assert.Equal(t, orders.StateCancelled, o.State)
+}
+
+// unlandedClient's order submissions time out waiting for a confirmation without reaching the market
+type unlandedClient struct {
+	provider.Client
+}
+
+func (c unlandedClient) SubmitOrder(context.Context, string, string, string, pb.Side, []pb.OrderType, float64, float64, provider.PostOrderOpts) (string, error) {
+	return "unlanded", &provider.ConfirmationTimeoutError{Signature: "unlanded", Timeout: time.Second}
+}
+
+func TestOrders_ReconcilePending(t *testing.T) {
+	api, s := newMockAPI(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	const pendingTimeout = 200 * time.Millisecond
+	opts := orders.Opts{Market: "SOLUSDC", Owner: owner, Payer: solana.NewWallet().PublicKey().String(), PendingTimeout: pendingTimeout}
+	m, err := orders.NewManager(g, opts)
+	require.Nil(t, err)
+
+	// orders that left the book before any status update was received aren't open anymore, and are found by client
+	// order ID
+	request := orders.OrderRequest{Side: pb.Side_S_BID, Types: []pb.OrderType{pb.OrderType_OT_LIMIT}, Amount: 1, Price: 98}
+	filled, err := m.Submit(ctx, request)
+	require.Nil(t, err)
+	cancelled, err := m.Submit(ctx, request)
+	require.Nil(t, err)
+	openOrders, err := api.GetOpenOrders(ctx, &pb.GetOpenOrdersRequest{Market: standinMarket, Address: owner})
+	require.Nil(t, err)
+	require.Len(t, openOrders.Orders, 2)
+	for _, order := range openOrders.Orders {
+		if order.ClientOrderID == strconv.FormatUint(filled.ClientOrderID, 10) {
+			require.Nil(t, api.FillOrder(order.OrderID, 1))
+		} else {
+			require.Nil(t, api.CancelOrder(order.OrderID))
+		}
+	}
+
+	require.Nil(t, m.Reconcile(ctx))
+	o, _ := m.Order(filled.ClientOrderID)
+	assert.Equal(t, orders.StateFilled, o.State)
+	assert.Equal(t, 1.0, o.FilledSize)
+	assert.NotEmpty(t, o.OrderID)
+	o, _ = m.Order(cancelled.ClientOrderID)
+	assert.Equal(t, orders.StateCancelled, o.State)
+	assert.Equal(t, 1.0, o.RemainingSize)
+
+	// orders that never reach the market fail once the pending timeout has passed
+	opts.FirstClientOrderID = 100
+	unlanded, err := orders.NewManager(unlandedClient{Client: g}, opts)
+	require.Nil(t, err)
+	lost, err := unlanded.Submit(ctx, request)
+	require.NotNil(t, err)
+	assert.Equal(t, orders.StatePending, lost.State)
+
+	require.Nil(t, unlanded.Reconcile(ctx))
+	o, _ = unlanded.Order(lost.ClientOrderID)
+	assert.Equal(t, orders.StatePending, o.State)
+
+	time.Sleep(pendingTimeout)
+	require.Nil(t, unlanded.Reconcile(ctx))
+	o, _ = unlanded.Order(lost.ClientOrderID)
+	assert.Equal(t, orders.StateFailed, o.State)
+	assert.ErrorIs(t, o.Err, orders.ErrOrderNotLanded)
+}
+
+// droppingClient loses the updates of its first order status stream once drop is closed, and ends that stream once end
+// is closed
+type droppingClient struct {
+	*provider.GRPCClient
+	drop, end     chan struct{}
+	dropped       int32
+	subscriptions int32
+}
+
+func (c *droppingClient) GetOrderStatusStream(ctx context.Context, market, ownerAddress string, outputChan chan *pb.GetOrderStatusStreamResponse) error {
+	if atomic.AddInt32(&c.subscriptions, 1) > 1 {
+		return c.GRPCClient.GetOrderStatusStream(ctx, market, ownerAddress, outputChan)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	updates := make(chan *pb.GetOrderStatusStreamResponse, 10)
+	if err := c.GRPCClient.GetOrderStatusStream(ctx, market, ownerAddress, updates); err != nil {
+		cancel()
+		return err
+	}
+	go func() {
+		defer close(outputChan)
+		defer cancel()
+		for {
+			select {
+			case <-c.end:
+				return
+			case update := <-updates:
+				select {
+				case <-c.drop:
+					atomic.AddInt32(&c.dropped, 1)
+				default:
+					outputChan <- update
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func TestOrders_StreamEnded(t *testing.T) {
+	api, s := newMockAPI(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+	require.Nil(t, err)
+	client := &droppingClient{GRPCClient: g, drop: make(chan struct{}), end: make(chan struct{})}
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	recorder := &eventRecorder{}
+	m, err := orders.NewManager(client, orders.Opts{
+		Market:              "SOLUSDC",
+		Owner:               owner,
+		Payer:               solana.NewWallet().PublicKey().String(),
+		ResubscribeInterval: 10 * time.Millisecond,
+		OnEvent:             recorder.record,
+	})
+	require.Nil(t, err)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- m.Run(ctx, client)
+	}()
+	require.Eventually(t, func() bool { return api.Subscribers() == 1 }, conformanceTimeout, 10*time.Millisecond)
+
+	placed, err := m.Submit(ctx, orders.OrderRequest{Side: pb.Side_S_BID, Types: []pb.OrderType{pb.OrderType_OT_LIMIT}, Amount: 1, Price: 98})
+	require.Nil(t, err)
+	api.AdvanceBlock()
+	require.Eventually(t, func() bool {
+		o, _ := m.Order(placed.ClientOrderID)
+		return o.State == orders.StateOpen
+	}, conformanceTimeout, 10*time.Millisecond)
+
+	// the fill is lost with the stream, and found by the reconciliation once the stream is subscribed to again
+	close(client.drop)
+	o, _ := m.Order(placed.ClientOrderID)
+	require.Nil(t, api.FillOrder(o.OrderID, 0.4))
+	api.AdvanceBlock()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&client.dropped) == 1 }, conformanceTimeout, 10*time.Millisecond)
+	close(client.end)
+	require.Eventually(t, func() bool {
+		o, _ := m.Order(placed.ClientOrderID)
+		return o.State == orders.StatePartiallyFilled
+	}, conformanceTimeout, 10*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&client.subscriptions))
+
+	// and the new stream is followed
+	require.Eventually(t, func() bool { return api.Subscribers() == 1 }, conformanceTimeout, 10*time.Millisecond)
+	_, err = m.Cancel(ctx, placed.ClientOrderID)
+	require.Nil(t, err)
+	api.AdvanceBlock()
+	require.Eventually(t, func() bool {
+		o, _ := m.Order(placed.ClientOrderID)
+		return o.State == orders.StateCancelled
+	}, conformanceTimeout, 10*time.Millisecond)
+	assert.Equal(t, []orders.State{orders.StateOpen, orders.StatePartiallyFilled, orders.StateCancelled}, recorder.states(placed.ClientOrderID))
+
+	cancel()
+	assert.ErrorIs(t, bxassert.ReadChanWithTimeout(t, stopped, conformanceTimeout), context.Canceled)
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamOverflow(t *testing.T) {
+	api, s := newMockAPI(t)
+	const otherMarket = "SOL/USDT"
+	api.AddMarket(otherMarket, solana.NewWallet().PublicKey().String(), 0.1)
+	require.Nil(t, api.SetOrderbook(otherMarket, []*pb.OrderbookItem{{Price: 98, Size: 1}}, []*pb.OrderbookItem{{Price: 100, Size: 1}}))
+
+	w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	defer func() {
+		_ = w.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	subscribe := func(policy provider.OverflowPolicy, buffer int, markets ...string) (chan *pb.GetOrderbooksStreamResponse, *provider.StreamStats) {
+		stats := &provider.StreamStats{}
+		ch := make(chan *pb.GetOrderbooksStreamResponse, buffer)
+		policyCtx := provider.WithStreamOverflow(ctx, provider.StreamOverflow{Policy: policy, Stats: stats})
+		require.Nil(t, w.GetOrderbooksStream(policyCtx, markets, 0, ch))
+		return ch, stats
+	}
+	newest, newestStats := subscribe(provider.OverflowDropNewest, 1, standinMarket)
+	oldest, oldestStats := subscribe(provider.OverflowDropOldest, 2, standinMarket)
+	conflated, conflatedStats := subscribe(provider.OverflowConflate, 2, standinMarket, otherMarket)
+	disconnected, disconnectedStats := subscribe(provider.OverflowDisconnect, 1, standinMarket)
+	tickers := make(chan *pb.GetTickersStreamResponse, 10)
+	require.Nil(t, w.GetTickersStream(ctx, standinMarket, tickers))
+	assert.Eventually(t, func() bool { return api.Subscribers() == 5 }, conformanceTimeout, 10*time.Millisecond)
+
+	// none of the stalled subscriptions hold up the others on the connection
+	initial := bxassert.ReadChanWithTimeout(t, tickers, conformanceTimeout).BlockHeight
+	var height int64
+	for i := 0; i < 5; i++ {
+		height = api.AdvanceBlock()
+		assert.Equal(t, height, bxassert.ReadChanWithTimeout(t, tickers, conformanceTimeout).BlockHeight)
+	}
+	require.Equal(t, initial+5, height)
+
+	// the first update is kept
+	assert.Eventually(t, func() bool { return newestStats.Dropped() == 5 }, conformanceTimeout, 10*time.Millisecond)
+	assert.Equal(t, initial, bxassert.ReadChanWithTimeout(t, newest, conformanceTimeout).BlockHeight)
+
+	// the last two updates are kept
+	assert.Eventually(t, func() bool { return oldestStats.Dropped() == 4 }, conformanceTimeout, 10*time.Millisecond)
+	assert.Equal(t, height-1, bxassert.ReadChanWithTimeout(t, oldest, conformanceTimeout).BlockHeight)
+	assert.Equal(t, height, bxassert.ReadChanWithTimeout(t, oldest, conformanceTimeout).BlockHeight)
+
+	// the last update of each market is kept
+	assert.Eventually(t, func() bool { return conflatedStats.Dropped() == 10 }, conformanceTimeout, 10*time.Millisecond)
+	markets := make(map[string]int64)
+	for i := 0; i < 2; i++ {
+		update := bxassert.ReadChanWithTimeout(t, conflated, conformanceTimeout)
+		markets[update.Orderbook.Market] = update.BlockHeight
+	}
+	assert.Equal(t, map[string]int64{standinMarket: height, otherMarket: height}, markets)
+
+	// the subscription ends on the first update that doesn't fit
+	assert.Equal(t, initial, bxassert.ReadChanWithTimeout(t, disconnected, conformanceTimeout).BlockHeight)
+	_, ok := <-disconnected
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), disconnectedStats.Disconnects())
+	assert.Equal(t, uint64(1), disconnectedStats.Dropped())
+	assert.Eventually(t, func() bool { return api.Subscribers() == 4 }, conformanceTimeout, 10*time.Millisecond)
+}
+
+func TestStreamOverflow_Block(t *testing.T) {
+	api, s := newMockAPI(t)
+	w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	defer func() {
+		_ = w.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 4*conformanceTimeout)
+	defer cancel()
+
+	stalled, err := w.SubscribeOrderbooks(ctx, []string{standinMarket}, 0)
+	require.Nil(t, err)
+	tickers := make(chan *pb.GetTickersStreamResponse, 10)
+	require.Nil(t, w.GetTickersStream(ctx, standinMarket, tickers))
+	assert.Eventually(t, func() bool { return api.Subscribers() == 2 }, conformanceTimeout, 10*time.Millisecond)
+
+	// the stalled subscription's updates queue up on the connection, without holding up the other one
+	initial := bxassert.ReadChanWithTimeout(t, tickers, conformanceTimeout).BlockHeight
+	const blocks = 1200
+	for i := 0; i < blocks; i++ {
+		height := api.AdvanceBlock()
+		require.Equal(t, height, bxassert.ReadChanWithTimeout(t, tickers, conformanceTimeout).BlockHeight)
+	}
+
+	// and are all delivered once the subscriber catches up
+	for i := 0; i <= blocks; i++ {
+		update, err := stalled.Next(ctx)
+		require.Nil(t, err)
+		assert.Equal(t, initial+int64(i), update.BlockHeight)
+	}
+	assert.Nil(t, stalled.Err())
+	assert.Equal(t, 2, api.Subscribers())
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// regionAPI answers GetServerTime after a delay, like a distant endpoint, and counts the orderbook requests it serves
+type regionAPI struct {
+	*mock.API
+	delay time.Duration
+
+	m          sync.Mutex
+	orderbooks int
+}
+
+func (a *regionAPI) GetServerTime(ctx context.Context, request *pb.GetServerTimeRequest) (*pb.GetServerTimeResponse, error) {
+	time.Sleep(a.delay)
+	return a.API.GetServerTime(ctx, request)
+}
+
+func (a *regionAPI) GetOrderbook(ctx context.Context, request *pb.GetOrderbookRequest) (*pb.GetOrderbookResponse, error) {
+	a.m.Lock()
+	a.orderbooks++
+	a.m.Unlock()
+	return a.API.GetOrderbook(ctx, request)
+}
+
+func (a *regionAPI) orderbookRequests() int {
+	a.m.Lock()
+	defer a.m.Unlock()
+	return a.orderbooks
+}
+
+func newRegionAPI(t *testing.T, delay time.Duration) (*regionAPI, *mock.Server) {
+	api := &regionAPI{API: mock.NewAPI(), delay: delay}
+	api.AddMarket(standinMarket, standinMarketAddress, 0.1)
+	require.Nil(t, api.SetOrderbook(standinMarket, []*pb.OrderbookItem{{Price: 99, Size: 1}}, []*pb.OrderbookItem{{Price: 101, Size: 1}}))
+
+	s, err := mock.NewServer(api)
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return api, s
+}
+
+func TestPool_Failover(t *testing.T) {
+	far, farServer := newRegionAPI(t, 100*time.Millisecond)
+	near, nearServer := newRegionAPI(t, 0)
+
+	pool, err := provider.NewGRPCPool([]string{farServer.GRPCEndpoint, nearServer.GRPCEndpoint},
+		provider.RPCOpts{Timeout: conformanceTimeout}, provider.PoolOpts{HealthCheckInterval: time.Hour})
+	require.Nil(t, err)
+	defer func() {
+		_ = pool.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	endpoints := pool.Endpoints()
+	require.Equal(t, 2, len(endpoints))
+	assert.Equal(t, nearServer.GRPCEndpoint, endpoints[0].Endpoint)
+	assert.True(t, endpoints[0].Healthy)
+	assert.True(t, endpoints[1].Healthy)
+	assert.Less(t, endpoints[0].Latency, endpoints[1].Latency)
+
+	// requests go to the endpoint with the lowest latency
+	_, err = pool.GetOrderbook(ctx, standinMarket, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 1, near.orderbookRequests())
+	assert.Equal(t, 0, far.orderbookRequests())
+
+	// errors returned by the API are not failed over
+	_, err = pool.GetOrderbook(ctx, "BTC/USDC", 0)
+	require.NotNil(t, err)
+	assert.Equal(t, 0, far.orderbookRequests())
+
+	// the next endpoint takes over once the preferred one goes down
+	require.Nil(t, nearServer.Close())
+	_, err = pool.GetOrderbook(ctx, standinMarket, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 1, far.orderbookRequests())
+
+	endpoints = pool.Endpoints()
+	assert.Equal(t, farServer.GRPCEndpoint, endpoints[0].Endpoint)
+	assert.False(t, endpoints[1].Healthy)
+	assert.NotNil(t, endpoints[1].LastErr)
+}
+
+func TestPool_RedundantStreams(t *testing.T) {
+	first, firstServer := newRegionAPI(t, 0)
+	second, secondServer := newRegionAPI(t, 0)
+
+	pool, err := provider.NewGRPCPool([]string{firstServer.GRPCEndpoint, secondServer.GRPCEndpoint},
+		provider.RPCOpts{Timeout: conformanceTimeout}, provider.PoolOpts{HealthCheckInterval: time.Hour, RedundantStreams: 2})
+	require.Nil(t, err)
+	defer func() {
+		_ = pool.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	orderbookCh := make(chan *pb.GetOrderbooksStreamResponse, 10)
+	require.Nil(t, pool.GetOrderbooksStream(ctx, []string{standinMarket}, 0, orderbookCh))
+	assert.Eventually(t, func() bool {
+		return first.Subscribers() == 1 && second.Subscribers() == 1
+	}, conformanceTimeout, 10*time.Millisecond)
+
+	// both endpoints send the current orderbook, which is forwarded once
+	initial := bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout)
+	height := initial.BlockHeight
+
+	// a block is forwarded from whichever endpoint sends it first, and dropped when the other one catches up
+	assert.Equal(t, height+1, first.AdvanceBlock())
+	assert.Equal(t, height+1, bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout).BlockHeight)
+	assert.Equal(t, height+1, second.AdvanceBlock())
+	assert.Equal(t, height+2, second.AdvanceBlock())
+	assert.Equal(t, height+2, bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout).BlockHeight)
+
+	// updates keep flowing from the remaining endpoint after one goes down
+	require.Nil(t, secondServer.Close())
+	assert.Equal(t, height+2, first.AdvanceBlock())
+	assert.Equal(t, height+3, first.AdvanceBlock())
+	assert.Equal(t, height+3, bxassert.ReadChanWithTimeout(t, orderbookCh, conformanceTimeout).BlockHeight)
+
+	select {
+	case update := <-orderbookCh:
+		assert.Failf(t, "unexpected update", "duplicate update at block %v", update.BlockHeight)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPool_RedundantStreamsSameHeight(t *testing.T) {
+	first, firstServer := newRegionAPI(t, 0)
+	second, secondServer := newRegionAPI(t, 0)
+
+	pool, err := provider.NewWSPool([]string{firstServer.WSEndpoint, secondServer.WSEndpoint},
+		provider.RPCOpts{Timeout: conformanceTimeout}, provider.PoolOpts{HealthCheckInterval: time.Hour, RedundantStreams: 2})
+	require.Nil(t, err)
+	defer func() {
+		_ = pool.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	tradesCh := make(chan *pb.GetTradesStreamResponse, 10)
+	require.Nil(t, pool.GetTradesStream(ctx, standinMarket, 0, tradesCh))
+	require.Eventually(t, func() bool {
+		return first.Subscribers() == 1 && second.Subscribers() == 1
+	}, conformanceTimeout, 10*time.Millisecond)
+
+	// copies of an update are dropped
+	trade := &pb.Trade{Side: pb.Side_S_BID, Size: 1, Price: 101}
+	require.Nil(t, first.AddTrades(standinMarket, trade))
+	height := first.AdvanceBlock()
+	update := bxassert.ReadChanWithTimeout(t, tradesCh, conformanceTimeout)
+	assert.Equal(t, height, update.BlockHeight)
+	require.Nil(t, second.AddTrades(standinMarket, trade))
+	assert.Equal(t, height, second.AdvanceBlock())
+
+	// but different updates at the same height are all forwarded
+	require.Nil(t, first.AddTrades(standinMarket, &pb.Trade{Side: pb.Side_S_ASK, Size: 2, Price: 100}))
+	assert.Equal(t, height+1, first.AdvanceBlock())
+	require.Nil(t, second.AddTrades(standinMarket, &pb.Trade{Side: pb.Side_S_ASK, Size: 3, Price: 99}))
+	assert.Equal(t, height+1, second.AdvanceBlock())
+	var sizes []float64
+	for i := 0; i < 2; i++ {
+		update := bxassert.ReadChanWithTimeout(t, tradesCh, conformanceTimeout)
+		assert.Equal(t, height+1, update.BlockHeight)
+		sizes = append(sizes, update.Trades.Trades[0].Size)
+	}
+	assert.ElementsMatch(t, []float64{2, 3}, sizes)
+
+	select {
+	case update := <-tradesCh:
+		assert.Failf(t, "unexpected update", "duplicate update at block %v", update.BlockHeight)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// throttledAPI rate limits every order it is asked to build
+type throttledAPI struct {
+	*mock.API
+}
+
+func (a *throttledAPI) PostOrder(context.Context, *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
+	return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
+
+func TestPool_SubmitFailover(t *testing.T) {
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+	payer := solana.NewWallet().PublicKey().String()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	throttled := &throttledAPI{API: mock.NewAPI()}
+	throttled.AddMarket(standinMarket, standinMarketAddress, 0.1)
+	throttledServer, err := mock.NewServer(throttled)
+	require.Nil(t, err)
+	defer func() {
+		_ = throttledServer.Close()
+	}()
+	flaky, flakyServer := newFlakyAPI(t)
+
+	submit := func(preferred *mock.Server) (string, *regionAPI, error) {
+		backup, backupServer := newRegionAPI(t, 50*time.Millisecond)
+		pool, err := provider.NewGRPCPool([]string{preferred.GRPCEndpoint, backupServer.GRPCEndpoint},
+			provider.RPCOpts{Timeout: conformanceTimeout, PrivateKey: &pk}, provider.PoolOpts{HealthCheckInterval: time.Hour})
+		require.Nil(t, err)
+		defer func() {
+			_ = pool.Close()
+		}()
+		require.Equal(t, preferred.GRPCEndpoint, pool.Endpoints()[0].Endpoint)
+
+		signature, err := pool.SubmitOrder(ctx, owner, payer, standinMarket, pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 101, provider.PostOrderOpts{})
+		return signature, backup, err
+	}
+
+	// submissions the server rate limited were never sent, so they fail over
+	signature, backup, err := submit(throttledServer)
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+	assert.Len(t, backup.Submissions(), 1)
+
+	// while those that failed otherwise may have landed
+	_, backup, err = submit(flakyServer)
+	assert.True(t, errors.Is(err, bxerrors.ErrTransportClosed))
+	assert.Len(t, flaky.Submissions(), 1)
+	assert.Empty(t, backup.Submissions())
+}
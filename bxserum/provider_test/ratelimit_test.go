@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit_Blocking(t *testing.T) {
+	_, s := newMockAPI(t)
+	limiter := provider.NewRateLimiter(provider.RateLimitOpts{Reads: &provider.RateLimit{Rate: 20, Burst: 2}})
+
+	// clients sharing a limiter share its quota
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, RateLimiter: limiter})
+	require.Nil(t, err)
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, RateLimiter: limiter}).Client()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	start := time.Now()
+	for _, client := range []provider.Client{g, h, g, h} {
+		_, err := client.GetOrderbook(ctx, standinMarket, 0)
+		require.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	stats := limiter.Stats(provider.ReadMethods)
+	assert.Equal(t, int64(4), stats.Requests)
+	assert.Equal(t, int64(2), stats.Delayed)
+	assert.Greater(t, stats.WaitTime, time.Duration(0))
+	assert.Equal(t, int64(0), stats.Rejected)
+
+	// requests fail right away if they can't get a token before their deadline
+	limiter = provider.NewRateLimiter(provider.RateLimitOpts{Reads: &provider.RateLimit{Rate: 0.1, Burst: 1}})
+	h = provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, RateLimiter: limiter}).Client()
+	_, err = h.GetMarkets(ctx)
+	require.Nil(t, err)
+
+	shortCtx, shortCancel := context.WithTimeout(ctx, time.Second)
+	defer shortCancel()
+	start = time.Now()
+	_, err = h.GetMarkets(shortCtx)
+	assert.True(t, errors.Is(err, provider.ErrClientRateLimited))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int64(1), limiter.Stats(provider.ReadMethods).Rejected)
+
+	// or with the context's error if it's already done, rather than waiting for a token
+	expiredCtx, expiredCancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer expiredCancel()
+	start = time.Now()
+	assert.ErrorIs(t, limiter.Wait(expiredCtx, "GetMarkets"), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int64(1), limiter.Stats(provider.ReadMethods).Rejected)
+}
+
+func TestRateLimit_FailFast(t *testing.T) {
+	_, s := newMockAPI(t)
+	limiter := provider.NewRateLimiter(provider.RateLimitOpts{Reads: &provider.RateLimit{Rate: 0.1, Burst: 1}, FailFast: true})
+	w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout, RateLimiter: limiter})
+	require.Nil(t, err)
+	defer func() {
+		_ = w.Close()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	_, err = w.GetTickers(ctx, standinMarket)
+	require.Nil(t, err)
+
+	_, err = w.GetTickers(ctx, standinMarket)
+	assert.True(t, errors.Is(err, bxerrors.ErrRateLimited))
+	assert.True(t, errors.Is(err, provider.ErrClientRateLimited))
+
+	// stream subscriptions are reads too
+	err = w.GetTickersStream(ctx, standinMarket, make(chan *pb.GetTickersStreamResponse))
+	assert.True(t, errors.Is(err, provider.ErrClientRateLimited))
+	assert.Equal(t, int64(2), limiter.Stats(provider.ReadMethods).Rejected)
+
+	// other method classes have their own limits
+	owner := solana.NewWallet().PublicKey().String()
+	_, err = w.PostOrder(ctx, owner, owner, standinMarket, pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 101, provider.PostOrderOpts{})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), limiter.Stats(provider.OrderMethods).Requests)
+}
@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/recording"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readRecording returns the complete records of the recording at path
+func readRecording(t *testing.T, path string) []*recording.Record {
+	r, err := recording.Open(path)
+	require.Nil(t, err)
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var records []*recording.Record
+	for {
+		record, err := r.Next()
+		if err != nil {
+			return records
+		}
+		records = append(records, record)
+	}
+}
+
+func TestRecording_RecordAndReplay(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		name := "uncompressed"
+		if compress {
+			name = "zstd"
+		}
+		t.Run(name, func(t *testing.T) {
+			api, s := newMockAPI(t)
+			client, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout})
+			require.Nil(t, err)
+			defer func() {
+				_ = client.Close()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			path := filepath.Join(t.TempDir(), "recording")
+			w, err := recording.Create(path, recording.WriterOpts{Compress: compress})
+			require.Nil(t, err)
+			r, err := recording.NewRecorder(client, w, recording.Streams{
+				Orderbooks: []string{standinMarket},
+				Trades:     []string{standinMarket},
+				Tickers:    []string{standinMarket},
+			})
+			require.Nil(t, err)
+
+			recordCtx, stopRecording := context.WithCancel(ctx)
+			stopped := make(chan error, 1)
+			go func() {
+				stopped <- r.Run(recordCtx)
+			}()
+			require.Eventually(t, func() bool { return api.Subscribers() == 3 }, conformanceTimeout, 10*time.Millisecond)
+
+			// the initial orderbook and ticker, then the orderbook, ticker and trades of the next block
+			require.Nil(t, api.AddTrades(standinMarket, &pb.Trade{Side: pb.Side_S_BID, Size: 1, Price: 101}))
+			height := api.AdvanceBlock()
+			require.Eventually(t, func() bool {
+				require.Nil(t, w.Flush())
+				return len(readRecording(t, path)) == 5
+			}, conformanceTimeout, 10*time.Millisecond)
+			stopRecording()
+			assert.ErrorIs(t, bxassert.ReadChanWithTimeout(t, stopped, conformanceTimeout), context.Canceled)
+			require.Nil(t, w.Close())
+
+			records := readRecording(t, path)
+			require.Len(t, records, 5)
+			counts := make(map[string]int)
+			for i, record := range records {
+				assert.Equal(t, standinMarket, record.Market)
+				assert.False(t, record.ReceivedAt.IsZero())
+				if i > 0 {
+					assert.False(t, record.ReceivedAt.Before(records[i-1].ReceivedAt))
+				}
+				switch {
+				case record.Orderbook != nil:
+					counts["orderbooks"]++
+				case record.Trades != nil:
+					counts["trades"]++
+				case record.Ticker != nil:
+					counts["tickers"]++
+				}
+			}
+			assert.Equal(t, map[string]int{"orderbooks": 2, "trades": 1, "tickers": 2}, counts)
+
+			// replays go through the same stream methods as the clients, and end with the recording
+			replayer, err := recording.NewReplayer(path, recording.ReplayOpts{Speed: recording.MaxSpeed})
+			require.Nil(t, err)
+			orderbooks := make(chan *pb.GetOrderbooksStreamResponse, 10)
+			require.Nil(t, replayer.GetOrderbooksStream(ctx, []string{standinMarket}, 0, orderbooks))
+			trades := make(chan *pb.GetTradesStreamResponse, 10)
+			require.Nil(t, replayer.GetTradesStream(ctx, standinMarket, 0, trades))
+			tickers := make(chan *pb.GetTickersStreamResponse, 10)
+			require.Nil(t, replayer.GetTickersStream(ctx, standinMarketAddress, tickers))
+
+			var orderbookHeights []int64
+			for update := range orderbooks {
+				assert.Equal(t, 99.0, update.Orderbook.Bids[0].Price)
+				orderbookHeights = append(orderbookHeights, update.BlockHeight)
+			}
+			assert.Equal(t, []int64{height - 1, height}, orderbookHeights)
+			update := bxassert.ReadChanWithTimeout(t, trades, conformanceTimeout)
+			assert.Equal(t, height, update.BlockHeight)
+			require.Len(t, update.Trades.Trades, 1)
+			assert.Equal(t, 101.0, update.Trades.Trades[0].Price)
+			_, ok := <-trades
+			assert.False(t, ok)
+			tickerCount := 0
+			for range tickers {
+				tickerCount++
+			}
+			assert.Equal(t, 2, tickerCount)
+
+			// other markets aren't replayed
+			others := make(chan *pb.GetOrderbooksStreamResponse, 10)
+			require.Nil(t, replayer.GetOrderbooksStream(ctx, []string{"BTC/USDC"}, 0, others))
+			_, ok = <-others
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestRecording_ReplaySpeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording")
+	w, err := recording.Create(path, recording.WriterOpts{})
+	require.Nil(t, err)
+	const delay = 300 * time.Millisecond
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.Nil(t, w.Write(recording.Record{
+			ReceivedAt: start.Add(time.Duration(i) * delay),
+			Market:     standinMarket,
+			Ticker:     &pb.GetTickersStreamResponse{BlockHeight: int64(i)},
+		}))
+	}
+	require.Nil(t, w.Close())
+
+	replay := func(speed float64) time.Duration {
+		replayer, err := recording.NewReplayer(path, recording.ReplayOpts{Speed: speed})
+		require.Nil(t, err)
+		tickers := make(chan *pb.GetTickersStreamResponse)
+		begin := time.Now()
+		require.Nil(t, replayer.GetTickersStream(context.Background(), standinMarket, tickers))
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, int64(i), bxassert.ReadChanWithTimeout(t, tickers, conformanceTimeout).BlockHeight)
+		}
+		return time.Since(begin)
+	}
+
+	assert.GreaterOrEqual(t, replay(0), 2*delay)
+	accelerated := replay(10)
+	assert.GreaterOrEqual(t, accelerated, 2*delay/10)
+	assert.Less(t, accelerated, 2*delay)
+	assert.Less(t, replay(recording.MaxSpeed), 2*delay/10)
+
+	_, err = recording.NewReplayer(path, recording.ReplayOpts{Speed: -1})
+	assert.ErrorIs(t, err, recording.ErrInvalidSpeed)
+}
+
+func TestRecording_Truncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording")
+	w, err := recording.Create(path, recording.WriterOpts{})
+	require.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		require.Nil(t, w.Write(recording.Record{ReceivedAt: time.Now(), Market: standinMarket, Trades: &pb.GetTradesStreamResponse{BlockHeight: int64(i)}}))
+	}
+	require.Nil(t, w.Close())
+
+	// a recorder killed while writing leaves the last record incomplete
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+	require.Nil(t, os.Truncate(path, info.Size()-2))
+
+	r, err := recording.Open(path)
+	require.Nil(t, err)
+	defer func() {
+		_ = r.Close()
+	}()
+	for i := 0; i < 2; i++ {
+		record, err := r.Next()
+		require.Nil(t, err)
+		assert.Equal(t, int64(i), record.Trades.BlockHeight)
+	}
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	replayer, err := recording.NewReplayer(path, recording.ReplayOpts{Speed: recording.MaxSpeed})
+	require.Nil(t, err)
+	trades := make(chan *pb.GetTradesStreamResponse, 10)
+	require.Nil(t, replayer.GetTradesStream(context.Background(), standinMarket, 0, trades))
+	count := 0
+	for range trades {
+		count++
+	}
+	assert.Equal(t, 2, count)
+
+	// the incomplete record ends the replay like the end of the recording
+	sub, err := replayer.SubscribeTrades(context.Background(), standinMarket, 0)
+	require.Nil(t, err)
+	count = 0
+	for range sub.Updates() {
+		count++
+	}
+	assert.Equal(t, 2, count)
+	assert.ErrorIs(t, sub.Err(), provider.ErrStreamEnded)
+}
+
+func TestRecording_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording")
+	f, err := os.Create(path)
+	require.Nil(t, err)
+	write := func(height int64) {
+		w, err := recording.NewWriter(f, recording.WriterOpts{})
+		require.Nil(t, err)
+		require.Nil(t, w.Write(recording.Record{ReceivedAt: time.Now(), Market: standinMarket, Trades: &pb.GetTradesStreamResponse{BlockHeight: height}}))
+		require.Nil(t, w.Close())
+	}
+	write(0)
+	// a complete record whose content isn't a record
+	_, err = f.Write([]byte{2, 0xff, 0xff})
+	require.Nil(t, err)
+	write(2)
+	require.Nil(t, f.Close())
+
+	replayer, err := recording.NewReplayer(path, recording.ReplayOpts{Speed: recording.MaxSpeed})
+	require.Nil(t, err)
+	sub, err := replayer.SubscribeTrades(context.Background(), standinMarket, 0)
+	require.Nil(t, err)
+	update, err := sub.Next(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), update.BlockHeight)
+	_, err = sub.Next(context.Background())
+	assert.ErrorIs(t, err, recording.ErrInvalidRecord)
+	assert.ErrorIs(t, sub.Err(), recording.ErrInvalidRecord)
+
+	// channels end at the corrupt record
+	trades := make(chan *pb.GetTradesStreamResponse, 10)
+	require.Nil(t, replayer.GetTradesStream(context.Background(), standinMarket, 0, trades))
+	assert.Equal(t, int64(0), bxassert.ReadChanWithTimeout(t, trades, conformanceTimeout).BlockHeight)
+	_, ok := <-trades
+	assert.False(t, ok)
+}
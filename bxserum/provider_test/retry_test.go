@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyAPI rate limits the first requests of some methods, and loses the response of the first submission
+type flakyAPI struct {
+	*mock.API
+
+	m                 sync.Mutex
+	orderbookFailures int
+	tickersFailures   int
+	submitLost        bool
+}
+
+func (a *flakyAPI) GetOrderbook(ctx context.Context, request *pb.GetOrderbookRequest) (*pb.GetOrderbookResponse, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	if a.orderbookFailures > 0 {
+		a.orderbookFailures--
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return a.API.GetOrderbook(ctx, request)
+}
+
+func (a *flakyAPI) GetTickers(ctx context.Context, request *pb.GetTickersRequest) (*pb.GetTickersResponse, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	if a.tickersFailures > 0 {
+		a.tickersFailures--
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return a.API.GetTickers(ctx, request)
+}
+
+// PostSubmit lands the first submission but loses its response, and reports the transaction as processed after that,
+// like Solana does for duplicates
+func (a *flakyAPI) PostSubmit(ctx context.Context, request *pb.PostSubmitRequest) (*pb.PostSubmitResponse, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	if a.submitLost {
+		return nil, status.Error(codes.InvalidArgument, "Transaction simulation failed: This transaction has already been processed")
+	}
+	if _, err := a.API.PostSubmit(ctx, request); err != nil {
+		return nil, err
+	}
+	a.submitLost = true
+	return nil, status.Error(codes.Unavailable, "connection reset")
+}
+
+func newFlakyAPI(t *testing.T) (*flakyAPI, *mock.Server) {
+	api := &flakyAPI{API: mock.NewAPI()}
+	api.AddMarket(standinMarket, standinMarketAddress, 0.1)
+	require.Nil(t, api.SetOrderbook(standinMarket, []*pb.OrderbookItem{{Price: 99, Size: 1}}, []*pb.OrderbookItem{{Price: 101, Size: 1}}))
+
+	s, err := mock.NewServer(api)
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return api, s
+}
+
+func TestRetry_ReadOnly(t *testing.T) {
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client
+	}{
+		{"http", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client {
+			opts.Endpoint = s.HTTPEndpoint
+			return provider.NewHTTPClientWithOpts(nil, opts).Client()
+		}},
+		{"ws", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client {
+			opts.Endpoint = s.WSEndpoint
+			w, err := provider.NewWSClientWithOpts(opts)
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client {
+			opts.Endpoint = s.GRPCEndpoint
+			g, err := provider.NewGRPCClientWithOpts(opts)
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			api, s := newFlakyAPI(t)
+			api.orderbookFailures, api.tickersFailures = 2, 3
+
+			var attempts []provider.RetryAttempt
+			policy := provider.DefaultRetryPolicy()
+			policy.InitialBackoff = time.Millisecond
+			policy.OnAttempt = func(attempt provider.RetryAttempt) { attempts = append(attempts, attempt) }
+			client := c.newClient(t, s, provider.RPCOpts{Timeout: conformanceTimeout, Retry: policy})
+			defer func() {
+				_ = client.Close()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			orderbook, err := client.GetOrderbook(ctx, standinMarket, 0)
+			require.Nil(t, err)
+			assert.Len(t, orderbook.Bids, 1)
+			require.Len(t, attempts, 3)
+			for i, attempt := range attempts {
+				assert.Equal(t, "GetOrderbook", attempt.Method)
+				assert.Equal(t, i+1, attempt.Attempt)
+				assert.Equal(t, i < 2, attempt.Err != nil)
+				assert.Equal(t, i < 2, attempt.Backoff > 0)
+			}
+
+			// attempts are capped, and the last error is returned
+			attempts = nil
+			_, err = client.GetTickers(ctx, standinMarket)
+			assert.True(t, errors.Is(err, bxerrors.ErrRateLimited))
+			assert.Len(t, attempts, 3)
+
+			// errors that aren't transient are returned right away
+			attempts = nil
+			_, err = client.GetOrderbook(ctx, "BTC/USDC", 0)
+			assert.NotNil(t, err)
+			assert.Len(t, attempts, 1)
+		})
+	}
+}
+
+func TestRetry_Submit(t *testing.T) {
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+	payer := solana.NewWallet().PublicKey().String()
+
+	// submissions aren't retried unless enabled
+	api, s := newFlakyAPI(t)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk, Retry: provider.DefaultRetryPolicy()})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	_, err = g.SubmitOrder(ctx, owner, payer, standinMarket, pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 101, provider.PostOrderOpts{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Len(t, api.Submissions(), 1)
+
+	// sending the transaction that landed again is reported as already processed
+	_, err = g.PostSubmit(ctx, api.Submissions()[0], false)
+	assert.True(t, errors.Is(err, bxerrors.ErrAlreadyProcessed))
+	assert.Len(t, api.Submissions(), 1)
+
+	// the same transaction is sent again, and the signature of the one that landed is returned
+	api, s = newFlakyAPI(t)
+	policy := provider.DefaultRetryPolicy()
+	policy.RetrySubmit = true
+	var attempts []provider.RetryAttempt
+	policy.OnAttempt = func(attempt provider.RetryAttempt) { attempts = append(attempts, attempt) }
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: s.HTTPEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk, Retry: policy})
+
+	signature, err := h.SubmitOrderWithContext(ctx, owner, payer, standinMarket, pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 101, provider.PostOrderOpts{})
+	require.Nil(t, err)
+	require.Len(t, api.Submissions(), 1)
+	txBytes, err := solanarpc.DataBytesOrJSONFromBase64(api.Submissions()[0])
+	require.Nil(t, err)
+	tx, err := (&solanarpc.TransactionWithMeta{Transaction: txBytes}).GetTransaction()
+	require.Nil(t, err)
+	assert.Equal(t, tx.Signatures[0].String(), signature)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, "PostSubmit", attempts[1].Method)
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/settlement"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettlement_Worker(t *testing.T) {
+	api, s := newMockAPI(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+	usdcWallet := solana.NewWallet().PublicKey().String()
+	openOrders := solana.NewWallet().PublicKey().String()
+
+	api.SetBalances(owner,
+		&pb.TokenBalance{Symbol: "SOL", Address: owner, WalletAmount: 1},
+		&pb.TokenBalance{Symbol: "USDC", Address: usdcWallet, WalletAmount: 100},
+	)
+	setUnsettled := func(base, quote float64) {
+		require.Nil(t, api.SetUnsettled(standinMarket, owner, &pb.UnsettledAccount{
+			Account:    openOrders,
+			BaseToken:  &pb.UnsettledAccountToken{Address: "So11111111111111111111111111111111111111112", Amount: base},
+			QuoteToken: &pb.UnsettledAccountToken{Address: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Amount: quote},
+		}))
+	}
+
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, PrivateKey: &pk})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	var settled []settlement.Result
+	opts := settlement.Opts{
+		Owner:    owner,
+		Markets:  map[string]settlement.Policy{"SOL-USDC": {BaseThreshold: 1, QuoteThreshold: 50}},
+		DryRun:   true,
+		OnSettle: func(result settlement.Result) { settled = append(settled, result) },
+	}
+	w, err := settlement.NewWorker(g, opts)
+	require.Nil(t, err)
+
+	// amounts below both thresholds are left alone
+	setUnsettled(0.5, 20)
+	results, err := w.RunOnce(ctx)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+
+	// dry runs report the settlement without submitting it
+	setUnsettled(0.5, 60)
+	results, err = w.RunOnce(ctx)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, settlement.Result{
+		Market:            "SOL-USDC",
+		OpenOrdersAddress: openOrders,
+		BaseAmount:        0.5,
+		QuoteAmount:       60,
+		BaseTokenWallet:   owner,
+		QuoteTokenWallet:  usdcWallet,
+		DryRun:            true,
+	}, results[0])
+	assert.Empty(t, api.Submissions())
+
+	opts.DryRun = false
+	w, err = settlement.NewWorker(g, opts)
+	require.Nil(t, err)
+	results, err = w.RunOnce(ctx)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.NotEmpty(t, results[0].Signature)
+	assert.Len(t, api.Submissions(), 1)
+	assert.Len(t, settled, 2)
+
+	unsettled, err := g.GetUnsettled(ctx, standinMarket, owner)
+	require.Nil(t, err)
+	assert.Empty(t, unsettled.Unsettled)
+}
+
+func TestSettlement_WalletResolution(t *testing.T) {
+	api, s := newMockAPI(t)
+	owner := solana.NewWallet().PublicKey().String()
+	require.Nil(t, api.SetUnsettled(standinMarket, owner, &pb.UnsettledAccount{
+		Account:   solana.NewWallet().PublicKey().String(),
+		BaseToken: &pb.UnsettledAccountToken{Amount: 1},
+	}))
+	api.SetBalances(owner, &pb.TokenBalance{Symbol: "SOL", Address: owner})
+
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+
+	// wallets that can't be found are reported per settlement
+	w, err := settlement.NewWorker(g, settlement.Opts{Owner: owner, Markets: map[string]settlement.Policy{standinMarket: {}}, DryRun: true})
+	require.Nil(t, err)
+	results, err := w.RunOnce(ctx)
+	require.NotNil(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, errors.Is(results[0].Err, settlement.ErrWalletNotFound))
+
+	// policies can provide them instead
+	quoteWallet := solana.NewWallet().PublicKey().String()
+	w, err = settlement.NewWorker(g, settlement.Opts{Owner: owner, Markets: map[string]settlement.Policy{standinMarket: {QuoteTokenWallet: quoteWallet}}, DryRun: true})
+	require.Nil(t, err)
+	results, err = w.RunOnce(ctx)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, owner, results[0].BaseTokenWallet)
+	assert.Equal(t, quoteWallet, results[0].QuoteTokenWallet)
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeypairFile writes the key in the JSON byte array format used by the Solana CLI
+func writeKeypairFile(t *testing.T, pk solana.PrivateKey) string {
+	ints := make([]int, len(pk))
+	for i, b := range pk {
+		ints[i] = int(b)
+	}
+	b, err := json.Marshal(ints)
+	require.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "id.json")
+	require.Nil(t, os.WriteFile(path, b, 0600))
+	return path
+}
+
+func TestSigner_Implementations(t *testing.T) {
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+
+	fileSigner, err := transaction.NewKeypairFileSigner(writeKeypairFile(t, pk))
+	require.Nil(t, err)
+
+	remote := httptest.NewServer(transaction.NewRemoteSignerHandler(transaction.NewPrivateKeySigner(pk)))
+	defer remote.Close()
+	remoteSigner, err := transaction.NewRemoteSigner(context.Background(), remote.URL, nil)
+	require.Nil(t, err)
+
+	signers := map[string]transaction.Signer{
+		"private key": transaction.NewPrivateKeySigner(pk),
+		"file":        fileSigner,
+		"remote":      remoteSigner,
+	}
+	message := []byte("message")
+	for name, signer := range signers {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, pk.PublicKey(), signer.PublicKey())
+
+			signature, err := signer.SignMessage(context.Background(), message)
+			require.Nil(t, err)
+			assert.True(t, signature.Verify(pk.PublicKey(), message))
+		})
+	}
+}
+
+func TestSigner_RemoteRejectsBadSignatures(t *testing.T) {
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	other, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+
+	// the signing service claims pk but signs with another key
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/publicKey" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"publicKey": pk.PublicKey().String()})
+			return
+		}
+		var request struct {
+			Message []byte `json:"message"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		signature, err := other.Sign(request.Message)
+		require.Nil(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{"signature": signature.String()})
+	}))
+	defer remote.Close()
+
+	signer, err := transaction.NewRemoteSigner(context.Background(), remote.URL, nil)
+	require.Nil(t, err)
+	_, err = signer.SignMessage(context.Background(), []byte("message"))
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "does not match its public key")
+
+	// the handler refuses to sign for other keys
+	handler := transaction.NewRemoteSignerHandler(transaction.NewPrivateKeySigner(pk))
+	body := fmt.Sprintf(`{"publicKey":%q,"message":"bWVzc2FnZQ=="}`, other.PublicKey())
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sign", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSigner_SubmitWithRemoteSigner(t *testing.T) {
+	s := newStandin(t)
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+
+	remote := httptest.NewServer(transaction.NewRemoteSignerHandler(transaction.NewPrivateKeySigner(pk)))
+	defer remote.Close()
+	signer, err := transaction.NewRemoteSigner(context.Background(), remote.URL, nil)
+	require.Nil(t, err)
+
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout, Signer: signer})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	owner := pk.PublicKey().String()
+	signature, err := g.SubmitOrder(ctx, owner, owner, "SOLUSDC", pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	require.Nil(t, err)
+	assert.NotEmpty(t, signature)
+
+	// without a signer or private key, submissions fail before reaching the API
+	g, err = provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+	require.Nil(t, err)
+	_, err = g.SubmitOrder(ctx, owner, owner, "SOLUSDC", pb.Side_S_ASK, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	assert.Equal(t, provider.ErrPrivateKeyNotFound, err)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// twoSignerTransaction builds an unsigned transaction that must be signed by both payer (the fee payer) and owner
+func twoSignerTransaction(t *testing.T, owner, payer solana.PublicKey) string {
+	tx, err := solana.NewTransaction([]solana.Instruction{
+		solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{
+			solana.Meta(owner).SIGNER(),
+			solana.Meta(payer).SIGNER(),
+		}, []byte("two signers")),
+	}, solana.Hash{}, solana.TransactionPayer(payer))
+	require.Nil(t, err)
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+
+	txBase64, err := tx.ToBase64()
+	require.Nil(t, err)
+	return txBase64
+}
+
+func TestSigning_MultipleSigners(t *testing.T) {
+	owner, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	payer, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	ownerSigner, payerSigner := transaction.NewPrivateKeySigner(owner), transaction.NewPrivateKeySigner(payer)
+	ctx := context.Background()
+
+	unsignedTx := twoSignerTransaction(t, owner.PublicKey(), payer.PublicKey())
+	missing, err := transaction.MissingSigners(unsignedTx)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []solana.PublicKey{owner.PublicKey(), payer.PublicKey()}, missing)
+
+	// a single signer can't complete the transaction
+	_, err = transaction.SignTxWithPrivateKey(unsignedTx, owner)
+	var missingErr *transaction.MissingSignersError
+	require.True(t, errors.As(err, &missingErr))
+	assert.Equal(t, []solana.PublicKey{payer.PublicKey()}, missingErr.Missing)
+
+	// parties sign in sequence, each filling its own slot
+	partialTx, missing, err := transaction.PartialSignTx(ctx, unsignedTx, ownerSigner)
+	require.Nil(t, err)
+	assert.Equal(t, []solana.PublicKey{payer.PublicKey()}, missing)
+
+	signedTx, missing, err := transaction.PartialSignTx(ctx, partialTx, payerSigner)
+	require.Nil(t, err)
+	assert.Empty(t, missing)
+	assertSignedTx(t, signedTx)
+
+	// or all at once, in any order
+	signedTx, err = transaction.SignTxWithSigners(ctx, unsignedTx, ownerSigner, payerSigner)
+	require.Nil(t, err)
+	assertSignedTx(t, signedTx)
+
+	// keys that aren't required signers are rejected
+	other, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	_, _, err = transaction.PartialSignTx(ctx, unsignedTx, transaction.NewPrivateKeySigner(other))
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "is not a required signer")
+}
+
+func assertSignedTx(t *testing.T, txBase64 string) {
+	missing, err := transaction.MissingSigners(txBase64)
+	require.Nil(t, err)
+	assert.Empty(t, missing)
+
+	txBytes, err := solanarpc.DataBytesOrJSONFromBase64(txBase64)
+	require.Nil(t, err)
+	tx, err := (&solanarpc.TransactionWithMeta{Transaction: txBytes}).GetTransaction()
+	require.Nil(t, err)
+	assert.Nil(t, tx.VerifySignatures())
+}
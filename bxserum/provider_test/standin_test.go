@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	standinMarket        = "SOL/USDC"
+	standinMarketAddress = "9wFFyRfZBsuAha4YcuxcXLKwMxJR43S7fPfQLusDBzvT"
+	standinStreamUpdates = 3
+)
+
+var errStandinMarketNotFound = status.Error(codes.NotFound, "provided market name/address was not found")
+
+// standinAPI is a canned, in-process implementation of the Serum API used to exercise the providers without network
+type standinAPI struct {
+	pb.UnimplementedApiServer
+}
+
+func (s *standinAPI) market(market string) error {
+	switch market {
+	case standinMarket, "SOLUSDC", "SOL-USDC", standinMarketAddress:
+		return nil
+	default:
+		return errStandinMarketNotFound
+	}
+}
+
+func (s *standinAPI) orderbook(limit uint32) *pb.GetOrderbookResponse {
+	orderbook := &pb.GetOrderbookResponse{
+		Market:        standinMarket,
+		MarketAddress: standinMarketAddress,
+		Bids:          []*pb.OrderbookItem{{Price: 99, Size: 1}, {Price: 98, Size: 2}, {Price: 97, Size: 3}},
+		Asks:          []*pb.OrderbookItem{{Price: 101, Size: 1}, {Price: 102, Size: 2}, {Price: 103, Size: 3}},
+	}
+	if limit != 0 && int(limit) < len(orderbook.Bids) {
+		orderbook.Bids = orderbook.Bids[:limit]
+		orderbook.Asks = orderbook.Asks[:limit]
+	}
+	return orderbook
+}
+
+func (s *standinAPI) GetMarkets(context.Context, *pb.GetMarketsRequest) (*pb.GetMarketsResponse, error) {
+	return &pb.GetMarketsResponse{Markets: map[string]*pb.Market{
+		standinMarket: {Market: standinMarket, Status: pb.MarketStatus_MS_ONLINE, Address: standinMarketAddress},
+	}}, nil
+}
+
+func (s *standinAPI) GetOrderbook(_ context.Context, request *pb.GetOrderbookRequest) (*pb.GetOrderbookResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	return s.orderbook(request.Limit), nil
+}
+
+func (s *standinAPI) GetTrades(_ context.Context, request *pb.GetTradesRequest) (*pb.GetTradesResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	return &pb.GetTradesResponse{Trades: []*pb.Trade{{Side: pb.Side_S_BID, Size: 1, Price: 100, OrderID: "1"}}}, nil
+}
+
+func (s *standinAPI) GetTickers(_ context.Context, request *pb.GetTickersRequest) (*pb.GetTickersResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	return &pb.GetTickersResponse{Tickers: []*pb.Ticker{{Market: standinMarket, MarketAddress: standinMarketAddress, Bid: 99, BidSize: 1, Ask: 101, AskSize: 1}}}, nil
+}
+
+func (s *standinAPI) GetOpenOrders(_ context.Context, request *pb.GetOpenOrdersRequest) (*pb.GetOpenOrdersResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	return &pb.GetOpenOrdersResponse{Orders: []*pb.Order{{OrderID: "1", Market: standinMarket, Side: pb.Side_S_ASK, Price: 101, RemainingSize: 1, ClientOrderID: "5000"}}}, nil
+}
+
+func (s *standinAPI) GetUnsettled(_ context.Context, request *pb.GetUnsettledRequest) (*pb.GetUnsettledResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	return &pb.GetUnsettledResponse{Market: standinMarketAddress, Unsettled: []*pb.UnsettledAccount{{
+		Account:    request.Owner,
+		BaseToken:  &pb.UnsettledAccountToken{Address: "base", Amount: 1},
+		QuoteToken: &pb.UnsettledAccountToken{Address: "quote", Amount: 2},
+	}}}, nil
+}
+
+func (s *standinAPI) GetAccountBalance(_ context.Context, request *pb.GetAccountBalanceRequest) (*pb.GetAccountBalanceResponse, error) {
+	return &pb.GetAccountBalanceResponse{Tokens: []*pb.TokenBalance{{Symbol: "SOL", Address: request.OwnerAddress, WalletAmount: 1}}}, nil
+}
+
+func (s *standinAPI) GetKline(_ context.Context, request *pb.GetKlineRequest) (*pb.GetKlineResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	response := &pb.GetKlineResponse{Market: standinMarket, Timestamp: timestamppb.Now()}
+	for i := uint32(0); i < request.Limit; i++ {
+		response.Candles = append(response.Candles, &pb.Candle{StartTime: request.From, UpdateTime: request.To, Open: 100, Close: 101})
+	}
+	return response, nil
+}
+
+// GetOrders echoes the filters back in the returned order, so tests can verify each transport encodes them
+func (s *standinAPI) GetOrders(_ context.Context, request *pb.GetOrdersRequest) (*pb.GetOrdersResponse, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	return &pb.GetOrdersResponse{Orders: []*pb.Order{{
+		OrderID:          "1",
+		Market:           standinMarket,
+		Side:             request.Side,
+		Types:            request.Types,
+		CreatedAt:        request.From,
+		OpenOrderAccount: request.Address,
+	}}}, nil
+}
+
+func (s *standinAPI) GetOrderByID(_ context.Context, request *pb.GetOrderByIDRequest) (*pb.GetOrderByIDResponse, error) {
+	if request.Market != "" {
+		if err := s.market(request.Market); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.GetOrderByIDResponse{Order: &pb.Order{OrderID: request.OrderID, Market: standinMarket}}, nil
+}
+
+func (s *standinAPI) GetServerTime(context.Context, *pb.GetServerTimeRequest) (*pb.GetServerTimeResponse, error) {
+	return &pb.GetServerTimeResponse{Timestamp: time.Now().UTC().Format(time.RFC3339Nano)}, nil
+}
+
+func (s *standinAPI) PostOrder(_ context.Context, request *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
+	tx, err := standinTransaction(request.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostOrderResponse{Transaction: tx, OpenOrdersAddress: request.OpenOrdersAddress}, nil
+}
+
+func (s *standinAPI) PostSubmit(_ context.Context, request *pb.PostSubmitRequest) (*pb.PostSubmitResponse, error) {
+	txBytes, err := solanarpc.DataBytesOrJSONFromBase64(request.Transaction)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	tx, err := (&solanarpc.TransactionWithMeta{Transaction: txBytes}).GetTransaction()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.PostSubmitResponse{Signature: tx.Signatures[0].String()}, nil
+}
+
+func (s *standinAPI) PostCancelOrder(_ context.Context, request *pb.PostCancelOrderRequest) (*pb.PostCancelOrderResponse, error) {
+	tx, err := standinTransaction(request.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostCancelOrderResponse{Transaction: tx}, nil
+}
+
+func (s *standinAPI) PostCancelByClientOrderID(_ context.Context, request *pb.PostCancelByClientOrderIDRequest) (*pb.PostCancelOrderResponse, error) {
+	tx, err := standinTransaction(request.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostCancelOrderResponse{Transaction: tx}, nil
+}
+
+func (s *standinAPI) PostCancelAll(_ context.Context, request *pb.PostCancelAllRequest) (*pb.PostCancelAllResponse, error) {
+	var txs []string
+	for range request.OpenOrdersAddresses {
+		tx, err := standinTransaction(request.OwnerAddress)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return &pb.PostCancelAllResponse{Transactions: txs}, nil
+}
+
+func (s *standinAPI) PostSettle(_ context.Context, request *pb.PostSettleRequest) (*pb.PostSettleResponse, error) {
+	tx, err := standinTransaction(request.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostSettleResponse{Transaction: tx}, nil
+}
+
+func (s *standinAPI) orderbookUpdates(request *pb.GetOrderbooksRequest) ([]proto.Message, error) {
+	var updates []proto.Message
+	for _, market := range request.Markets {
+		if err := s.market(market); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < standinStreamUpdates; i++ {
+		updates = append(updates, &pb.GetOrderbooksStreamResponse{BlockHeight: int64(i + 1), Orderbook: s.orderbook(request.Limit)})
+	}
+	return updates, nil
+}
+
+func (s *standinAPI) tradesUpdates(request *pb.GetTradesRequest) ([]proto.Message, error) {
+	trades, err := s.GetTrades(context.Background(), request)
+	if err != nil {
+		return nil, err
+	}
+	var updates []proto.Message
+	for i := 0; i < standinStreamUpdates; i++ {
+		updates = append(updates, &pb.GetTradesStreamResponse{BlockHeight: int64(i + 1), Trades: trades})
+	}
+	return updates, nil
+}
+
+func (s *standinAPI) orderStatusUpdates(request *pb.GetOrderStatusStreamRequest) ([]proto.Message, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	var updates []proto.Message
+	for i := 0; i < standinStreamUpdates; i++ {
+		updates = append(updates, &pb.GetOrderStatusStreamResponse{BlockHeight: int64(i + 1), OrderInfo: &pb.GetOrderStatusResponse{
+			Market:      standinMarket,
+			OrderID:     "1",
+			OrderStatus: pb.OrderStatus_OS_OPEN,
+		}})
+	}
+	return updates, nil
+}
+
+func (s *standinAPI) tickersUpdates(request *pb.GetTickersRequest) ([]proto.Message, error) {
+	if err := s.market(request.Market); err != nil {
+		return nil, err
+	}
+	var updates []proto.Message
+	for i := 0; i < standinStreamUpdates; i++ {
+		updates = append(updates, &pb.GetTickersStreamResponse{BlockHeight: int64(i + 1), Ticker: &pb.GetTickersResponse{Tickers: []*pb.Ticker{
+			{Market: standinMarket, MarketAddress: standinMarketAddress, Bid: 99, BidSize: 1, Ask: 101, AskSize: 1},
+		}}})
+	}
+	return updates, nil
+}
+
+func (s *standinAPI) marketDepthUpdates() ([]proto.Message, error) {
+	var updates []proto.Message
+	for i := 0; i < standinStreamUpdates; i++ {
+		updates = append(updates, &pb.GetMarketDepthStreamResponse{BlockHeight: int64(i + 1), Tick: &pb.MarketDepthTick{
+			PrevBlockHeight: int64(i),
+			Bids:            []*pb.OrderbookItem{{Price: 99, Size: float64(i + 1)}},
+			Asks:            []*pb.OrderbookItem{{Price: 101, Size: float64(i + 1)}},
+		}})
+	}
+	return updates, nil
+}
+
+func (s *standinAPI) GetOrderbooksStream(request *pb.GetOrderbooksRequest, stream pb.Api_GetOrderbooksStreamServer) error {
+	return sendStandinUpdates(stream, func() ([]proto.Message, error) { return s.orderbookUpdates(request) })
+}
+
+func (s *standinAPI) GetTradesStream(request *pb.GetTradesRequest, stream pb.Api_GetTradesStreamServer) error {
+	return sendStandinUpdates(stream, func() ([]proto.Message, error) { return s.tradesUpdates(request) })
+}
+
+func (s *standinAPI) GetOrderStatusStream(request *pb.GetOrderStatusStreamRequest, stream pb.Api_GetOrderStatusStreamServer) error {
+	return sendStandinUpdates(stream, func() ([]proto.Message, error) { return s.orderStatusUpdates(request) })
+}
+
+func (s *standinAPI) GetTickersStream(request *pb.GetTickersRequest, stream pb.Api_GetTickersStreamServer) error {
+	return sendStandinUpdates(stream, func() ([]proto.Message, error) { return s.tickersUpdates(request) })
+}
+
+func (s *standinAPI) GetMarketDepthStream(_ *pb.GetMarketsRequest, stream pb.Api_GetMarketDepthStreamServer) error {
+	return sendStandinUpdates(stream, s.marketDepthUpdates)
+}
+
+// sendStandinUpdates writes all updates on the stream, then holds the stream open until the client leaves
+func sendStandinUpdates(stream grpc.ServerStream, updatesFn func() ([]proto.Message, error)) error {
+	updates, err := updatesFn()
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		if err := stream.SendMsg(update); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+// standinTransaction builds an unsigned transaction that requires a single signature from owner
+func standinTransaction(owner string) (string, error) {
+	ownerKey, err := solana.PublicKeyFromBase58(owner)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	tx, err := solana.NewTransaction([]solana.Instruction{
+		solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{solana.Meta(ownerKey).SIGNER()}, []byte("standin")),
+	}, solana.Hash{}, solana.TransactionPayer(ownerKey))
+	if err != nil {
+		return "", err
+	}
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+	return tx.ToBase64()
+}
+
+// newStandin serves standinAPI over GRPC, HTTP and websockets on local ports
+func newStandin(t *testing.T) *mock.Server {
+	s, err := mock.NewServer(&standinAPI{})
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	bxerrors "github.com/bloXroute-Labs/serum-client-go/bxserum/errors"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endingAPI ends tickers streams after their first update
+type endingAPI struct {
+	*mock.API
+}
+
+func (a endingAPI) GetTickersStream(request *pb.GetTickersRequest, stream pb.Api_GetTickersStreamServer) error {
+	tickers, err := a.GetTickers(stream.Context(), request)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&pb.GetTickersStreamResponse{Ticker: tickers})
+}
+
+// tickersSubscriber is implemented by the clients that return stream subscriptions
+type tickersSubscriber interface {
+	SubscribeTickers(ctx context.Context, market string) (*provider.Subscription[*pb.GetTickersStreamResponse], error)
+	Close() error
+}
+
+func TestSubscription(t *testing.T) {
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T, s *mock.Server) tickersSubscriber
+	}{
+		{"ws", func(t *testing.T, s *mock.Server) tickersSubscriber {
+			w, err := provider.NewWSClientWithOpts(provider.RPCOpts{Endpoint: s.WSEndpoint, Timeout: conformanceTimeout})
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T, s *mock.Server) tickersSubscriber {
+			g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			_, s := newMockAPI(t)
+			client := c.newClient(t, s)
+			defer func() {
+				_ = client.Close()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			// subscription errors are returned right away
+			_, err := client.SubscribeTickers(ctx, "BTC/USDC")
+			assert.ErrorIs(t, err, bxerrors.ErrMarketNotFound)
+
+			// canceling the context ends the stream
+			canceledCtx, cancelSubscription := context.WithCancel(ctx)
+			canceled, err := client.SubscribeTickers(canceledCtx, standinMarket)
+			require.Nil(t, err)
+			_, err = canceled.Next(ctx)
+			require.Nil(t, err)
+			assert.Nil(t, canceled.Err())
+			cancelSubscription()
+			_, err = canceled.Next(ctx)
+			assert.ErrorIs(t, err, context.Canceled)
+			<-canceled.Done()
+			assert.ErrorIs(t, canceled.Err(), context.Canceled)
+
+			// losing the connection ends the stream with a transport error
+			lost, err := client.SubscribeTickers(ctx, standinMarket)
+			require.Nil(t, err)
+			_, err = lost.Next(ctx)
+			require.Nil(t, err)
+			require.Nil(t, s.Close())
+			for range lost.Updates() {
+			}
+			<-lost.Done()
+			assert.ErrorIs(t, lost.Err(), bxerrors.ErrTransportClosed)
+			assert.NotErrorIs(t, lost.Err(), context.Canceled)
+		})
+	}
+
+	// only GRPC streams can be ended by the server
+	t.Run("grpc ended", func(t *testing.T) {
+		api := endingAPI{API: mock.NewAPI()}
+		api.AddMarket(standinMarket, standinMarketAddress, 0.1)
+		s, err := mock.NewServer(api)
+		require.Nil(t, err)
+		defer func() {
+			_ = s.Close()
+		}()
+		g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{Endpoint: s.GRPCEndpoint, Timeout: conformanceTimeout})
+		require.Nil(t, err)
+		defer func() {
+			_ = g.Close()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+		defer cancel()
+
+		sub, err := g.SubscribeTickers(ctx, standinMarket)
+		require.Nil(t, err)
+		_, err = sub.Next(ctx)
+		require.Nil(t, err)
+		_, err = sub.Next(ctx)
+		assert.ErrorIs(t, err, provider.ErrStreamEnded)
+		assert.ErrorIs(t, sub.Err(), provider.ErrStreamEnded)
+	})
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/mock"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	solanarpc "github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedAPI records the trace context PostSubmit requests arrive with
+type tracedAPI struct {
+	*mock.API
+
+	m      sync.Mutex
+	submit trace.SpanContext
+}
+
+func (a *tracedAPI) PostSubmit(ctx context.Context, request *pb.PostSubmitRequest) (*pb.PostSubmitResponse, error) {
+	a.m.Lock()
+	a.submit = trace.SpanContextFromContext(ctx)
+	a.m.Unlock()
+	return a.API.PostSubmit(ctx, request)
+}
+
+func (a *tracedAPI) submitContext() trace.SpanContext {
+	a.m.Lock()
+	defer a.m.Unlock()
+	return a.submit
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no %v span in %v", name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+func TestTracing_SubmitOrder(t *testing.T) {
+	pk, err := solana.NewRandomPrivateKey()
+	require.Nil(t, err)
+	owner := pk.PublicKey().String()
+	payer := solana.NewWallet().PublicKey().String()
+
+	clients := []struct {
+		name      string
+		newClient func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client
+	}{
+		{"http", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client {
+			opts.Endpoint = s.HTTPEndpoint
+			return provider.NewHTTPClientWithOpts(nil, opts).Client()
+		}},
+		{"ws", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client {
+			opts.Endpoint = s.WSEndpoint
+			w, err := provider.NewWSClientWithOpts(opts)
+			require.Nil(t, err)
+			return w
+		}},
+		{"grpc", func(t *testing.T, s *mock.Server, opts provider.RPCOpts) provider.Client {
+			opts.Endpoint = s.GRPCEndpoint
+			g, err := provider.NewGRPCClientWithOpts(opts)
+			require.Nil(t, err)
+			return g
+		}},
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			api := &tracedAPI{API: mock.NewAPI()}
+			api.AddMarket(standinMarket, standinMarketAddress, 0.1)
+			s, err := mock.NewServer(api)
+			require.Nil(t, err)
+			defer func() {
+				_ = s.Close()
+			}()
+			_, rpcEndpoint := newFakeSolanaRPC(t, signatureStatus(10, solanarpc.ConfirmationStatusConfirmed, nil))
+
+			exporter := tracetest.NewInMemoryExporter()
+			client := c.newClient(t, s, provider.RPCOpts{
+				Timeout:             conformanceTimeout,
+				PrivateKey:          &pk,
+				SolanaRPCEndpoint:   rpcEndpoint,
+				WaitForConfirmation: &provider.ConfirmationOpts{PollInterval: 10 * time.Millisecond},
+				Tracing:             &provider.TracingOpts{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))},
+			})
+			defer func() {
+				_ = client.Close()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+			defer cancel()
+
+			_, err = client.SubmitOrder(ctx, owner, payer, standinMarket, pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 98, provider.PostOrderOpts{})
+			require.Nil(t, err)
+
+			spans := exporter.GetSpans()
+			root := findSpan(t, spans, "SubmitOrder")
+			assert.False(t, root.Parent.IsValid())
+			for _, step := range []string{"PostOrder", "SignTransaction", "PostSubmit", "ConfirmTransaction"} {
+				span := findSpan(t, spans, step)
+				assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID(), step)
+				assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID(), step)
+				assert.Equal(t, codes.Unset, span.Status.Code, step)
+			}
+
+			submit := findSpan(t, spans, "PostSubmit")
+			assert.Equal(t, trace.SpanKindClient, submit.SpanKind)
+			assert.Contains(t, submit.Attributes, attribute.String("serum.transport", c.name))
+
+			// the API receives the submit span as its remote parent
+			remote := api.submitContext()
+			assert.True(t, remote.IsRemote())
+			assert.Equal(t, submit.SpanContext.TraceID(), remote.TraceID())
+			assert.Equal(t, submit.SpanContext.SpanID(), remote.SpanID())
+
+			// failures are recorded on the step that failed and the spans containing it
+			exporter.Reset()
+			_, err = client.SubmitOrder(ctx, owner, payer, standinMarket, pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 0.01, 98, provider.PostOrderOpts{})
+			require.NotNil(t, err)
+			spans = exporter.GetSpans()
+			assert.Equal(t, codes.Error, findSpan(t, spans, "PostSubmit").Status.Code)
+			assert.Equal(t, codes.Error, findSpan(t, spans, "SubmitOrder").Status.Code)
+		})
+	}
+}
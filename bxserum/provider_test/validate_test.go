@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validOrderRequest() *pb.PostOrderRequest {
+	owner := solana.NewWallet().PublicKey().String()
+	return &pb.PostOrderRequest{
+		OwnerAddress: owner,
+		PayerAddress: owner,
+		Market:       standinMarket,
+		Side:         pb.Side_S_BID,
+		Type:         []pb.OrderType{pb.OrderType_OT_LIMIT},
+		Amount:       1,
+		Price:        100,
+	}
+}
+
+func TestValidate_PostOrderRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *pb.PostOrderRequest)
+		field  string
+		err    error
+	}{
+		{"no types", func(r *pb.PostOrderRequest) { r.Type = nil }, "order types", provider.ErrInvalidOrderTypes},
+		{"market and limit", func(r *pb.PostOrderRequest) { r.Type = []pb.OrderType{pb.OrderType_OT_MARKET, pb.OrderType_OT_LIMIT} }, "order types", provider.ErrInvalidOrderTypes},
+		{"IOC and post", func(r *pb.PostOrderRequest) {
+			r.Type = []pb.OrderType{pb.OrderType_OT_LIMIT, pb.OrderType_OT_IOC, pb.OrderType_OT_POST}
+		}, "order types", provider.ErrInvalidOrderTypes},
+		{"unknown type", func(r *pb.PostOrderRequest) { r.Type = []pb.OrderType{pb.OrderType_OT_LIMIT, 7} }, "order types", provider.ErrInvalidOrderTypes},
+		{"unknown side", func(r *pb.PostOrderRequest) { r.Side = pb.Side_S_UNKNOWN }, "side", provider.ErrInvalidSide},
+		{"zero price", func(r *pb.PostOrderRequest) { r.Price = 0 }, "price", provider.ErrInvalidPrice},
+		{"negative amount", func(r *pb.PostOrderRequest) { r.Amount = -1 }, "amount", provider.ErrInvalidAmount},
+		{"bad payer", func(r *pb.PostOrderRequest) { r.PayerAddress = "payer" }, "payer", provider.ErrInvalidAddress},
+		{"bad open orders address", func(r *pb.PostOrderRequest) { r.OpenOrdersAddress = "oo" }, "open orders address", provider.ErrInvalidAddress},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := validOrderRequest()
+			test.modify(request)
+
+			err := provider.ValidatePostOrderRequest(request, nil, false)
+			var validationErr *provider.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Equal(t, test.field, validationErr.Field)
+			assert.True(t, errors.Is(err, test.err))
+		})
+	}
+
+	request := validOrderRequest()
+	request.Type = []pb.OrderType{pb.OrderType_OT_LIMIT, pb.OrderType_OT_IOC}
+	assert.Nil(t, provider.ValidatePostOrderRequest(request, nil, false))
+}
+
+func TestValidate_MarketSpec(t *testing.T) {
+	spec := &provider.MarketSpec{TickSize: 0.01, LotSize: 0.1}
+
+	request := validOrderRequest()
+	request.Price, request.Amount = 100.25, 1.3
+	assert.Nil(t, provider.ValidatePostOrderRequest(request, spec, false))
+
+	request.Price = 100.255
+	assert.True(t, errors.Is(provider.ValidatePostOrderRequest(request, spec, false), provider.ErrPriceNotOnTick))
+	request.Price, request.Amount = 100.25, 1.35
+	assert.True(t, errors.Is(provider.ValidatePostOrderRequest(request, spec, false), provider.ErrAmountNotOnLot))
+
+	// bids round down and asks round up, so that rounding never makes an order more aggressive
+	request.Price, request.Amount = 100.255, 1.35
+	require.Nil(t, provider.ValidatePostOrderRequest(request, spec, true))
+	assert.Equal(t, 100.25, request.Price)
+	assert.Equal(t, 1.3, request.Amount)
+
+	request.Side, request.Price = pb.Side_S_ASK, 100.255
+	require.Nil(t, provider.ValidatePostOrderRequest(request, spec, true))
+	assert.Equal(t, 100.26, request.Price)
+
+	// amounts below a lot can't be rounded
+	request.Amount = 0.05
+	assert.True(t, errors.Is(provider.ValidatePostOrderRequest(request, spec, true), provider.ErrAmountNotOnLot))
+}
+
+// newFakeMarketRPC serves getAccountInfo for a Serum market account with the given lot sizes and its mints
+func newFakeMarketRPC(t *testing.T, market solana.PublicKey, baseLotSize, quoteLotSize uint64, baseDecimals, quoteDecimals uint8) (string, *int32) {
+	baseMint, quoteMint := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	marketData := make([]byte, 388)
+	copy(marketData[53:], baseMint[:])
+	copy(marketData[85:], quoteMint[:])
+	binary.LittleEndian.PutUint64(marketData[349:], baseLotSize)
+	binary.LittleEndian.PutUint64(marketData[357:], quoteLotSize)
+	mintData := func(decimals uint8) []byte {
+		data := make([]byte, 82)
+		data[44], data[45] = decimals, 1
+		return data
+	}
+	accounts := map[string][]byte{
+		market.String():    marketData,
+		baseMint.String():  mintData(baseDecimals),
+		quoteMint.String(): mintData(quoteDecimals),
+	}
+
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     interface{}   `json:"id"`
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "getAccountInfo", request.Method)
+		atomic.AddInt32(&requests, 1)
+
+		data, ok := accounts[request.Params[0].(string)]
+		require.True(t, ok)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result": map[string]interface{}{
+				"context": map[string]interface{}{"slot": 1},
+				"value": map[string]interface{}{
+					"data":       []string{base64.StdEncoding.EncodeToString(data), "base64"},
+					"executable": false,
+					"lamports":   1,
+					"owner":      solana.SystemProgramID.String(),
+					"rentEpoch":  0,
+				},
+			},
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s.URL, &requests
+}
+
+func TestValidate_ClientValidation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	owner := solana.NewWallet().PublicKey().String()
+	payer := solana.NewWallet().PublicKey().String()
+
+	// invalid orders fail before any request is sent
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{Endpoint: "http://127.0.0.1:0", OrderValidation: &provider.OrderValidation{}})
+	_, err := h.PostOrderWithContext(ctx, owner, payer, "SOLUSDC", pb.Side_S_UNKNOWN, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+	assert.True(t, errors.Is(err, provider.ErrInvalidSide))
+
+	// specs of unconfigured markets are loaded from chain once, and orders are rounded to them
+	s := newStandin(t)
+	rpcEndpoint, rpcRequests := newFakeMarketRPC(t, solana.MustPublicKeyFromBase58(standinMarketAddress), 100_000_000, 100, 9, 6)
+	g, err := provider.NewGRPCClientWithOpts(provider.RPCOpts{
+		Endpoint:          s.GRPCEndpoint,
+		Timeout:           conformanceTimeout,
+		SolanaRPCEndpoint: rpcEndpoint,
+		OrderValidation:   &provider.OrderValidation{},
+	})
+	require.Nil(t, err)
+
+	_, err = g.PostOrder(ctx, owner, payer, "SOLUSDC", pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1.25, 100, provider.PostOrderOpts{})
+	assert.True(t, errors.Is(err, provider.ErrAmountNotOnLot))
+	_, err = g.PostOrder(ctx, owner, payer, "SOLUSDC", pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1.2, 100.0005, provider.PostOrderOpts{})
+	assert.True(t, errors.Is(err, provider.ErrPriceNotOnTick))
+	_, err = g.PostOrder(ctx, owner, payer, "SOLUSDC", pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1.2, 100.001, provider.PostOrderOpts{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(rpcRequests))
+}
+
+func TestValidate_SpecLoadFailures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	owner := solana.NewWallet().PublicKey().String()
+
+	// the market account can't be read, and the first read waits for release
+	var requests int32
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"error":   map[string]interface{}{"code": -32603, "message": "node is behind"},
+		})
+	}))
+	defer s.Close()
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+
+	const ttl = 200 * time.Millisecond
+	h := provider.NewHTTPClientWithOpts(nil, provider.RPCOpts{
+		Endpoint:          "http://127.0.0.1:0",
+		SolanaRPCEndpoint: s.URL,
+		OrderValidation:   &provider.OrderValidation{SpecFailureTTL: ttl},
+	})
+	postOrder := func(market string) error {
+		_, err := h.PostOrderWithContext(ctx, owner, owner, market, pb.Side_S_BID, []pb.OrderType{pb.OrderType_OT_LIMIT}, 1, 100, provider.PostOrderOpts{})
+		return err
+	}
+
+	// orders on the market wait for a single load of its specs
+	errs := make(chan error, 3)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- postOrder(standinMarketAddress)
+		}()
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&requests) == 1 }, conformanceTimeout, 10*time.Millisecond)
+
+	// while orders on other markets aren't held up by it
+	err := postOrder("BTCUSDC")
+	require.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "node is behind")
+
+	close(release)
+	for i := 0; i < cap(errs); i++ {
+		err := bxassert.ReadChanWithTimeout(t, errs, conformanceTimeout)
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "node is behind")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// the failure is returned until the TTL has passed, then the specs are loaded again
+	require.NotNil(t, postOrder(standinMarketAddress))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	time.Sleep(ttl)
+	require.NotNil(t, postOrder(standinMarketAddress))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -1,12 +1,16 @@
 package provider
 
 import (
+	"context"
 	"github.com/stretchr/testify/require"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/connections"
 	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider_test/bxassert"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
 	"github.com/gagliardetto/solana-go"
 	"github.com/stretchr/testify/assert"
 )
@@ -42,3 +46,55 @@ func TestWS_NewWithOpts(t *testing.T) {
 	assert.NotNil(t, c)
 	assert.Nil(t, err)
 }
+
+func TestWS_Reconnect(t *testing.T) {
+	s := newStandin(t)
+
+	events := make(chan connections.ConnectionEvent, 10)
+	o := provider.RPCOpts{
+		Endpoint: s.WSEndpoint,
+		Timeout:  time.Second,
+		WSReconnect: &connections.ReconnectPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 10 * time.Millisecond,
+			Multiplier:     2,
+		},
+		OnWSConnectionEvent: func(event connections.ConnectionEvent) {
+			events <- event
+		},
+	}
+
+	c, err := provider.NewWSClientWithOpts(o)
+	require.Nil(t, err)
+	defer func() {
+		_ = c.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	orderbookCh := make(chan *pb.GetOrderbooksStreamResponse, 10)
+	err = c.GetOrderbooksStream(ctx, []string{"SOLUSDC"}, 0, orderbookCh)
+	require.Nil(t, err)
+	for i := 0; i < standinStreamUpdates; i++ {
+		bxassert.ReadChanWithTimeout(t, orderbookCh, time.Second)
+	}
+
+	s.DropWSConnections()
+
+	event := bxassert.ReadChanWithTimeout(t, events, time.Second)
+	assert.Equal(t, connections.Disconnected, event.Type)
+	event = bxassert.ReadChanWithTimeout(t, events, time.Second)
+	assert.Equal(t, connections.Reconnected, event.Type)
+
+	// subscription is resumed on the original channel
+	for i := 0; i < standinStreamUpdates; i++ {
+		update := bxassert.ReadChanWithTimeout(t, orderbookCh, time.Second)
+		require.NotNil(t, update)
+		assert.Equal(t, int64(i+1), update.BlockHeight)
+	}
+
+	// requests work on the new connection
+	_, err = c.GetMarkets(ctx)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,295 @@
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxRecordSize bounds the records read, so that corrupt length prefixes fail instead of allocating
+const maxRecordSize = 64 << 20
+
+// zstdMagic starts every zstd frame, and is how readers tell compressed recordings apart
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+var ErrInvalidRecord = errors.New("invalid recording record")
+
+// Record is a stream update with the time it was received. Exactly one of the updates is set.
+type Record struct {
+	ReceivedAt time.Time
+	// Market is the market the stream was subscribed to, as trades updates don't name it. Orderbooks streams follow
+	// several markets, so their records have the market of the orderbook.
+	Market    string
+	Orderbook *pb.GetOrderbooksStreamResponse
+	Trades    *pb.GetTradesStreamResponse
+	Ticker    *pb.GetTickersStreamResponse
+}
+
+// record fields, encoded as the protobuf message:
+//
+//	message Record {
+//	  int64 received_at = 1; // unix nanoseconds
+//	  string market = 5;
+//	  oneof update {
+//	    GetOrderbooksStreamResponse orderbook = 2;
+//	    GetTradesStreamResponse trades = 3;
+//	    GetTickersStreamResponse ticker = 4;
+//	  }
+//	}
+const (
+	fieldReceivedAt protowire.Number = 1
+	fieldOrderbook  protowire.Number = 2
+	fieldTrades     protowire.Number = 3
+	fieldTicker     protowire.Number = 4
+	fieldMarket     protowire.Number = 5
+)
+
+// WriterOpts configures a Writer
+type WriterOpts struct {
+	// Compress compresses the recording with zstd
+	Compress bool
+}
+
+// Writer writes records as a sequence of varint length-delimited protobuf messages, optionally compressed with zstd.
+// It is safe for concurrent use.
+type Writer struct {
+	m       sync.Mutex
+	buf     *bufio.Writer
+	encoder *zstd.Encoder
+	file    *os.File
+	scratch []byte
+}
+
+// Create creates or truncates the recording file at path
+func Create(path string, opts WriterOpts) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f, opts)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	w.file = f
+	return w, nil
+}
+
+// NewWriter writes a recording to w. Close must be called to flush the records.
+func NewWriter(w io.Writer, opts WriterOpts) (*Writer, error) {
+	if !opts.Compress {
+		return &Writer{buf: bufio.NewWriter(w)}, nil
+	}
+
+	encoder, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{buf: bufio.NewWriter(encoder), encoder: encoder}, nil
+}
+
+// Write appends record to the recording
+func (w *Writer) Write(record Record) error {
+	var field protowire.Number
+	var update proto.Message
+	switch {
+	case record.Orderbook != nil:
+		field, update = fieldOrderbook, record.Orderbook
+	case record.Trades != nil:
+		field, update = fieldTrades, record.Trades
+	case record.Ticker != nil:
+		field, update = fieldTicker, record.Ticker
+	default:
+		return fmt.Errorf("%w: no update", ErrInvalidRecord)
+	}
+	b, err := proto.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	message := protowire.AppendTag(w.scratch[:0], fieldReceivedAt, protowire.VarintType)
+	message = protowire.AppendVarint(message, uint64(record.ReceivedAt.UnixNano()))
+	message = protowire.AppendTag(message, field, protowire.BytesType)
+	message = protowire.AppendBytes(message, b)
+	if record.Market != "" {
+		message = protowire.AppendTag(message, fieldMarket, protowire.BytesType)
+		message = protowire.AppendString(message, record.Market)
+	}
+	w.scratch = message
+
+	var length [binary.MaxVarintLen64]byte
+	if _, err := w.buf.Write(length[:binary.PutUvarint(length[:], uint64(len(message)))]); err != nil {
+		return err
+	}
+	_, err = w.buf.Write(message)
+	return err
+}
+
+// Flush writes the buffered records to the underlying writer, ending the current zstd block
+func (w *Writer) Flush() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if w.encoder != nil {
+		return w.encoder.Flush()
+	}
+	return nil
+}
+
+// Close flushes the recording, and closes its file if it was opened by Create
+func (w *Writer) Close() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	err := w.buf.Flush()
+	if w.encoder != nil {
+		if closeErr := w.encoder.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Reader reads the records of a recording, compressed or not
+type Reader struct {
+	buf     *bufio.Reader
+	decoder *zstd.Decoder
+	file    *os.File
+}
+
+// Open opens the recording file at path
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r.file = f
+	return r, nil
+}
+
+// NewReader reads a recording from r, detecting whether it's compressed
+func NewReader(r io.Reader) (*Reader, error) {
+	buf := bufio.NewReader(r)
+	magic, err := buf.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(magic, zstdMagic) {
+		return &Reader{buf: buf}, nil
+	}
+
+	decoder, err := zstd.NewReader(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{buf: bufio.NewReader(decoder), decoder: decoder}, nil
+}
+
+// Next returns the next record. It returns io.EOF at the end of the recording, and io.ErrUnexpectedEOF if the recording
+// was cut short in the middle of a record, e.g. because the recorder was killed.
+func (r *Reader) Next() (*Record, error) {
+	length, err := binary.ReadUvarint(r.buf)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxRecordSize {
+		return nil, fmt.Errorf("%w: record of %v bytes", ErrInvalidRecord, length)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(r.buf, message); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return decodeRecord(message)
+}
+
+// Close releases the decoder, and closes the file if the recording was opened by Open
+func (r *Reader) Close() error {
+	if r.decoder != nil {
+		r.decoder.Close()
+	}
+	if r.file != nil {
+		return r.file.Close()
+	}
+	return nil
+}
+
+func decodeRecord(message []byte) (*Record, error) {
+	record := &Record{}
+	for len(message) > 0 {
+		field, wireType, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, protowire.ParseError(n))
+		}
+		message = message[n:]
+
+		var err error
+		switch {
+		case field == fieldReceivedAt && wireType == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(message)
+			record.ReceivedAt = time.Unix(0, int64(v))
+		case field == fieldOrderbook && wireType == protowire.BytesType:
+			var b []byte
+			b, n = protowire.ConsumeBytes(message)
+			record.Orderbook = &pb.GetOrderbooksStreamResponse{}
+			err = proto.Unmarshal(b, record.Orderbook)
+		case field == fieldTrades && wireType == protowire.BytesType:
+			var b []byte
+			b, n = protowire.ConsumeBytes(message)
+			record.Trades = &pb.GetTradesStreamResponse{}
+			err = proto.Unmarshal(b, record.Trades)
+		case field == fieldTicker && wireType == protowire.BytesType:
+			var b []byte
+			b, n = protowire.ConsumeBytes(message)
+			record.Ticker = &pb.GetTickersStreamResponse{}
+			err = proto.Unmarshal(b, record.Ticker)
+		case field == fieldMarket && wireType == protowire.BytesType:
+			record.Market, n = protowire.ConsumeString(message)
+		default:
+			// fields of newer recordings are skipped
+			n = protowire.ConsumeFieldValue(field, wireType, message)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, protowire.ParseError(n))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		message = message[n:]
+	}
+
+	if record.Orderbook == nil && record.Trades == nil && record.Ticker == nil {
+		return nil, fmt.Errorf("%w: no update", ErrInvalidRecord)
+	}
+	return record, nil
+}
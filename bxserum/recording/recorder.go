@@ -0,0 +1,137 @@
+// Package recording persists orderbook, trades and ticker stream updates to files, and replays them through the same
+// channel based stream methods as the provider clients, e.g. to backtest strategies against real market data.
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+const streamBuffer = 100
+
+var ErrNoStreams = errors.New("at least one stream must be recorded")
+
+// Streamer is the set of stream methods that are recorded and replayed. It is implemented by all provider clients, the
+// provider.Pool and Replayer.
+type Streamer interface {
+	GetOrderbooksStream(ctx context.Context, markets []string, limit uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error
+	GetTradesStream(ctx context.Context, market string, limit uint32, tradesChan chan *pb.GetTradesStreamResponse) error
+	GetTickersStream(ctx context.Context, market string, tickersChan chan *pb.GetTickersStreamResponse) error
+}
+
+var (
+	_ Streamer = (*provider.HTTPClient)(nil)
+	_ Streamer = (provider.StreamingClient)(nil)
+	_ Streamer = (*Replayer)(nil)
+)
+
+// Streams selects the streams to record
+type Streams struct {
+	// Orderbooks are the markets whose orderbooks are recorded, with at most OrderbookLimit levels per side if set
+	Orderbooks     []string
+	OrderbookLimit uint32
+	// Trades are the markets whose trades are recorded, with at most TradesLimit trades per update if set
+	Trades      []string
+	TradesLimit uint32
+	// Tickers are the markets whose tickers are recorded
+	Tickers []string
+}
+
+// Recorder writes the updates of streams to a recording
+type Recorder struct {
+	client  Streamer
+	w       *Writer
+	streams Streams
+}
+
+// NewRecorder records streams subscribed to with client to w. The caller closes w once the Recorder has stopped.
+func NewRecorder(client Streamer, w *Writer, streams Streams) (*Recorder, error) {
+	if len(streams.Orderbooks) == 0 && len(streams.Trades) == 0 && len(streams.Tickers) == 0 {
+		return nil, ErrNoStreams
+	}
+	return &Recorder{client: client, w: w, streams: streams}, nil
+}
+
+// Run subscribes to the streams and writes every update with the time it was received, until ctx is done or one of the
+// streams ends. Subscription errors are returned right away.
+func (r *Recorder) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// every stream reports how it ended, and the recording only stops once they all have, so that w isn't written
+	// after Run returns
+	count := 0
+	errs := make(chan error, 1+len(r.streams.Trades)+len(r.streams.Tickers))
+	stop := func(err error) error {
+		cancel()
+		for ; count > 0; count-- {
+			<-errs
+		}
+		return err
+	}
+	if len(r.streams.Orderbooks) > 0 {
+		orderbooks := make(chan *pb.GetOrderbooksStreamResponse, streamBuffer)
+		if err := r.client.GetOrderbooksStream(ctx, r.streams.Orderbooks, r.streams.OrderbookLimit, orderbooks); err != nil {
+			return stop(fmt.Errorf("failed to subscribe to orderbooks of %v: %w", r.streams.Orderbooks, err))
+		}
+		count++
+		go func() {
+			errs <- record(ctx, r.w, "orderbooks", orderbooks, func(update *pb.GetOrderbooksStreamResponse, receivedAt time.Time) Record {
+				return Record{ReceivedAt: receivedAt, Market: update.GetOrderbook().GetMarket(), Orderbook: update}
+			})
+		}()
+	}
+	for _, market := range r.streams.Trades {
+		trades := make(chan *pb.GetTradesStreamResponse, streamBuffer)
+		if err := r.client.GetTradesStream(ctx, market, r.streams.TradesLimit, trades); err != nil {
+			return stop(fmt.Errorf("failed to subscribe to trades of %v: %w", market, err))
+		}
+		count++
+		go func(market string) {
+			errs <- record(ctx, r.w, "trades of "+market, trades, func(update *pb.GetTradesStreamResponse, receivedAt time.Time) Record {
+				return Record{ReceivedAt: receivedAt, Market: market, Trades: update}
+			})
+		}(market)
+	}
+	for _, market := range r.streams.Tickers {
+		tickers := make(chan *pb.GetTickersStreamResponse, streamBuffer)
+		if err := r.client.GetTickersStream(ctx, market, tickers); err != nil {
+			return stop(fmt.Errorf("failed to subscribe to tickers of %v: %w", market, err))
+		}
+		count++
+		go func(market string) {
+			errs <- record(ctx, r.w, "tickers of "+market, tickers, func(update *pb.GetTickersStreamResponse, receivedAt time.Time) Record {
+				return Record{ReceivedAt: receivedAt, Market: market, Ticker: update}
+			})
+		}(market)
+	}
+
+	// the first stream to end stops the others
+	err := <-errs
+	count--
+	return stop(err)
+}
+
+func record[T any](ctx context.Context, w *Writer, stream string, updates chan T, newRecord func(T, time.Time) Record) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return fmt.Errorf("%v stream ended", stream)
+			}
+			if err := w.Write(newRecord(update, time.Now())); err != nil {
+				return fmt.Errorf("failed to record %v: %w", stream, err)
+			}
+		}
+	}
+}
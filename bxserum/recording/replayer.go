@@ -0,0 +1,235 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	pb "github.com/bloXroute-Labs/serum-client-go/proto"
+)
+
+// MaxSpeed replays recordings as fast as the subscriber reads them
+const MaxSpeed = math.MaxFloat64
+
+var ErrInvalidSpeed = errors.New("replay speed must not be negative")
+
+// ReplayOpts configures a Replayer
+type ReplayOpts struct {
+	// Speed multiplies the pace of the recording: 1 replays updates with the delays they were received with, 10 ten
+	// times faster and MaxSpeed without delays. Defaults to 1.
+	Speed float64
+}
+
+// Replayer replays a recording through the stream methods of the provider clients, so that code written against them
+// can be run on recorded data. Every stream reads the recording from its start and sends each update as long after the
+// subscription as it was received after the first record, divided by the speed, so streams subscribed together keep
+// the recorded order. Streams end at the end of the recording, or at the last complete record of a recording that was
+// cut short: subscriptions then end with provider.ErrStreamEnded, and channels are closed. A record that can't be
+// read ends them too, and the subscriptions with the read error, e.g. one matching ErrInvalidRecord.
+type Replayer struct {
+	path  string
+	speed float64
+	// start is when the first record was received
+	start time.Time
+}
+
+// NewReplayer replays the recording file at path
+func NewReplayer(path string, opts ReplayOpts) (*Replayer, error) {
+	if opts.Speed < 0 {
+		return nil, ErrInvalidSpeed
+	}
+	if opts.Speed == 0 {
+		opts.Speed = 1
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	replayer := &Replayer{path: path, speed: opts.Speed}
+	first, err := r.Next()
+	if err == nil {
+		replayer.start = first.ReceivedAt
+	} else if err != io.EOF {
+		return nil, err
+	}
+	return replayer, nil
+}
+
+// GetOrderbooksStream replays the orderbooks of markets, which are matched against the recorded market names and
+// addresses. The limit is not applied, as updates are replayed as recorded.
+func (r *Replayer) GetOrderbooksStream(ctx context.Context, markets []string, _ uint32, orderbookChan chan *pb.GetOrderbooksStreamResponse) error {
+	return replayTo(ctx, r, orderbookChan, orderbooks(markets))
+}
+
+// SubscribeOrderbooks is GetOrderbooksStream returning a Subscription, which tells why the replay ended
+func (r *Replayer) SubscribeOrderbooks(ctx context.Context, markets []string, _ uint32) (*provider.Subscription[*pb.GetOrderbooksStreamResponse], error) {
+	return subscribe(ctx, r, orderbooks(markets))
+}
+
+// GetTradesStream replays the trades of market, which must be the market name the trades were recorded under. The
+// limit is not applied, as updates are replayed as recorded.
+func (r *Replayer) GetTradesStream(ctx context.Context, market string, _ uint32, tradesChan chan *pb.GetTradesStreamResponse) error {
+	return replayTo(ctx, r, tradesChan, trades(market))
+}
+
+// SubscribeTrades is GetTradesStream returning a Subscription, which tells why the replay ended
+func (r *Replayer) SubscribeTrades(ctx context.Context, market string, _ uint32) (*provider.Subscription[*pb.GetTradesStreamResponse], error) {
+	return subscribe(ctx, r, trades(market))
+}
+
+// GetTickersStream replays the tickers of market, which is matched against the recorded market name and the addresses
+// of the tickers
+func (r *Replayer) GetTickersStream(ctx context.Context, market string, tickersChan chan *pb.GetTickersStreamResponse) error {
+	return replayTo(ctx, r, tickersChan, tickers(market))
+}
+
+// SubscribeTickers is GetTickersStream returning a Subscription, which tells why the replay ended
+func (r *Replayer) SubscribeTickers(ctx context.Context, market string) (*provider.Subscription[*pb.GetTickersStreamResponse], error) {
+	return subscribe(ctx, r, tickers(market))
+}
+
+func orderbooks(markets []string) func(*Record) (*pb.GetOrderbooksStreamResponse, bool) {
+	match := marketMatcher(markets)
+	return func(record *Record) (*pb.GetOrderbooksStreamResponse, bool) {
+		if record.Orderbook == nil {
+			return nil, false
+		}
+		return record.Orderbook, match(record.Market) || match(record.Orderbook.GetOrderbook().GetMarketAddress())
+	}
+}
+
+func trades(market string) func(*Record) (*pb.GetTradesStreamResponse, bool) {
+	return func(record *Record) (*pb.GetTradesStreamResponse, bool) {
+		return record.Trades, record.Trades != nil && record.Market == market
+	}
+}
+
+func tickers(market string) func(*Record) (*pb.GetTickersStreamResponse, bool) {
+	return func(record *Record) (*pb.GetTickersStreamResponse, bool) {
+		if record.Ticker == nil {
+			return nil, false
+		}
+		if record.Market == market {
+			return record.Ticker, true
+		}
+		for _, ticker := range record.Ticker.GetTicker().GetTickers() {
+			if ticker.MarketAddress == market {
+				return record.Ticker, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// replayTo sends the updates of the replay to ch until it ends, then closes ch
+func replayTo[T any](ctx context.Context, r *Replayer, ch chan T, match func(*Record) (T, bool)) error {
+	generator, err := replay(ctx, r, match)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(ch)
+		for {
+			update, err := generator()
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func subscribe[T any](ctx context.Context, r *Replayer, match func(*Record) (T, bool)) (*provider.Subscription[T], error) {
+	generator, err := replay(ctx, r, match)
+	if err != nil {
+		return nil, err
+	}
+	return provider.NewSubscription(ctx, generator), nil
+}
+
+// replay returns a generator of the updates match selects from the recording, paced from now. The generator fails with
+// io.EOF at the end of the recording, the error of ctx once it's done, or the error of the record that couldn't be read.
+func replay[T any](ctx context.Context, r *Replayer, match func(*Record) (T, bool)) (func() (T, error), error) {
+	reader, err := Open(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribed := time.Now()
+	var ended error
+	next := func() (T, error) {
+		var zero T
+		for {
+			record, err := reader.Next()
+			// only the last record can be incomplete, as the recording ends with it
+			if err == io.ErrUnexpectedEOF {
+				return zero, io.EOF
+			}
+			if err != nil {
+				return zero, err
+			}
+			update, ok := match(record)
+			if !ok {
+				continue
+			}
+			if !r.wait(ctx, subscribed, record.ReceivedAt) {
+				return zero, ctx.Err()
+			}
+			return update, nil
+		}
+	}
+	return func() (T, error) {
+		if ended == nil {
+			var update T
+			update, ended = next()
+			if ended == nil {
+				return update, nil
+			}
+			_ = reader.Close()
+		}
+		var zero T
+		return zero, ended
+	}, nil
+}
+
+// wait waits until the time receivedAt corresponds to for a stream subscribed at subscribed, and returns false if ctx
+// is done first
+func (r *Replayer) wait(ctx context.Context, subscribed, receivedAt time.Time) bool {
+	delay := time.Until(subscribed.Add(time.Duration(float64(receivedAt.Sub(r.start)) / r.speed)))
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func marketMatcher(markets []string) func(string) bool {
+	set := make(map[string]bool, len(markets))
+	for _, market := range markets {
+		set[market] = true
+	}
+	return func(market string) bool {
+		return market != "" && set[market]
+	}
+}
@@ -0,0 +1,207 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+)
+
+const defaultInterval = time.Minute
+
+var (
+	ErrMissingOwnerAddress = errors.New("owner address must be provided")
+	ErrNoMarkets           = errors.New("at least one market must be provided")
+	ErrWalletNotFound      = errors.New("token wallet not found in account balance")
+)
+
+// Policy decides when the unsettled funds of a market are settled, and where to
+type Policy struct {
+	// BaseThreshold and QuoteThreshold are the unsettled amounts that trigger a settlement. The market is settled when
+	// either is reached, and a zero threshold settles any amount of that token.
+	BaseThreshold  float64
+	QuoteThreshold float64
+	// BaseTokenWallet and QuoteTokenWallet are the wallets funds are settled to. They are resolved by token symbol from
+	// GetAccountBalance when not provided, which requires the market name to be in the A/B, A-B or A:B format.
+	BaseTokenWallet  string
+	QuoteTokenWallet string
+}
+
+// Opts configures a Worker
+type Opts struct {
+	// Owner of the funds, who must be able to sign for the client
+	Owner string
+	// Markets maps the markets to settle to their policy
+	Markets map[string]Policy
+	// Interval between checks when running, defaults to a minute
+	Interval time.Duration
+	// DryRun reports the settlements that would be submitted without submitting them
+	DryRun        bool
+	SkipPreFlight bool
+	// OnSettle is called with the result of every settlement
+	OnSettle func(Result)
+}
+
+// Result is a settlement of an open orders account
+type Result struct {
+	Market            string
+	OpenOrdersAddress string
+	BaseAmount        float64
+	QuoteAmount       float64
+	BaseTokenWallet   string
+	QuoteTokenWallet  string
+	// Signature of the settle transaction, empty in dry run mode or if the settlement failed
+	Signature string
+	DryRun    bool
+	Err       error
+}
+
+// Worker periodically settles the unsettled funds of an owner that cross the thresholds of its market policies
+type Worker struct {
+	client provider.Client
+	opts   Opts
+}
+
+func NewWorker(client provider.Client, opts Opts) (*Worker, error) {
+	if opts.Owner == "" {
+		return nil, ErrMissingOwnerAddress
+	}
+	if len(opts.Markets) == 0 {
+		return nil, ErrNoMarkets
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	return &Worker{client: client, opts: opts}, nil
+}
+
+// Run settles funds every interval until ctx is done. Failed settlements are reported through OnSettle and retried on
+// the next check.
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce checks the unsettled funds of every market and settles the accounts that cross the policy thresholds. It
+// returns the results of the settlements attempted, and the first error encountered.
+func (w *Worker) RunOnce(ctx context.Context) ([]Result, error) {
+	markets := make([]string, 0, len(w.opts.Markets))
+	for market := range w.opts.Markets {
+		markets = append(markets, market)
+	}
+	sort.Strings(markets)
+
+	var results []Result
+	var firstErr error
+	wallets := &walletResolver{client: w.client, owner: w.opts.Owner}
+	for _, market := range markets {
+		marketResults, err := w.settleMarket(ctx, market, w.opts.Markets[market], wallets)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		results = append(results, marketResults...)
+	}
+	return results, firstErr
+}
+
+func (w *Worker) settleMarket(ctx context.Context, market string, policy Policy, wallets *walletResolver) ([]Result, error) {
+	unsettled, err := w.client.GetUnsettled(ctx, market, w.opts.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unsettled funds of %v: %w", market, err)
+	}
+
+	var results []Result
+	var firstErr error
+	for _, account := range unsettled.Unsettled {
+		base, quote := account.GetBaseToken().GetAmount(), account.GetQuoteToken().GetAmount()
+		if !policy.due(base, quote) {
+			continue
+		}
+
+		result := Result{Market: market, OpenOrdersAddress: account.Account, BaseAmount: base, QuoteAmount: quote, DryRun: w.opts.DryRun}
+		result.BaseTokenWallet, result.QuoteTokenWallet, result.Err = wallets.resolve(ctx, market, policy)
+		if result.Err == nil && !w.opts.DryRun {
+			result.Signature, result.Err = w.client.SubmitSettle(ctx, w.opts.Owner, market, result.BaseTokenWallet, result.QuoteTokenWallet, account.Account, w.opts.SkipPreFlight)
+		}
+		if result.Err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to settle %v of %v: %w", account.Account, market, result.Err)
+		}
+
+		results = append(results, result)
+		if w.opts.OnSettle != nil {
+			w.opts.OnSettle(result)
+		}
+	}
+	return results, firstErr
+}
+
+// due reports whether the unsettled amounts cross the policy thresholds
+func (p Policy) due(base, quote float64) bool {
+	return (base > 0 && base >= p.BaseThreshold) || (quote > 0 && quote >= p.QuoteThreshold)
+}
+
+// walletResolver looks up the owner's token wallets, fetching the account balance at most once
+type walletResolver struct {
+	client  provider.Client
+	owner   string
+	fetched bool
+	wallets map[string]string
+}
+
+func (r *walletResolver) resolve(ctx context.Context, market string, policy Policy) (string, string, error) {
+	base, quote := policy.BaseTokenWallet, policy.QuoteTokenWallet
+	if base != "" && quote != "" {
+		return base, quote, nil
+	}
+
+	baseSymbol, quoteSymbol, ok := splitMarket(market)
+	if !ok {
+		return "", "", fmt.Errorf("can't resolve token wallets of %v: provide them in its policy or use the A/B market format", market)
+	}
+	if !r.fetched {
+		balance, err := r.client.GetAccountBalance(ctx, r.owner)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get account balance: %w", err)
+		}
+		r.wallets = make(map[string]string)
+		for _, token := range balance.Tokens {
+			r.wallets[strings.ToUpper(token.Symbol)] = token.Address
+		}
+		r.fetched = true
+	}
+
+	if base == "" {
+		if base, ok = r.wallets[baseSymbol]; !ok {
+			return "", "", fmt.Errorf("%w: %v", ErrWalletNotFound, baseSymbol)
+		}
+	}
+	if quote == "" {
+		if quote, ok = r.wallets[quoteSymbol]; !ok {
+			return "", "", fmt.Errorf("%w: %v", ErrWalletNotFound, quoteSymbol)
+		}
+	}
+	return base, quote, nil
+}
+
+func splitMarket(market string) (string, string, bool) {
+	for _, separator := range []string{"/", "-", ":"} {
+		if parts := strings.Split(market, separator); len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			return strings.ToUpper(parts[0]), strings.ToUpper(parts[1]), true
+		}
+	}
+	return "", "", false
+}
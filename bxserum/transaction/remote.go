@@ -0,0 +1,178 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Remote signer protocol: the signer serves its public key on GET /publicKey and signs base64 encoded messages on
+// POST /sign. Failures are reported with a non-200 status and a JSON body with a message.
+const (
+	remotePublicKeyPath = "/publicKey"
+	remoteSignPath      = "/sign"
+)
+
+type remotePublicKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
+
+type remoteSignRequest struct {
+	PublicKey string `json:"publicKey"`
+	Message   string `json:"message"`
+}
+
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+type remoteError struct {
+	Message string `json:"message"`
+}
+
+// RemoteSigner delegates signing to a signing service over HTTP, so the private key never enters the process
+type RemoteSigner struct {
+	endpoint   string
+	httpClient *http.Client
+	publicKey  solana.PublicKey
+}
+
+// NewRemoteSigner connects to the signing service at endpoint and fetches the public key it signs for. Set client to
+// nil to use the default client.
+func NewRemoteSigner(ctx context.Context, endpoint string, client *http.Client) (*RemoteSigner, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	s := &RemoteSigner{endpoint: strings.TrimSuffix(endpoint, "/"), httpClient: client}
+
+	var response remotePublicKeyResponse
+	if err := s.call(ctx, http.MethodGet, remotePublicKeyPath, nil, &response); err != nil {
+		return nil, err
+	}
+	publicKey, err := solana.PublicKeyFromBase58(response.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid public key: %w", err)
+	}
+	s.publicKey = publicKey
+	return s, nil
+}
+
+func (s *RemoteSigner) PublicKey() solana.PublicKey {
+	return s.publicKey
+}
+
+// SignMessage requests a signature from the signing service and verifies it before returning
+func (s *RemoteSigner) SignMessage(ctx context.Context, message []byte) (solana.Signature, error) {
+	request := remoteSignRequest{PublicKey: s.publicKey.String(), Message: base64.StdEncoding.EncodeToString(message)}
+	var response remoteSignResponse
+	if err := s.call(ctx, http.MethodPost, remoteSignPath, request, &response); err != nil {
+		return solana.Signature{}, err
+	}
+
+	signature, err := solana.SignatureFromBase58(response.Signature)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	if !signature.Verify(s.publicKey, message) {
+		return solana.Signature{}, errors.New("remote signer returned a signature that does not match its public key")
+	}
+	return signature, nil
+}
+
+func (s *RemoteSigner) call(ctx context.Context, method, path string, request, response interface{}) error {
+	var body []byte
+	if request != nil {
+		var err error
+		body, err = json.Marshal(request)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var rErr remoteError
+		if err := json.Unmarshal(b, &rErr); err != nil || rErr.Message == "" {
+			return fmt.Errorf("remote signer responded with %v", resp.Status)
+		}
+		return fmt.Errorf("remote signer: %v", rErr.Message)
+	}
+	return json.Unmarshal(b, response)
+}
+
+// NewRemoteSignerHandler serves signer over the remote signer protocol, e.g. from a separate signing process
+func NewRemoteSignerHandler(signer Signer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(remotePublicKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeRemoteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		writeRemoteJSON(w, remotePublicKeyResponse{PublicKey: signer.PublicKey().String()})
+	})
+	mux.HandleFunc(remoteSignPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeRemoteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var request remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeRemoteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if request.PublicKey != signer.PublicKey().String() {
+			writeRemoteError(w, http.StatusBadRequest, fmt.Sprintf("cannot sign for %v", request.PublicKey))
+			return
+		}
+		message, err := base64.StdEncoding.DecodeString(request.Message)
+		if err != nil {
+			writeRemoteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		signature, err := signer.SignMessage(r.Context(), message)
+		if err != nil {
+			writeRemoteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeRemoteJSON(w, remoteSignResponse{Signature: signature.String()})
+	})
+	return mux
+}
+
+func writeRemoteJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeRemoteError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(remoteError{Message: message})
+}
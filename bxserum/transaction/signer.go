@@ -0,0 +1,50 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Signer signs transaction messages on behalf of a single account. Implementations may keep the key outside the
+// process, so signing can block and is bound to ctx.
+type Signer interface {
+	PublicKey() solana.PublicKey
+	SignMessage(ctx context.Context, message []byte) (solana.Signature, error)
+}
+
+// PrivateKeySigner signs with a private key held in memory
+type PrivateKeySigner struct {
+	privateKey solana.PrivateKey
+}
+
+// NewPrivateKeySigner returns a Signer for an in-memory private key
+func NewPrivateKeySigner(privateKey solana.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{privateKey: privateKey}
+}
+
+// NewKeypairFileSigner loads a keypair JSON file written by the Solana CLI (e.g. `solana-keygen new`)
+func NewKeypairFileSigner(path string) (*PrivateKeySigner, error) {
+	privateKey, err := solana.PrivateKeyFromSolanaKeygenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKeySigner(privateKey), nil
+}
+
+// LoadSignerFromEnv returns a signer for the `PRIVATE_KEY` environment variable
+func LoadSignerFromEnv() (*PrivateKeySigner, error) {
+	privateKey, err := LoadPrivateKeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKeySigner(privateKey), nil
+}
+
+func (s *PrivateKeySigner) PublicKey() solana.PublicKey {
+	return s.privateKey.PublicKey()
+}
+
+func (s *PrivateKeySigner) SignMessage(_ context.Context, message []byte) (solana.Signature, error) {
+	return s.privateKey.Sign(message)
+}
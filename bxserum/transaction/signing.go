@@ -1,7 +1,7 @@
 package transaction
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/gagliardetto/solana-go"
 	solanarpc "github.com/gagliardetto/solana-go/rpc"
@@ -28,60 +28,131 @@ func SignTx(unsignedTxBase64 string) (string, error) {
 	return SignTxWithPrivateKey(unsignedTxBase64, privateKey)
 }
 
-// SignTxWithPrivateKey uses the provided private key to sign the message content and replace the zero signature
+// SignTxWithPrivateKey uses the provided private key to sign the message content and fill its signature slot
 func SignTxWithPrivateKey(unsignedTxBase64 string, privateKey solana.PrivateKey) (string, error) {
-	unsignedTxBytes, err := solanarpc.DataBytesOrJSONFromBase64(unsignedTxBase64)
+	return SignTxWithSigners(context.Background(), unsignedTxBase64, NewPrivateKeySigner(privateKey))
+}
+
+// SignTxWithSigner uses the provided signer to sign the message content and fill its signature slot
+func SignTxWithSigner(ctx context.Context, unsignedTxBase64 string, signer Signer) (string, error) {
+	return SignTxWithSigners(ctx, unsignedTxBase64, signer)
+}
+
+// SignTxWithSigners signs the transaction with every signer, and fails with a *MissingSignersError if any required
+// signature is still missing afterwards
+func SignTxWithSigners(ctx context.Context, unsignedTxBase64 string, signers ...Signer) (string, error) {
+	signedTxBase64, missing, err := PartialSignTx(ctx, unsignedTxBase64, signers...)
 	if err != nil {
 		return "", err
 	}
-	unsignedTx := solanarpc.TransactionWithMeta{Transaction: unsignedTxBytes}
-	solanaTx, err := unsignedTx.GetTransaction()
+	if len(missing) > 0 {
+		return "", &MissingSignersError{Missing: missing}
+	}
+	return signedTxBase64, nil
+}
+
+// PartialSignTx fills the signature slots of the provided signers and returns the signers that still need to sign, so
+// that several parties can sign the transaction in sequence. Signatures already present are kept.
+func PartialSignTx(ctx context.Context, txBase64 string, signers ...Signer) (string, []solana.PublicKey, error) {
+	solanaTx, err := decodeTx(txBase64)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	err = signTx(solanaTx, privateKey)
+	err = signTx(ctx, solanaTx, signers)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	signedTxBase64, err := solanaTx.ToBase64()
+	if err != nil {
+		return "", nil, err
+	}
+	return signedTxBase64, missingSigners(solanaTx), nil
+}
+
+// MissingSigners returns the required signers of the transaction that have not signed it yet
+func MissingSigners(txBase64 string) ([]solana.PublicKey, error) {
+	solanaTx, err := decodeTx(txBase64)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSignatureSlots(solanaTx); err != nil {
+		return nil, err
 	}
+	return missingSigners(solanaTx), nil
+}
+
+// MissingSignersError is returned when a transaction is not fully signed
+type MissingSignersError struct {
+	Missing []solana.PublicKey
+}
 
-	return solanaTx.ToBase64()
+func (e *MissingSignersError) Error() string {
+	return fmt.Sprintf("transaction is missing signatures from %v", e.Missing)
 }
 
-func signTx(solanaTx *solana.Transaction, privateKey solana.PrivateKey) error {
+func decodeTx(txBase64 string) (*solana.Transaction, error) {
+	txBytes, err := solanarpc.DataBytesOrJSONFromBase64(txBase64)
+	if err != nil {
+		return nil, err
+	}
+	tx := solanarpc.TransactionWithMeta{Transaction: txBytes}
+	return tx.GetTransaction()
+}
+
+func checkSignatureSlots(solanaTx *solana.Transaction) error {
 	signaturesRequired := int(solanaTx.Message.Header.NumRequiredSignatures)
 	signaturesPresent := len(solanaTx.Signatures)
 	if signaturesPresent != signaturesRequired {
 		return fmt.Errorf("transaction requires %v signatures and has %v signatures", signaturesRequired, signaturesPresent)
 	}
-
-	return replaceZeroSignature(solanaTx, privateKey)
+	if len(solanaTx.Message.AccountKeys) < signaturesRequired {
+		return fmt.Errorf("transaction requires %v signatures but only has %v accounts", signaturesRequired, len(solanaTx.Message.AccountKeys))
+	}
+	return nil
 }
 
-func replaceZeroSignature(tx *solana.Transaction, privateKey solana.PrivateKey) error {
-	messageContent, err := tx.Message.MarshalBinary()
-	if err != nil {
-		return fmt.Errorf("unable to encode message for signing: %w", err)
+// signTx places each signer's signature at the index of its public key: the first NumRequiredSignatures account keys
+// are the required signers, in the same order as the signatures
+func signTx(ctx context.Context, solanaTx *solana.Transaction, signers []Signer) error {
+	if err := checkSignatureSlots(solanaTx); err != nil {
+		return err
 	}
 
-	signedMessageContent, err := privateKey.Sign(messageContent)
+	messageContent, err := solanaTx.Message.MarshalBinary()
 	if err != nil {
-		return fmt.Errorf("unable to sign message: %v", err)
+		return fmt.Errorf("unable to encode message for signing: %w", err)
 	}
 
-	zeroSigIndex := -1
-	for i, sig := range tx.Signatures {
-		if sig.IsZero() {
-			if zeroSigIndex != -1 {
-				return errors.New("more than one zero signature provided in transaction")
+	signerKeys := solanaTx.Message.AccountKeys[:solanaTx.Message.Header.NumRequiredSignatures]
+	for _, signer := range signers {
+		index := -1
+		for i, key := range signerKeys {
+			if key.Equals(signer.PublicKey()) {
+				index = i
+				break
 			}
-			zeroSigIndex = i
 		}
-	}
-	if zeroSigIndex == -1 {
-		return errors.New("no zero signatures to replace in transaction")
-	}
+		if index == -1 {
+			return fmt.Errorf("%v is not a required signer of the transaction", signer.PublicKey())
+		}
 
-	tx.Signatures[zeroSigIndex] = signedMessageContent
+		signature, err := signer.SignMessage(ctx, messageContent)
+		if err != nil {
+			return fmt.Errorf("unable to sign message: %w", err)
+		}
+		solanaTx.Signatures[index] = signature
+	}
 	return nil
 }
+
+func missingSigners(solanaTx *solana.Transaction) []solana.PublicKey {
+	var missing []solana.PublicKey
+	for i, signature := range solanaTx.Signatures {
+		if signature.IsZero() {
+			missing = append(missing, solanaTx.Message.AccountKeys[i])
+		}
+	}
+	return missing
+}
@@ -150,7 +150,7 @@ func callOrderbookGRPCStream(g *provider.GRPCClient) {
 	defer cancel()
 
 	// Stream response
-	err := g.GetOrderbookStream(ctx, []string{"SOL/USDC", "xxx", "SOL-USDT"}, 3, orderbookChan)
+	err := g.GetOrderbooksStream(ctx, []string{"SOL/USDC", "xxx", "SOL-USDT"}, 3, orderbookChan)
 	if err != nil {
 		log.Errorf("error with GetOrderbook stream request for SOL/USDC: %v", err)
 	} else {
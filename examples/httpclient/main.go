@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -56,7 +57,7 @@ func main() {
 func callMarketsHTTP() {
 	h := provider.NewHTTPClient()
 
-	markets, err := h.GetMarkets()
+	markets, err := h.GetMarketsWithContext(context.Background())
 	if err != nil {
 		log.Errorf("error with GetMarkets request: %v", err)
 	} else {
@@ -69,7 +70,7 @@ func callMarketsHTTP() {
 func callOrderbookHTTP() {
 	h := provider.NewHTTPClient()
 
-	orderbook, err := h.GetOrderbook("ETH-USDT", 0)
+	orderbook, err := h.GetOrderbookWithContext(context.Background(), "ETH-USDT", 0)
 	if err != nil {
 		log.Errorf("error with GetOrderbook request for ETH-USDT: %v", err)
 	} else {
@@ -78,7 +79,7 @@ func callOrderbookHTTP() {
 
 	fmt.Println()
 
-	orderbook, err = h.GetOrderbook("SOLUSDT", 2)
+	orderbook, err = h.GetOrderbookWithContext(context.Background(), "SOLUSDT", 2)
 	if err != nil {
 		log.Errorf("error with GetOrderbook request for SOLUSDT: %v", err)
 	} else {
@@ -87,7 +88,7 @@ func callOrderbookHTTP() {
 
 	fmt.Println()
 
-	orderbook, err = h.GetOrderbook("SOL:USDC", 3)
+	orderbook, err = h.GetOrderbookWithContext(context.Background(), "SOL:USDC", 3)
 	if err != nil {
 		log.Errorf("error with GetOrderbook request for SOL:USDC: %v", err)
 	} else {
@@ -100,7 +101,7 @@ func callOpenOrdersHTTP() {
 	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIHTTP)
 	h := provider.NewHTTPClientWithOpts(client, opts)
 
-	orders, err := h.GetOpenOrders("SOLUSDT", "HxFLKUAmAMLz1jtT3hbvCMELwH5H9tpM2QugP8sKyfhc")
+	orders, err := h.GetOpenOrdersWithContext(context.Background(), "SOLUSDT", "HxFLKUAmAMLz1jtT3hbvCMELwH5H9tpM2QugP8sKyfhc")
 	if err != nil {
 		log.Errorf("error with GetOrders request for SOLUSDT: %v", err)
 	} else {
@@ -115,7 +116,7 @@ func callUnsettledHTTP() {
 	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIHTTP)
 	h := provider.NewHTTPClientWithOpts(client, opts)
 
-	response, err := h.GetUnsettled("SOLUSDT", "HxFLKUAmAMLz1jtT3hbvCMELwH5H9tpM2QugP8sKyfhc")
+	response, err := h.GetUnsettledWithContext(context.Background(), "SOLUSDT", "HxFLKUAmAMLz1jtT3hbvCMELwH5H9tpM2QugP8sKyfhc")
 	if err != nil {
 		log.Errorf("error with GetOrders request for SOLUSDT: %v", err)
 	} else {
@@ -130,7 +131,7 @@ func callGetAccountBalanceHTTP() {
 	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIHTTP)
 	h := provider.NewHTTPClientWithOpts(client, opts)
 
-	response, err := h.GetAccountBalance("F75gCEckFAyeeCWA9FQMkmLCmke7ehvBnZeVZ3QgvJR7")
+	response, err := h.GetAccountBalanceWithContext(context.Background(), "F75gCEckFAyeeCWA9FQMkmLCmke7ehvBnZeVZ3QgvJR7")
 	if err != nil {
 		log.Errorf("error with GetAccountBalance request for HxFLKUAmAMLz1jtT3hbvCMELwH5H9tpM2QugP8sKyfhc: %v", err)
 	} else {
@@ -143,7 +144,7 @@ func callGetAccountBalanceHTTP() {
 func callTradesHTTP() {
 	h := provider.NewHTTPClient()
 
-	trades, err := h.GetTrades("SOLUSDT", 5)
+	trades, err := h.GetTradesWithContext(context.Background(), "SOLUSDT", 5)
 	if err != nil {
 		log.Errorf("error with GetTrades request for SOLUSDT: %v", err)
 	} else {
@@ -156,7 +157,7 @@ func callTradesHTTP() {
 func callTickersHTTP() {
 	h := provider.NewHTTPClient()
 
-	tickers, err := h.GetTickers("SOLUSDT")
+	tickers, err := h.GetTickersWithContext(context.Background(), "SOLUSDT")
 	if err != nil {
 		log.Errorf("error with GetTickers request for SOLUSDT: %v", err)
 	} else {
@@ -191,14 +192,14 @@ func callPlaceOrderHTTP(ownerAddr, ooAddr string) uint64 {
 	}
 
 	// create order without actually submitting
-	response, err := h.PostOrder(ownerAddr, ownerAddr, marketAddr, orderSide, []pb.OrderType{orderType}, orderAmount, orderPrice, opts)
+	response, err := h.PostOrderWithContext(context.Background(), ownerAddr, ownerAddr, marketAddr, orderSide, []pb.OrderType{orderType}, orderAmount, orderPrice, opts)
 	if err != nil {
 		log.Fatalf("failed to create order (%v)", err)
 	}
 	fmt.Printf("created unsigned place order transaction: %v", response.Transaction)
 
 	// sign/submit transaction after creation
-	sig, err := h.SubmitOrder(ownerAddr, ownerAddr, marketAddr,
+	sig, err := h.SubmitOrderWithContext(context.Background(), ownerAddr, ownerAddr, marketAddr,
 		orderSide, []pb.OrderType{orderType}, orderAmount,
 		orderPrice, opts)
 	if err != nil {
@@ -216,7 +217,7 @@ func callCancelByClientOrderIDHTTP(ownerAddr, ooAddr string, clientOrderID uint6
 	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIHTTP)
 	h := provider.NewHTTPClientWithOpts(client, opts)
 
-	_, err := h.SubmitCancelByClientOrderID(clientOrderID, ownerAddr,
+	_, err := h.SubmitCancelByClientOrderIDWithContext(context.Background(), clientOrderID, ownerAddr,
 		marketAddr, ooAddr, true)
 	if err != nil {
 		log.Fatalf("failed to cancel order by client ID (%v)", err)
@@ -231,7 +232,7 @@ func callPostSettleHTTP(ownerAddr, ooAddr string) {
 	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIHTTP)
 	h := provider.NewHTTPClientWithOpts(client, opts)
 
-	sig, err := h.SubmitSettle(ownerAddr, "SOL/USDC", "F75gCEckFAyeeCWA9FQMkmLCmke7ehvBnZeVZ3QgvJR7", "4raJjCwLLqw8TciQXYruDEF4YhDkGwoEnwnAdwJSjcgv", ooAddr, false)
+	sig, err := h.SubmitSettleWithContext(context.Background(), ownerAddr, "SOL/USDC", "F75gCEckFAyeeCWA9FQMkmLCmke7ehvBnZeVZ3QgvJR7", "4raJjCwLLqw8TciQXYruDEF4YhDkGwoEnwnAdwJSjcgv", ooAddr, false)
 	if err != nil {
 		log.Errorf("error with post transaction stream request for SOL/USDC: %v", err)
 		return
@@ -259,14 +260,14 @@ func cancelAll(ownerAddr, payerAddr, ooAddr string) {
 
 	// Place 2 orders in orderbook
 	fmt.Println("placing orders")
-	sig, err := h.SubmitOrder(ownerAddr, payerAddr, marketAddr, orderSide, []pb.OrderType{orderType}, orderAmount, orderPrice, opts)
+	sig, err := h.SubmitOrderWithContext(context.Background(), ownerAddr, payerAddr, marketAddr, orderSide, []pb.OrderType{orderType}, orderAmount, orderPrice, opts)
 	if err != nil {
 		log.Fatal(err)
 	}
 	log.Infof("submitting place order #1, signature %s", sig)
 
 	opts.ClientOrderID = clientOrderID2
-	sig, err = h.SubmitOrder(ownerAddr, payerAddr, marketAddr, orderSide, []pb.OrderType{orderType}, orderAmount, orderPrice, opts)
+	sig, err = h.SubmitOrderWithContext(context.Background(), ownerAddr, payerAddr, marketAddr, orderSide, []pb.OrderType{orderType}, orderAmount, orderPrice, opts)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -275,7 +276,7 @@ func cancelAll(ownerAddr, payerAddr, ooAddr string) {
 	time.Sleep(time.Minute)
 
 	// Check orders are there
-	orders, err := h.GetOpenOrders(marketAddr, ownerAddr)
+	orders, err := h.GetOpenOrdersWithContext(context.Background(), marketAddr, ownerAddr)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -298,7 +299,7 @@ func cancelAll(ownerAddr, payerAddr, ooAddr string) {
 
 	// Cancel all the orders
 	fmt.Println("\ncancelling the orders")
-	sigs, err := h.SubmitCancelAll(marketAddr, ownerAddr, []string{ooAddr}, true)
+	sigs, err := h.SubmitCancelAllWithContext(context.Background(), marketAddr, ownerAddr, []string{ooAddr}, true)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -306,7 +307,7 @@ func cancelAll(ownerAddr, payerAddr, ooAddr string) {
 
 	time.Sleep(time.Minute)
 
-	orders, err = h.GetOpenOrders(marketAddr, ownerAddr)
+	orders, err = h.GetOpenOrdersWithContext(context.Background(), marketAddr, ownerAddr)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/provider"
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/recording"
+	log "github.com/sirupsen/logrus"
+)
+
+// records the orderbooks, trades and tickers of markets over websockets until interrupted, e.g.:
+//
+//	go run ./examples/recorder -out sol.rec.zst -zstd -orderbooks SOL/USDC -trades SOL/USDC -tickers SOL/USDC
+func main() {
+	out := flag.String("out", "recording.rec", "recording file to write")
+	compress := flag.Bool("zstd", false, "compress the recording with zstd")
+	testnet := flag.Bool("testnet", false, "record from testnet instead of mainnet")
+	orderbooks := flag.String("orderbooks", "", "comma separated markets whose orderbooks are recorded")
+	trades := flag.String("trades", "", "comma separated markets whose trades are recorded")
+	tickers := flag.String("tickers", "", "comma separated markets whose tickers are recorded")
+	flag.Parse()
+
+	var w *provider.WSClient
+	var err error
+	if *testnet {
+		w, err = provider.NewWSClientTestnet()
+	} else {
+		w, err = provider.NewWSClient()
+	}
+	if err != nil {
+		log.Fatalf("error dialing WS client: %v", err)
+	}
+	defer func() {
+		_ = w.Close()
+	}()
+
+	f, err := recording.Create(*out, recording.WriterOpts{Compress: *compress})
+	if err != nil {
+		log.Fatalf("error creating recording: %v", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Errorf("error closing recording: %v", err)
+		}
+	}()
+
+	r, err := recording.NewRecorder(w, f, recording.Streams{
+		Orderbooks: markets(*orderbooks),
+		Trades:     markets(*trades),
+		Tickers:    markets(*tickers),
+	})
+	if err != nil {
+		log.Errorf("error creating recorder: %v", err)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	log.Infof("recording to %v, interrupt to stop", *out)
+	if err := r.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Errorf("recording stopped: %v", err)
+	}
+}
+
+func markets(flag string) []string {
+	if flag == "" {
+		return nil
+	}
+	return strings.Split(flag, ",")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+
+	"github.com/bloXroute-Labs/serum-client-go/bxserum/transaction"
+	log "github.com/sirupsen/logrus"
+)
+
+// Serves a Solana CLI keypair file over the remote signer protocol, standing in for a signing service. Point a client
+// at it with:
+//
+//	signer, err := transaction.NewRemoteSigner(ctx, "http://127.0.0.1:8090", nil)
+//	opts := provider.DefaultRPCOpts(provider.MainnetSerumAPIGRPC)
+//	opts.Signer = signer
+func main() {
+	keypairPath := flag.String("keypair", "", "path to a keypair JSON file created by solana-keygen")
+	addr := flag.String("addr", "127.0.0.1:8090", "address to listen on")
+	flag.Parse()
+
+	signer, err := transaction.NewKeypairFileSigner(*keypairPath)
+	if err != nil {
+		log.Fatalf("could not load keypair: %v", err)
+	}
+
+	log.Infof("signing for %v on %v", signer.PublicKey(), *addr)
+	if err := http.ListenAndServe(*addr, transaction.NewRemoteSignerHandler(signer)); err != nil {
+		log.Fatal(err)
+	}
+}